@@ -61,4 +61,115 @@ CREATE TABLE IF NOT EXISTS items (
     nm_id INTEGER NOT NULL,
     brand TEXT NOT NULL,
     status INTEGER NOT NULL
-);
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    order_uid TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NOT NULL,
+    success BOOLEAN NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx
+    ON webhook_deliveries (subscription_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS webhook_pending_deliveries (
+    id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    order_uid TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_pending_deliveries_due_idx
+    ON webhook_pending_deliveries (next_attempt_at, id);
+
+CREATE INDEX IF NOT EXISTS orders_recent_idx
+    ON orders (date_created DESC, order_uid DESC);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS orders_updated_at_idx
+    ON orders (updated_at);
+
+CREATE INDEX IF NOT EXISTS items_order_uid_idx
+    ON items (order_uid, id);
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', name), 'A') ||
+        setweight(to_tsvector('english', name), 'A') ||
+        setweight(to_tsvector('russian', brand), 'B') ||
+        setweight(to_tsvector('english', brand), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS items_search_vector_idx
+    ON items USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency TEXT PRIMARY KEY,
+    rate NUMERIC(24, 10) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS order_flags (
+    id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    order_uid TEXT NOT NULL REFERENCES orders(order_uid) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_flags_order_uid_idx
+    ON order_flags (order_uid);
+
+CREATE TABLE IF NOT EXISTS quarantined_orders (
+    id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    order_uid TEXT NOT NULL DEFAULT '',
+    payload BYTEA NOT NULL,
+    stage TEXT NOT NULL,
+    errors TEXT[] NOT NULL,
+    topic TEXT NOT NULL,
+    kafka_partition INTEGER NOT NULL,
+    kafka_offset BIGINT NOT NULL,
+    kafka_key TEXT NOT NULL,
+    kafka_timestamp TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'quarantined',
+    received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resubmitted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS quarantined_orders_status_idx
+    ON quarantined_orders (status, id);
+
+CREATE TABLE IF NOT EXISTS order_outbox (
+    id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    event_id TEXT NOT NULL UNIQUE,
+    order_uid TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
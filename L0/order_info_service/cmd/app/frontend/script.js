@@ -8,10 +8,12 @@ document.addEventListener('DOMContentLoaded', () => {
     const prevBtn = document.getElementById('prev-btn');
     const nextBtn = document.getElementById('next-btn');
     const errorMessage = document.getElementById('error-message');
+    const liveStatus = document.getElementById('live-status');
 
     let currentOrderUid = '';
     let lastItemId = 0;
     let paginationHistory = [];
+    let eventSource = null;
     const ITEMS_PER_PAGE = 4;
 
     searchBtn.addEventListener('click', () => {
@@ -53,6 +55,7 @@ document.addEventListener('DOMContentLoaded', () => {
             .then(order => {
                 displayOrder(order);
                 fetchItems(orderUid, 0, ITEMS_PER_PAGE);
+                subscribeToUpdates(orderUid);
             })
             .catch(error => {
                 showError(error.message);
@@ -80,6 +83,44 @@ document.addEventListener('DOMContentLoaded', () => {
             });
     }
 
+    function subscribeToUpdates(orderUid) {
+        unsubscribeFromUpdates();
+
+        eventSource = new EventSource(`/api/orders/${orderUid}/events`);
+
+        eventSource.onopen = () => {
+            liveStatus.textContent = 'Live updates on';
+            liveStatus.classList.remove('hidden', 'offline');
+        };
+
+        eventSource.onerror = () => {
+            liveStatus.textContent = 'Live updates reconnecting...';
+            liveStatus.classList.add('offline');
+        };
+
+        eventSource.addEventListener('order', event => {
+            if (orderUid !== currentOrderUid) {
+                return;
+            }
+
+            const order = JSON.parse(event.data);
+            displayOrder(order);
+            paginationHistory = [0];
+            lastItemId = 0;
+            fetchItems(orderUid, 0, ITEMS_PER_PAGE);
+
+            liveStatus.textContent = `Order updated at ${new Date().toLocaleTimeString()}`;
+        });
+    }
+
+    function unsubscribeFromUpdates() {
+        if (eventSource) {
+            eventSource.close();
+            eventSource = null;
+        }
+        liveStatus.classList.add('hidden');
+    }
+
     function displayOrder(order) {
         orderInfo.classList.remove('hidden');
         
@@ -156,6 +197,7 @@ document.addEventListener('DOMContentLoaded', () => {
     }
 
     function resetState() {
+        unsubscribeFromUpdates();
         orderInfo.classList.add('hidden');
         itemsSection.classList.add('hidden');
         orderDetails.innerHTML = '';
@@ -5,10 +5,12 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"flag"
 	"fmt"
 	"io/fs"
 	"log"
 	"mime"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,141 +19,355 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/admin"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/analytics"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/circuitbreaker"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/config"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/exchange"
+	grpcserver "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/grpc_server"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/invalidation"
 	kafka "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/kafka_consumer"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/outbox"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/pubsub"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/quarantine"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository/memory"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/search"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/snapshot"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/warmup"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/webhook"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
-	"github.com/caarlos0/env/v6"
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
-type Config struct {
-	DBHost     string `env:"DB_HOST" envDefault:"localhost"`
-	DBPort     string `env:"DB_PORT" envDefault:"5432"`
-	DBUser     string `env:"DB_USER" envDefault:"postgres"`
-	DBPassword string `env:"DB_PASSWORD" envDefault:"postgres"`
-	DBName     string `env:"DB_NAME" envDefault:"orders"`
-
-	KafkaBootstrapServers  string        `env:"KAFKA_BOOTSTRAP_SERVERS" envDefault:"localhost:9092"`
-	KafkaGroupID           string        `env:"KAFKA_GROUP_ID" envDefault:"order-info-service"`
-	KafkaTopic             string        `env:"KAFKA_TOPIC" envDefault:"orders"`
-	KafkaAutoOffset        string        `env:"KAFKA_AUTO_OFFSET" envDefault:"earliest"`
-	KafkaSessionTimeout    time.Duration `env:"KAFKA_SESSION_TIMEOUT" envDefault:"10s"`
-	KafkaRebalanceTimeout  time.Duration `env:"KAFKA_REBALANCE_TIMEOUT" envDefault:"15s"`
-	KafkaHeartbeatInterval time.Duration `env:"KAFKA_HEARTBEAT_INTERVAL" envDefault:"3s"`
-	KafkaMaxPollInterval   time.Duration `env:"KAFKA_MAX_POLL_INTERVAL" envDefault:"5m"`
-	KafkaMaxRetries        int           `env:"KAFKA_MAX_RETRIES" envDefault:"3"`
-	KafkaCleanupInterval   time.Duration `env:"KAFKA_CLEANUP_INTERVAL" envDefault:"5m"`
-	KafkaMaxAge            time.Duration `env:"KAFKA_MAX_AGE" envDefault:"30m"`
-
-	ServerPort string `env:"SERVER_PORT" envDefault:"8080"`
-}
-
 //go:embed frontend/*
 var frontendFS embed.FS
 
-func LoadConfig() (Config, error) {
-	var cfg Config
-	if err := env.Parse(&cfg); err != nil {
-		return Config{}, err
-	}
-	return cfg, nil
+// orderStore is what the service needs from either order storage.
+type orderStore interface {
+	repository.RepositoryProvider
+	repository.SnapshotRepositoryProvider
 }
 
 func main() {
-	cfg, err := LoadConfig()
+	configPath := flag.String("config", os.Getenv(config.EnvFile), "path to a YAML or TOML config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
 	if err != nil {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
 
-	logg, err := logger.NewLogger()
+	logg, err := logger.NewLogger(logger.Config{
+		Level:          cfg.Log.Level,
+		Format:         cfg.Log.Format,
+		RedactKeys:     cfg.Log.RedactKeys,
+		RedactPatterns: cfg.Log.RedactPatterns,
+	})
 	if err != nil {
 		log.Fatalf("failed to initialize logger: %v", err)
 	}
 
-	db, err := initDB(cfg, logg)
+	tracer, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.OTLPEndpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		logg.Error("failed to connect to database", zap.Error(err))
+		logg.Error("failed to set up tracing", zap.Error(err))
 		os.Exit(1)
 	}
-	defer db.Close()
 
-	schemaPath := "./db/postgres.sql"
-	if err := applyDBSchema(db, schemaPath, logg); err != nil {
-		logg.Error("failed to apply database schema", zap.Error(err))
-		os.Exit(1)
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		hostname, _ := os.Hostname()
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
 	}
 
-	repo := repository.NewOrderRepository(db)
+	// db stays nil with the memory storage, which turns off every feature
+	// that has no in-memory counterpart.
+	var db *sql.DB
+	var repo orderStore
+	var itemSearch repository.ItemSearchRepositoryProvider
+	var replicas *repository.ReplicaSet
+	if cfg.Storage == config.StorageMemory {
+		logg.Warn("orders are kept in memory and lost on restart; webhooks, analytics, search, " +
+			"exchange rates, quarantine, cache snapshots and cache invalidation are disabled")
+		repo = memory.NewOrderRepository()
+	} else {
+		db, err = initDB(cfg, logg)
+		if err != nil {
+			logg.Error("failed to connect to database", zap.Error(err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		schemaPath := "./db/postgres.sql"
+		if err := applyDBSchema(db, schemaPath, logg); err != nil {
+			logg.Error("failed to apply database schema", zap.Error(err))
+			os.Exit(1)
+		}
+
+		var repoOpts []repository.OrderRepositoryOption
+		if len(cfg.DB.ReplicaHosts) > 0 {
+			replicaDBs, err := openReplicas(cfg)
+			if err != nil {
+				logg.Error("failed to open database replicas", zap.Error(err))
+				os.Exit(1)
+			}
+			for _, replicaDB := range replicaDBs {
+				defer replicaDB.Close()
+			}
+			replicas = repository.NewReplicaSet(replicaDBs, logg, repository.ReplicaConfig{
+				MaxLag:        cfg.DB.ReplicaMaxLag,
+				CheckInterval: cfg.DB.ReplicaCheckInterval,
+			})
+			repoOpts = append(repoOpts, repository.WithReplicas(replicas))
+		}
+		if cfg.Cache.InvalidationChannel != "" {
+			repoOpts = append(repoOpts, repository.WithChangeNotifications(cfg.Cache.InvalidationChannel, instanceID))
+		}
+		if cfg.Outbox.Topic != "" {
+			repoOpts = append(repoOpts, repository.WithOutbox())
+		}
+		orders := repository.NewOrderRepository(db, repoOpts...)
+		repo, itemSearch = orders, orders
+	}
 
-	cache := cache.NewLocalCache()
+	cache := cache.NewLocalCache(cache.WithEvictedRetention(cfg.Cache.EvictedCapacity, cfg.Cache.EvictedTTL))
+
+	// Only HTTP reads go through the breaker: the consumer has its own retries
+	// and quarantine for a failing database.
+	breaker := circuitbreaker.New(circuitbreaker.Config{
+		FailureThreshold: cfg.Breaker.FailureThreshold,
+		OpenTimeout:      cfg.Breaker.OpenTimeout,
+		HalfOpenRequests: cfg.Breaker.HalfOpenRequests,
+	}, logg)
+	ctrl := controller.NewController(circuitbreaker.NewRepository(repo, breaker), cache, logg,
+		controller.WithBreaker(breaker))
+
+	hub := pubsub.NewHub(cfg.Events.BufferSize)
+
+	warmer := warmup.NewService(repo, cache, logg, cfg.Warmup.BatchSize)
+
+	var invalidator *invalidation.Service
+	if db != nil && cfg.Cache.InvalidationChannel != "" {
+		listener := invalidation.NewPQListener(postgresDSN(cfg, cfg.DB.Host, cfg.DB.Port),
+			cfg.Cache.InvalidationMinReconnect, cfg.Cache.InvalidationMaxReconnect, logg)
+		invalidator, err = invalidation.NewService(listener, repo, cache, warmer, logg, invalidation.Config{
+			Channel:     cfg.Cache.InvalidationChannel,
+			Origin:      instanceID,
+			Mode:        cfg.Cache.InvalidationMode,
+			RewarmLimit: cfg.Warmup.Limit,
+		})
+		if err != nil {
+			logg.Error("failed to configure cache invalidation", zap.Error(err))
+			os.Exit(1)
+		}
+	}
 
-	ctrl := controller.NewController(repo, cache, logg)
+	// A snapshot would restore orders the empty memory storage does not have.
+	snapshotPath := cfg.Cache.SnapshotPath
+	if db == nil {
+		snapshotPath = ""
+	}
+	snapshots := snapshot.NewService(repo, cache, logg, snapshot.Config{
+		Path:    snapshotPath,
+		MaxSize: cfg.Cache.SnapshotMaxSize,
+	})
 
-	httpHandler := handler.NewHandler(ctrl, logg)
+	var webhooks *webhook.Service
+	if db != nil {
+		webhooks = webhook.NewService(repository.NewWebhookRepository(db), logg, webhook.Config{
+			Workers:        cfg.Webhook.Workers,
+			BatchSize:      cfg.Webhook.BatchSize,
+			PollInterval:   cfg.Webhook.PollInterval,
+			MaxAttempts:    cfg.Webhook.MaxAttempts,
+			InitialBackoff: cfg.Webhook.InitialBackoff,
+			MaxBackoff:     cfg.Webhook.MaxBackoff,
+			RequestTimeout: cfg.Webhook.Timeout,
+		})
+	}
 
-	kafkaConfig := kafka.KafkaConfig{
-		BootstrapServers:  cfg.KafkaBootstrapServers,
-		GroupID:           cfg.KafkaGroupID,
-		Topic:             cfg.KafkaTopic,
-		AutoOffset:        cfg.KafkaAutoOffset,
-		SessionTimeout:    cfg.KafkaSessionTimeout,
-		HeartbeatInterval: cfg.KafkaHeartbeatInterval,
-		MaxPollInterval:   cfg.KafkaMaxPollInterval,
-		MaxRetries:        cfg.KafkaMaxRetries,
-		CleanupInterval:   cfg.KafkaCleanupInterval,
-		MaxAge:            cfg.KafkaMaxAge,
+	var outboxRelay *outbox.Relay
+	if cfg.Outbox.Topic != "" {
+		publisher, err := outbox.NewKafkaPublisher(cfg.Kafka.BootstrapServers, cfg.Outbox.Topic)
+		if err != nil {
+			logg.Error("failed to create outbox publisher", zap.Error(err))
+			os.Exit(1)
+		}
+		defer publisher.Close()
+		outboxRelay = outbox.NewRelay(repository.NewOutboxRepository(db), publisher, logg, outbox.Config{
+			BatchSize:      cfg.Outbox.BatchSize,
+			PollInterval:   cfg.Outbox.PollInterval,
+			PublishTimeout: cfg.Outbox.PublishTimeout,
+		})
 	}
-	kafkaConsumer, err := kafka.NewKafkaConsumer(kafkaConfig, logg)
+
+	ruleEngine, err := cfg.Rules.Engine()
+	if err != nil {
+		logg.Error("failed to configure business rules", zap.Error(err))
+		os.Exit(1)
+	}
+	decoder := ingest.NewDecoder(ruleEngine)
+
+	handleOrder := func(ctx context.Context, order *model.Order) error {
+		stored, created, err := repo.UpsertOrder(ctx, order)
+		if err != nil {
+			return err
+		}
+		cache.SetOrder(stored)
+		hub.Publish(stored)
+
+		if webhooks == nil {
+			return nil
+		}
+		// A failed enqueue fails the message, so the consumer retries it
+		// instead of losing the event.
+		if created {
+			return webhooks.Notify(ctx, model.EventOrderCreated, stored)
+		}
+		return webhooks.Notify(ctx, model.EventOrderUpdated, stored)
+	}
+
+	consumerOpts := []kafka.Option{kafka.WithDecoder(decoder)}
+	var handlerOpts []handler.Option
+	if db != nil {
+		rates := exchange.NewService(repository.NewExchangeRateRepository(db), logg)
+		if err := loadExchangeRates(rates, cfg.Exchange.RatesFile); err != nil {
+			logg.Error("failed to load exchange rates", zap.Error(err))
+			os.Exit(1)
+		}
+
+		stats := analytics.NewService(repository.NewAnalyticsRepository(db), logg, analytics.WithConverter(rates))
+		quarantined := quarantine.NewService(repository.NewQuarantineRepository(db), decoder, handleOrder, logg)
+
+		consumerOpts = append(consumerOpts, kafka.WithQuarantine(quarantined))
+		handlerOpts = append(handlerOpts,
+			handler.WithWebhooks(webhooks),
+			handler.WithAnalytics(stats),
+			handler.WithSearch(search.NewService(itemSearch, logg)),
+			handler.WithExchange(rates),
+			handler.WithQuarantine(quarantined),
+		)
+	}
+
+	kafkaConsumer, err := kafka.NewKafkaConsumer(cfg.Kafka, logg, consumerOpts...)
 	if err != nil {
 		logg.Error("failed to create kafka consumer", zap.Error(err))
 		os.Exit(1)
 	}
 
-	var cachedAmount int
-	if cachedAmount, err = controller.WarmUpCache(context.Background(), repo, cache, 100); err != nil {
-		logg.Error("failed to warm up cache", zap.Error(err))
+	if cfg.HTTP.AdminToken == "" {
+		logg.Warn("ADMIN_TOKEN is not set, admin API rejects all requests")
 	}
 
-	logg.Info("orders added to cache", zap.Int("amount", cachedAmount))
+	httpHandler := handler.NewHandler(ctrl, logg, append(handlerOpts,
+		handler.WithOrderEvents(hub),
+		handler.WithAdmin(admin.NewService(cache, warmer, kafkaConsumer, logg, logg)),
+		handler.WithAdminToken(cfg.HTTP.AdminToken),
+		handler.WithCORSOrigins(cfg.HTTP.CORSOrigins...),
+		handler.WithMaxItemPage(cfg.HTTP.MaxItemPage),
+		handler.WithRateLimit(cfg.HTTP.RateLimit, cfg.HTTP.RateBurst),
+		handler.WithWarmUpLimit(cfg.Warmup.Limit),
+	)...)
+
+	reloader := config.NewReloader(*configPath, cfg, logg)
+	reloader.OnReload(func(prev, next config.Config) {
+		// The level can also be set through the admin API, so a reload only
+		// touches it when log.level itself changed in the file.
+		if next.Log.Level != prev.Log.Level {
+			if err := logg.SetLevel(next.Log.Level); err != nil {
+				logg.Error("failed to apply log level", zap.Error(err))
+			}
+		}
+		httpHandler.SetMaxItemPage(next.HTTP.MaxItemPage)
+		httpHandler.SetRateLimit(next.HTTP.RateLimit, next.HTTP.RateBurst)
+		cache.SetEvictedRetention(next.Cache.EvictedCapacity, next.Cache.EvictedTTL)
+	})
+
+	orderServer := grpcserver.NewServer(ctrl, hub, logg, cfg.GRPC.WatchInterval)
+	grpcServer := grpcserver.NewGRPCServer(orderServer, logg)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	reloader.Start(ctx)
+	if replicas != nil {
+		replicas.Start(ctx)
+	}
+	warmer.Start(ctx)
+	if invalidator != nil {
+		invalidator.Start(ctx)
+	}
+	if !snapshots.Restore(ctx) {
+		if _, err := warmer.Trigger(cfg.Warmup.Limit); err != nil {
+			logg.Error("failed to start cache warmup", zap.Error(err))
+		}
+	}
+
+	if webhooks != nil {
+		webhooks.Start(ctx)
+	}
+	if outboxRelay != nil {
+		outboxRelay.Start(ctx)
+	}
+
 	server := &http.Server{
-		Addr:    ":" + cfg.ServerPort,
+		Addr:    ":" + cfg.HTTP.Port,
 		Handler: setupRouter(httpHandler, logg),
 	}
+	// Shutdown does not cancel request contexts or touch hijacked connections,
+	// so closing the hub is what ends open SSE and WebSocket streams.
+	server.RegisterOnShutdown(hub.Close)
 	go func() {
 		logg.Info("starting HTTP server",
 			zap.String("addr", server.Addr),
-			zap.String("port", cfg.ServerPort))
+			zap.String("port", cfg.HTTP.Port))
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logg.Error("HTTP server error", zap.Error(err))
 		}
 	}()
 
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+	if err != nil {
+		logg.Error("failed to listen on gRPC port", zap.String("port", cfg.GRPC.Port), zap.Error(err))
+		os.Exit(1)
+	}
+	go func() {
+		logg.Info("starting gRPC server",
+			zap.String("addr", grpcListener.Addr().String()),
+			zap.String("port", cfg.GRPC.Port))
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logg.Error("gRPC server error", zap.Error(err))
+		}
+	}()
+
 	go func() {
-		if err := kafkaConsumer.Consume(ctx, func(ctx context.Context, order *model.Order) error {
-			_, err := repo.UpsertOrder(ctx, order)
-			return err
-		}); err != nil {
+		if err := kafkaConsumer.Consume(ctx, handleOrder); err != nil {
 			logg.Error("kafka consumer error", zap.Error(err))
 		}
 	}()
 
 	logg.Info("application started",
-		zap.String("db_host", cfg.DBHost),
-		zap.String("db_port", cfg.DBPort),
-		zap.String("db_name", cfg.DBName),
-		zap.String("kafka_brokers", cfg.KafkaBootstrapServers),
-		zap.String("kafka_topic", cfg.KafkaTopic),
-		zap.String("server_port", cfg.ServerPort))
+		zap.String("storage", cfg.Storage),
+		zap.String("db_host", cfg.DB.Host),
+		zap.String("db_port", cfg.DB.Port),
+		zap.String("db_name", cfg.DB.Name),
+		zap.String("kafka_brokers", cfg.Kafka.BootstrapServers),
+		zap.String("kafka_topic", cfg.Kafka.Topic),
+		zap.String("server_port", cfg.HTTP.Port),
+		zap.String("grpc_port", cfg.GRPC.Port),
+		zap.String("instance_id", instanceID))
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -166,13 +382,56 @@ func main() {
 		logg.Error("HTTP server forced to shutdown", zap.Error(err))
 	}
 
+	grpcShutdownCtx, grpcShutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer grpcShutdownCancel()
+	orderServer.Shutdown()
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-grpcShutdownCtx.Done():
+		logg.Error("gRPC server forced to shutdown", zap.Error(grpcShutdownCtx.Err()))
+		grpcServer.Stop()
+	}
+
 	if err := kafkaConsumer.Close(); err != nil {
 		logg.Error("failed to close kafka consumer", zap.Error(err))
 	}
 
+	reloader.Wait()
+	if webhooks != nil {
+		webhooks.Wait()
+	}
+	if outboxRelay != nil {
+		outboxRelay.Wait()
+	}
+	warmer.Wait()
+	snapshots.Wait()
+	if invalidator != nil {
+		invalidator.Wait()
+	}
+	if replicas != nil {
+		replicas.Wait()
+	}
+
+	snapshotCtx, snapshotCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer snapshotCancel()
+	snapshots.Save(snapshotCtx)
+
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer tracingCancel()
+	if err := tracer.Shutdown(tracingCtx); err != nil {
+		logg.Error("failed to flush traces", zap.Error(err))
+	}
+
 	logg.Info("application shutdown complete")
 }
 
+// setupRouter serves the embedded frontend and mounts the API handler, which
+// applies its own CORS policy.
 func setupRouter(apiHandler http.Handler, log logger.Logger) http.Handler {
 	mux := http.NewServeMux()
 
@@ -210,7 +469,7 @@ func setupRouter(apiHandler http.Handler, log logger.Logger) http.Handler {
 
 	mux.Handle("/api/", apiHandler)
 
-	return corsMiddleware(mux)
+	return mux
 }
 
 func serveIndex(w http.ResponseWriter, root fs.FS) {
@@ -223,36 +482,18 @@ func serveIndex(w http.ResponseWriter, root fs.FS) {
 	w.Write(data)
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		port := os.Getenv("SERVER_PORT")
-		if port == "" {
-			port = "8080"
-		}
-
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:"+port)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
+func postgresDSN(cfg config.Config, host, port string) string {
+	psqlInfo := "host=%s port=%s user=%s password=%s dbname=%s sslmode=disable"
+	return fmt.Sprintf(psqlInfo,
+		host,
+		port,
+		cfg.DB.User,
+		cfg.DB.Password,
+		cfg.DB.Name)
 }
 
-func initDB(cfg Config, log logger.Logger) (*sql.DB, error) {
-	psqlInfo := "host=%s port=%s user=%s password=%s dbname=%s sslmode=disable"
-	psqlInfo = fmt.Sprintf(psqlInfo,
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBUser,
-		cfg.DBPassword,
-		cfg.DBName)
-
-	db, err := sql.Open("postgres", psqlInfo)
+func initDB(cfg config.Config, log logger.Logger) (*sql.DB, error) {
+	db, err := sql.Open("postgres", postgresDSN(cfg, cfg.DB.Host, cfg.DB.Port))
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", srvcerrors.ErrDatabase, err)
 	}
@@ -264,13 +505,40 @@ func initDB(cfg Config, log logger.Logger) (*sql.DB, error) {
 	}
 
 	log.Info("database successfully connected",
-		zap.String("host", cfg.DBHost),
-		zap.String("port", cfg.DBPort),
-		zap.String("dbname", cfg.DBName))
+		zap.String("host", cfg.DB.Host),
+		zap.String("port", cfg.DB.Port),
+		zap.String("dbname", cfg.DB.Name))
 
 	return db, nil
 }
 
+// openReplicas opens a pool per DB_REPLICA_HOSTS entry ("host" or "host:port").
+// Replicas are not pinged here: an unreachable replica is simply reported
+// unhealthy by the replica set and reads stay on the primary.
+func openReplicas(cfg config.Config) (map[string]*sql.DB, error) {
+	replicas := make(map[string]*sql.DB, len(cfg.DB.ReplicaHosts))
+	for _, addr := range cfg.DB.ReplicaHosts {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			host, port = addr, cfg.DB.Port
+		}
+
+		db, err := sql.Open("postgres", postgresDSN(cfg, host, port))
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("%w: replica %s: %v", srvcerrors.ErrDatabase, addr, err)
+		}
+		replicas[addr] = db
+	}
+	return replicas, nil
+}
+
 func applyDBSchema(db *sql.DB, schemaPath string, log logger.Logger) error {
 	schema, err := os.ReadFile(schemaPath)
 	if err != nil {
@@ -291,3 +559,13 @@ func applyDBSchema(db *sql.DB, schemaPath string, log logger.Logger) error {
 	log.Info("database schema applied", zap.String("path", schemaPath))
 	return nil
 }
+
+func loadExchangeRates(rates *exchange.Service, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if path != "" {
+		return rates.LoadFile(ctx, path)
+	}
+	return rates.Load(ctx)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"golang.org/x/time/rate"
+)
+
+type LoadConfig struct {
+	Rate        float64
+	Concurrency int
+	Duration    time.Duration
+}
+
+type sender interface {
+	NewMessage(value []byte) *kafka.Message
+	Send(context.Context, *kafka.Message) error
+}
+
+type weighted[T any] struct {
+	value  T
+	weight int
+}
+
+// Distributions of generated orders in load mode, roughly matching real
+// traffic: most orders hold one or two items, most are Russian and paid in
+// roubles.
+var (
+	itemCountWeights = []weighted[int]{
+		{1, 40}, {2, 25}, {3, 15}, {4, 8}, {5, 4}, {6, 3}, {7, 2}, {8, 1}, {9, 1}, {10, 1},
+	}
+	localeWeights   = []weighted[string]{{"ru", 70}, {"en", 30}}
+	currencyWeights = []weighted[string]{{"RUB", 75}, {"USD", 25}}
+	saleWeights     = []weighted[int]{{0, 50}, {10, 15}, {20, 15}, {30, 10}, {50, 7}, {70, 3}}
+	brands          = []string{"Vivienne Sabo", "Maybelline", "L'Oreal", "Nivea", "Essence", "Garnier"}
+)
+
+// medianPrice is the median item price in minor units per currency. Prices
+// follow a log-normal distribution around it.
+var medianPrice = map[string]float64{"RUB": 1200, "USD": 15}
+
+func pick[T any](g *OrderGenerator, choices []weighted[T]) T {
+	total := 0
+	for _, c := range choices {
+		total += c.weight
+	}
+	n := g.rand.Intn(total)
+	for _, c := range choices {
+		if n < c.weight {
+			return c.value
+		}
+		n -= c.weight
+	}
+	return choices[len(choices)-1].value
+}
+
+// GenerateRealisticOrder returns a valid order that passes the business rules,
+// with item count, locale, currency and prices drawn from the distributions
+// above.
+func (g *OrderGenerator) GenerateRealisticOrder() *model.Order {
+	order := g.GenerateValidOrder()
+	order.Locale = pick(g, localeWeights)
+	order.Payment.Currency = pick(g, currencyWeights)
+
+	count := pick(g, itemCountWeights)
+	order.Items = g.generateItems(order.OrderUID, order.TrackNumber, count)
+
+	goodsTotal := 0
+	for _, item := range order.Items {
+		price := medianPrice[order.Payment.Currency] * math.Exp(g.rand.NormFloat64()*0.8)
+		item.Price = int(math.Max(1, math.Round(price)))
+		item.Sale = pick(g, saleWeights)
+		item.TotalPrice = item.Price * (100 - item.Sale) / 100
+		item.Brand = brands[g.rand.Intn(len(brands))]
+		goodsTotal += item.TotalPrice
+	}
+
+	order.Payment.GoodsTotal = goodsTotal
+	order.Payment.DeliveryCost = 0
+	if freeFrom := 10 * medianPrice[order.Payment.Currency]; float64(goodsTotal) < freeFrom {
+		order.Payment.DeliveryCost = int(freeFrom / 20)
+	}
+	order.Payment.CustomFee = 0
+	order.Payment.Amount = goodsTotal + order.Payment.DeliveryCost
+	order.Payment.PaymentDT = int(time.Now().Unix())
+	return order
+}
+
+// LoadReport summarizes a load run.
+type LoadReport struct {
+	Elapsed   time.Duration
+	Sent      int
+	Failed    int
+	Latencies []time.Duration
+	Errors    map[string]int
+}
+
+func (r *LoadReport) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Sent-r.Failed) / r.Elapsed.Seconds()
+}
+
+// Percentile returns the delivery latency below which p percent of the
+// delivered messages fall, using the nearest-rank method.
+func (r *LoadReport) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(r.Latencies))))
+	rank = min(max(rank, 1), len(r.Latencies))
+	return r.Latencies[rank-1]
+}
+
+func (r *LoadReport) Print(w io.Writer) {
+	fmt.Fprintf(w, "Elapsed:     %s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "Sent:        %d\n", r.Sent)
+	fmt.Fprintf(w, "Delivered:   %d\n", r.Sent-r.Failed)
+	fmt.Fprintf(w, "Failed:      %d\n", r.Failed)
+	fmt.Fprintf(w, "Throughput:  %.1f msg/s\n", r.Throughput())
+	if len(r.Latencies) > 0 {
+		fmt.Fprintf(w, "Latency:     p50=%s p90=%s p95=%s p99=%s max=%s\n",
+			r.Percentile(50), r.Percentile(90), r.Percentile(95), r.Percentile(99), r.Percentile(100))
+	}
+	if len(r.Errors) == 0 {
+		return
+	}
+
+	messages := make([]string, 0, len(r.Errors))
+	for msg := range r.Errors {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if r.Errors[messages[i]] != r.Errors[messages[j]] {
+			return r.Errors[messages[i]] > r.Errors[messages[j]]
+		}
+		return messages[i] < messages[j]
+	})
+	fmt.Fprintln(w, "Errors:")
+	for _, msg := range messages {
+		fmt.Fprintf(w, "  %6d  %s\n", r.Errors[msg], msg)
+	}
+}
+
+type loadResult struct {
+	latency time.Duration
+	err     error
+}
+
+// RunLoad sends generated orders at cfg.Rate messages per second (no limit if
+// zero) from cfg.Concurrency workers until cfg.Duration passes or ctx is
+// done. Orders are generated by a single goroutine, so a seeded generator
+// produces the same orders on every run.
+func RunLoad(ctx context.Context, producer sender, generator *OrderGenerator, cfg LoadConfig) *LoadReport {
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+	limit := rate.Inf
+	if cfg.Rate > 0 {
+		limit = rate.Limit(cfg.Rate)
+	}
+	limiter := rate.NewLimiter(limit, 1)
+	concurrency := max(cfg.Concurrency, 1)
+
+	jobs := make(chan []byte)
+	results := make(chan loadResult, concurrency)
+
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for value := range jobs {
+				msg := producer.NewMessage(value)
+				start := time.Now()
+				// The run context may already be done, the message is sent anyway.
+				err := producer.Send(context.WithoutCancel(ctx), msg)
+				results <- loadResult{latency: time.Since(start), err: err}
+			}
+		}()
+	}
+
+	report := &LoadReport{Errors: make(map[string]int)}
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for res := range results {
+			report.Sent++
+			if res.err != nil {
+				report.Failed++
+				report.Errors[res.err.Error()]++
+				continue
+			}
+			report.Latencies = append(report.Latencies, res.latency)
+		}
+	}()
+
+	start := time.Now()
+	for limiter.Wait(ctx) == nil {
+		value, err := json.Marshal(generator.GenerateRealisticOrder())
+		if err != nil {
+			results <- loadResult{err: fmt.Errorf("failed to marshal order: %w", err)}
+			continue
+		}
+		select {
+		case jobs <- value:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	workers.Wait()
+	report.Elapsed = time.Since(start)
+	close(results)
+	<-collected
+
+	sort.Slice(report.Latencies, func(i, j int) bool { return report.Latencies[i] < report.Latencies[j] })
+	return report
+}
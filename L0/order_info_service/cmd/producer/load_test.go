@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/rules"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	mu       sync.Mutex
+	calls    int
+	inFlight int
+	peak     int
+	delay    time.Duration
+	failEach int
+}
+
+func (s *fakeSender) NewMessage(value []byte) *kafka.Message {
+	return &kafka.Message{Value: value}
+}
+
+func (s *fakeSender) Send(context.Context, *kafka.Message) error {
+	s.mu.Lock()
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	s.mu.Unlock()
+
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	s.calls++
+	if s.failEach > 0 && s.calls%s.failEach == 0 {
+		return errors.New("delivery failed: Local: Message timed out")
+	}
+	return nil
+}
+
+func TestGenerateRealisticOrder(t *testing.T) {
+	generator := NewOrderGenerator(42)
+	decoder := ingest.NewDecoder(rules.NewEngine(map[string]rules.Action{
+		rules.RulePaymentAmount:  rules.ActionReject,
+		rules.RuleItemTotalPrice: rules.ActionReject,
+	}))
+
+	locales := map[string]int{}
+	currencies := map[string]int{}
+	items := map[int]int{}
+	const n = 2000
+	for i := 0; i < n; i++ {
+		order := generator.GenerateRealisticOrder()
+		payload, err := json.Marshal(order)
+		require.NoError(t, err)
+		_, result, err := decoder.Decode(payload)
+		require.NoError(t, err)
+		require.Empty(t, result.Violations)
+
+		locales[order.Locale]++
+		currencies[order.Payment.Currency]++
+		items[len(order.Items)]++
+	}
+
+	assert.InDelta(t, 0.7, float64(locales["ru"])/n, 0.05)
+	assert.InDelta(t, 0.75, float64(currencies["RUB"])/n, 0.05)
+	assert.InDelta(t, 0.4, float64(items[1])/n, 0.05)
+	assert.Greater(t, items[1], items[2])
+	assert.Greater(t, items[2], items[5])
+}
+
+func TestLoadReport_Percentile(t *testing.T) {
+	report := &LoadReport{}
+	assert.Zero(t, report.Percentile(50))
+
+	for i := 1; i <= 100; i++ {
+		report.Latencies = append(report.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, report.Percentile(50))
+	assert.Equal(t, 99*time.Millisecond, report.Percentile(99))
+	assert.Equal(t, 100*time.Millisecond, report.Percentile(100))
+	assert.Equal(t, time.Millisecond, report.Percentile(0))
+}
+
+func TestRunLoad(t *testing.T) {
+	s := &fakeSender{delay: 5 * time.Millisecond, failEach: 10}
+
+	report := RunLoad(context.Background(), s, NewOrderGenerator(1), LoadConfig{
+		Concurrency: 4,
+		Duration:    200 * time.Millisecond,
+	})
+
+	require.Positive(t, report.Sent)
+	assert.Equal(t, s.calls, report.Sent)
+	assert.Equal(t, report.Sent/10, report.Failed)
+	assert.Len(t, report.Latencies, report.Sent-report.Failed)
+	assert.Equal(t, map[string]int{"delivery failed: Local: Message timed out": report.Failed}, report.Errors)
+	assert.Equal(t, 4, s.peak)
+	assert.GreaterOrEqual(t, report.Percentile(50), 5*time.Millisecond)
+	assert.Positive(t, report.Throughput())
+
+	var out bytes.Buffer
+	report.Print(&out)
+	assert.Contains(t, out.String(), "Throughput:")
+	assert.Contains(t, out.String(), "p99=")
+	assert.Contains(t, out.String(), "Message timed out")
+}
+
+func TestRunLoad_Rate(t *testing.T) {
+	s := &fakeSender{}
+
+	report := RunLoad(context.Background(), s, NewOrderGenerator(1), LoadConfig{
+		Rate:        50,
+		Concurrency: 2,
+		Duration:    400 * time.Millisecond,
+	})
+
+	assert.InDelta(t, 20, report.Sent, 5)
+	assert.Zero(t, report.Failed)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -11,17 +12,49 @@ import (
 	"syscall"
 	"time"
 
+	serviceconfig "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/config"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	modeGenerate = "generate"
+	modeReplay   = "replay"
+	modeLoad     = "load"
+)
+
+const (
+	tracerName         = "order-producer"
+	defaultServiceName = "order-producer"
 )
 
 type Config struct {
 	BootstrapServers string
 	Topic            string
+	Mode             string
 	ValidCount       int
 	InvalidCount     int
 	DelayMs          int
 	Seed             int64
+	KeyStrategy      string
+	CorrelationID    string
+	TracingExporter  string
+	TracingEndpoint  string
+	Replay           ReplayConfig
+	Load             LoadConfig
+}
+
+type ReplayConfig struct {
+	Input      string
+	Key        string
+	Headers    []kafka.Header
+	Rate       float64
+	DryRun     bool
+	ConfigPath string
 }
 
 type OrderGenerator struct {
@@ -200,41 +233,67 @@ func (g *OrderGenerator) generateRID() string {
 }
 
 type KafkaProducer struct {
-	producer *kafka.Producer
-	topic    string
+	producer      *kafka.Producer
+	topic         string
+	key           KeyFunc
+	correlationID string
 }
 
 func NewKafkaProducer(config Config) (*KafkaProducer, error) {
+	key, err := ParseKeyStrategy(config.KeyStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Idempotence keeps messages with the same key in order even when several
+	// are in flight, as in load mode.
 	p, err := kafka.NewProducer(&kafka.ConfigMap{
-		"bootstrap.servers": config.BootstrapServers,
-		"acks":              "all",
+		"bootstrap.servers":  config.BootstrapServers,
+		"acks":               "all",
+		"enable.idempotence": true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create producer: %w", err)
 	}
 
 	return &KafkaProducer{
-		producer: p,
-		topic:    config.Topic,
+		producer:      p,
+		topic:         config.Topic,
+		key:           key,
+		correlationID: config.CorrelationID,
 	}, nil
 }
 
-func (kp *KafkaProducer) SendOrder(order *model.Order) error {
+func (kp *KafkaProducer) SendOrder(ctx context.Context, order *model.Order) error {
 	value, err := json.Marshal(order)
 	if err != nil {
 		return fmt.Errorf("failed to marshal order: %w", err)
 	}
+	return kp.Send(ctx, kp.NewMessage(value))
+}
+
+// Send produces msg to the configured topic within a producer span, whose
+// trace context goes into the message headers, and waits for its delivery.
+func (kp *KafkaProducer) Send(ctx context.Context, msg *kafka.Message) (err error) {
+	ctx, span := tracing.Tracer(tracerName).Start(ctx, kp.topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(kp.topic),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+		))
+	defer func() { tracing.End(span, err) }()
+	tracing.InjectKafkaHeaders(ctx, msg)
 
 	deliveryChan := make(chan kafka.Event, 1)
 	defer close(deliveryChan)
 
-	err = kp.producer.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{
-			Topic:     &kp.topic,
-			Partition: kafka.PartitionAny,
-		},
-		Value: value,
-	}, deliveryChan)
+	msg.TopicPartition = kafka.TopicPartition{
+		Topic:     &kp.topic,
+		Partition: kafka.PartitionAny,
+	}
+	err = kp.producer.Produce(msg, deliveryChan)
 
 	if err != nil {
 		return fmt.Errorf("failed to produce message: %w", err)
@@ -255,20 +314,57 @@ func (kp *KafkaProducer) Close() {
 	kp.producer.Close()
 }
 
-func main() {
-	defaultBootstrap := os.Getenv("KAFKA_BOOTSTRAP_SERVERS")
-	if defaultBootstrap == "" {
-		defaultBootstrap = "localhost:19092"
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
 	}
+	return fallback
+}
+
+func setupTracing(cfg Config) (func(), error) {
+	provider, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		ServiceName: defaultServiceName,
+		SampleRatio: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			log.Printf("Failed to flush spans: %v", err)
+		}
+	}, nil
+}
+
+func main() {
+	defaultBootstrap := envOrDefault("KAFKA_BOOTSTRAP_SERVERS", "localhost:19092")
 
 	var (
 		bootstrapServers = flag.String("bootstrap-servers", defaultBootstrap, "Kafka bootstrap servers")
 		topic            = flag.String("topic", "orders", "Kafka topic")
+		mode             = flag.String("mode", modeGenerate, "Mode: generate random orders, replay fixtures or load test")
 		validCount       = flag.Int("valid-count", 10, "Number of valid orders to generate")
 		invalidCount     = flag.Int("invalid-count", 5, "Number of invalid orders to generate")
 		delayMs          = flag.Int("delay-ms", 100, "Delay between messages in milliseconds")
 		seed             = flag.Int64("seed", time.Now().UnixNano(), "Random seed")
+		keyStrategy      = flag.String("key-strategy", KeyOrderUID, "Message key: order_uid, customer_id, random or none")
+		correlationID    = flag.String("correlation-id", "", "Correlation id header for every message, logged by the consumer")
+		tracingExporter  = flag.String("tracing-exporter", envOrDefault("TRACING_EXPORTER", tracing.ExporterNone), "Trace exporter: none, stdout or otlp")
+		tracingEndpoint  = flag.String("tracing-endpoint", envOrDefault("TRACING_OTLP_ENDPOINT", "localhost:4317"), "OTLP gRPC endpoint")
+		input            = flag.String("input", "", "Replay: JSON or NDJSON file, or a directory of them")
+		key              = flag.String("key", "", "Replay: message key for every message, overrides -key-strategy")
+		rateLimit        = flag.Float64("rate", 0, "Replay and load: messages per second, 0 for no limit")
+		concurrency      = flag.Int("concurrency", 8, "Load: number of messages in flight")
+		duration         = flag.Duration("duration", 30*time.Second, "Load: how long to send, 0 to run until interrupted")
+		dryRun           = flag.Bool("dry-run", false, "Replay: validate payloads like the consumer instead of sending")
+		configPath       = flag.String("config", os.Getenv(serviceconfig.EnvFile), "Replay: service config file with the rule actions for -dry-run")
+		headers          headerFlags
 	)
+	flag.Var(&headers, "header", "Replay: message header as key=value, overrides a standard header, may be repeated")
 	flag.Parse()
 
 	config := Config{
@@ -276,10 +372,53 @@ func main() {
 		Topic:            *topic,
 		ValidCount:       *validCount,
 		InvalidCount:     *invalidCount,
+		Mode:             *mode,
 		DelayMs:          *delayMs,
 		Seed:             *seed,
+		KeyStrategy:      *keyStrategy,
+		CorrelationID:    *correlationID,
+		TracingExporter:  *tracingExporter,
+		TracingEndpoint:  *tracingEndpoint,
+		Replay: ReplayConfig{
+			Input:      *input,
+			Key:        *key,
+			Headers:    headers,
+			Rate:       *rateLimit,
+			DryRun:     *dryRun,
+			ConfigPath: *configPath,
+		},
+		Load: LoadConfig{
+			Rate:        *rateLimit,
+			Concurrency: *concurrency,
+			Duration:    *duration,
+		},
+	}
+
+	shutdownTracing, err := setupTracing(config)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
 	}
 
+	var run func(Config) error
+	switch config.Mode {
+	case modeGenerate:
+	case modeReplay:
+		run = runReplay
+	case modeLoad:
+		run = runLoad
+	default:
+		log.Fatalf("Unknown mode %q, expected %s, %s or %s", config.Mode, modeGenerate, modeReplay, modeLoad)
+	}
+	if run != nil {
+		err := run(config)
+		shutdownTracing()
+		if err != nil {
+			log.Fatalf("Failed to %s: %v", config.Mode, err)
+		}
+		return
+	}
+	defer shutdownTracing()
+
 	generator := NewOrderGenerator(config.Seed)
 
 	producer, err := NewKafkaProducer(config)
@@ -291,6 +430,7 @@ func main() {
 	fmt.Printf("Starting order generator with config:\n")
 	fmt.Printf("- Bootstrap servers: %s\n", config.BootstrapServers)
 	fmt.Printf("- Topic: %s\n", config.Topic)
+	fmt.Printf("- Key strategy: %s\n", config.KeyStrategy)
 	fmt.Printf("- Valid orders: %d\n", config.ValidCount)
 	fmt.Printf("- Invalid orders: %d\n", config.InvalidCount)
 	fmt.Printf("- Delay: %dms\n", config.DelayMs)
@@ -303,13 +443,16 @@ func main() {
 		<-sigchan
 		fmt.Println("\nReceived termination signal, shutting down...")
 		producer.Close()
+		shutdownTracing()
 		os.Exit(0)
 	}()
 
+	ctx := context.Background()
+
 	fmt.Printf("Generating %d valid orders...\n", config.ValidCount)
 	for i := 0; i < config.ValidCount; i++ {
 		order := generator.GenerateValidOrder()
-		if err := producer.SendOrder(order); err != nil {
+		if err := producer.SendOrder(ctx, order); err != nil {
 			log.Printf("Failed to send valid order %d: %v", i+1, err)
 		} else {
 			fmt.Printf("Sent valid order %d/%d (UID: %s)\n", i+1, config.ValidCount, order.OrderUID)
@@ -323,7 +466,7 @@ func main() {
 	fmt.Printf("\nGenerating %d invalid orders...\n", config.InvalidCount)
 	for i := 0; i < config.InvalidCount; i++ {
 		order := generator.GenerateInvalidOrder()
-		if err := producer.SendOrder(order); err != nil {
+		if err := producer.SendOrder(ctx, order); err != nil {
 			log.Printf("Failed to send invalid order %d: %v", i+1, err)
 		} else {
 			fmt.Printf("Sent invalid order %d/%d (UID: %s)\n", i+1, config.InvalidCount, order.OrderUID)
@@ -336,3 +479,69 @@ func main() {
 
 	fmt.Println("\nAll orders sent successfully!")
 }
+
+func runReplay(cfg Config) error {
+	if cfg.Replay.Input == "" {
+		return fmt.Errorf("-input is required in replay mode")
+	}
+	payloads, err := LoadPayloads(cfg.Replay.Input)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Loaded %d payloads from %s\n", len(payloads), cfg.Replay.Input)
+
+	if cfg.Replay.DryRun {
+		serviceCfg, err := serviceconfig.Load(cfg.Replay.ConfigPath)
+		if err != nil {
+			return err
+		}
+		engine, err := serviceCfg.Rules.Engine()
+		if err != nil {
+			return err
+		}
+		if rejected := ValidatePayloads(os.Stdout, ingest.NewDecoder(engine), payloads); rejected > 0 {
+			return fmt.Errorf("%d of %d payloads would be rejected", rejected, len(payloads))
+		}
+		fmt.Println("\nAll payloads are valid")
+		return nil
+	}
+
+	producer, err := NewKafkaProducer(cfg)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	sent, err := Replay(ctx, producer, payloads, cfg.Replay.Key, cfg.Replay.Headers, cfg.Replay.Rate)
+	fmt.Printf("\nReplayed %d of %d payloads\n", sent, len(payloads))
+	return err
+}
+
+func runLoad(cfg Config) error {
+	producer, err := NewKafkaProducer(cfg)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	fmt.Printf("Starting load with config:\n")
+	fmt.Printf("- Bootstrap servers: %s\n", cfg.BootstrapServers)
+	fmt.Printf("- Topic: %s\n", cfg.Topic)
+	fmt.Printf("- Target rate: %g msg/s\n", cfg.Load.Rate)
+	fmt.Printf("- Concurrency: %d\n", cfg.Load.Concurrency)
+	fmt.Printf("- Duration: %s\n", cfg.Load.Duration)
+	fmt.Printf("- Random seed: %d\n\n", cfg.Seed)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report := RunLoad(ctx, producer, NewOrderGenerator(cfg.Seed), cfg.Load)
+	report.Print(os.Stdout)
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d messages failed", report.Failed, report.Sent)
+	}
+	return nil
+}
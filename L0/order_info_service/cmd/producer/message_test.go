@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	kafkaconsumer "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/kafka_consumer"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func headerMap(msg *kafka.Message) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	return headers
+}
+
+func TestParseKeyStrategy(t *testing.T) {
+	order := []byte(`{"order_uid":"b563feb7b2b84b6test","customer_id":"test"}`)
+
+	for strategy, want := range map[string][]byte{
+		KeyOrderUID:   []byte("b563feb7b2b84b6test"),
+		KeyCustomerID: []byte("test"),
+		KeyNone:       nil,
+	} {
+		key, err := ParseKeyStrategy(strategy)
+		require.NoError(t, err, strategy)
+		assert.Equal(t, want, key(order), strategy)
+	}
+
+	key, err := ParseKeyStrategy(KeyOrderUID)
+	require.NoError(t, err)
+	assert.Nil(t, key([]byte(`{"order_uid":`)), "broken payloads get no key")
+	assert.Nil(t, key([]byte(`{"track_number":"WBILMTESTTRACK"}`)))
+
+	key, err = ParseKeyStrategy(KeyRandom)
+	require.NoError(t, err)
+	assert.Len(t, key(order), 16)
+	assert.NotEqual(t, key(order), key(order))
+
+	_, err = ParseKeyStrategy("track_number")
+	require.Error(t, err)
+}
+
+func TestNewMessage(t *testing.T) {
+	key, err := ParseKeyStrategy(KeyOrderUID)
+	require.NoError(t, err)
+	kp := &KafkaProducer{topic: "orders", key: key}
+	value := []byte(`{"order_uid":"b563feb7b2b84b6test"}`)
+
+	msg := kp.NewMessage(value)
+
+	assert.Equal(t, []byte("b563feb7b2b84b6test"), msg.Key)
+	assert.Equal(t, value, msg.Value)
+	headers := headerMap(msg)
+	assert.Equal(t, ContentTypeJSON, headers[HeaderContentType])
+	assert.Equal(t, SchemaVersion, headers[HeaderSchemaVersion])
+	assert.Len(t, headers[HeaderEventID], 32)
+	producedAt, err := time.Parse(time.RFC3339Nano, headers[HeaderProducedAt])
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), producedAt, time.Minute)
+	assert.NotContains(t, headers, kafkaconsumer.CorrelationIDHeader)
+
+	assert.NotEqual(t, headers[HeaderEventID], headerMap(kp.NewMessage(value))[HeaderEventID])
+
+	kp.correlationID = "replay-incident-42"
+	assert.Equal(t, "replay-incident-42", headerMap(kp.NewMessage(value))[kafkaconsumer.CorrelationIDHeader])
+}
+
+func TestSetHeader(t *testing.T) {
+	msg := &kafka.Message{Headers: []kafka.Header{{Key: HeaderSchemaVersion, Value: []byte(SchemaVersion)}}}
+
+	setHeader(msg, HeaderSchemaVersion, "2")
+	setHeader(msg, "x-debug", "1")
+
+	assert.Equal(t, []kafka.Header{
+		{Key: HeaderSchemaVersion, Value: []byte("2")},
+		{Key: "x-debug", Value: []byte("1")},
+	}, msg.Headers)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"golang.org/x/time/rate"
+)
+
+// maxLineSize bounds a single NDJSON line.
+const maxLineSize = 16 << 20
+
+// Payload is a message value read from a fixture, sent as is. Source tells
+// where it came from, as file:line for NDJSON and file[index] for arrays.
+type Payload struct {
+	Source string
+	Value  []byte
+}
+
+// headerFlags collects repeated -header key=value flags.
+type headerFlags []kafka.Header
+
+func (h *headerFlags) String() string {
+	parts := make([]string, len(*h))
+	for i, header := range *h {
+		parts[i] = header.Key + "=" + string(header.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (h *headerFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*h = append(*h, kafka.Header{Key: key, Value: []byte(val)})
+	return nil
+}
+
+func isFixture(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".ndjson", ".jsonl":
+		return true
+	}
+	return false
+}
+
+// LoadPayloads reads payloads from a .json file holding one order, several
+// concatenated orders or an array of them, from a .ndjson or .jsonl file with
+// one order per line, or from every such file in a directory tree, in
+// lexical order.
+func LoadPayloads(path string) ([]Payload, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return loadFile(path)
+	}
+
+	var payloads []Payload
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isFixture(file) {
+			return err
+		}
+		filePayloads, err := loadFile(file)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, filePayloads...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payloads, nil
+}
+
+func loadFile(path string) ([]Payload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return loadNDJSON(path, f)
+	case ".json":
+		return loadJSON(path, f)
+	default:
+		return nil, fmt.Errorf("%s: unsupported fixture, expected .json, .ndjson or .jsonl", path)
+	}
+}
+
+func loadNDJSON(path string, r io.Reader) ([]Payload, error) {
+	var payloads []Payload
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		value := bytes.TrimSpace(scanner.Bytes())
+		if len(value) == 0 {
+			continue
+		}
+		payloads = append(payloads, Payload{
+			Source: fmt.Sprintf("%s:%d", path, line),
+			Value:  append([]byte(nil), value...),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return payloads, nil
+}
+
+func loadJSON(path string, r io.Reader) ([]Payload, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var values []json.RawMessage
+		if err := json.Unmarshal(trimmed, &values); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		payloads := make([]Payload, len(values))
+		for i, value := range values {
+			payloads[i] = Payload{Source: fmt.Sprintf("%s[%d]", path, i), Value: value}
+		}
+		return payloads, nil
+	}
+
+	var payloads []Payload
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	for i := 0; ; i++ {
+		var value json.RawMessage
+		if err := dec.Decode(&value); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		payloads = append(payloads, Payload{Source: fmt.Sprintf("%s[%d]", path, i), Value: value})
+	}
+	return payloads, nil
+}
+
+// ValidatePayloads decodes payloads the way the consumer does and reports
+// each one. It returns the number of payloads the consumer would reject.
+func ValidatePayloads(w io.Writer, decoder *ingest.Decoder, payloads []Payload) int {
+	rejected := 0
+	for _, p := range payloads {
+		order, result, err := decoder.Decode(p.Value)
+		if rejection, ok := ingest.AsRejection(err); ok {
+			rejected++
+			fmt.Fprintf(w, "REJECT %s: %s failed: %s\n", p.Source, rejection.Stage, strings.Join(rejection.Errors, "; "))
+			continue
+		}
+
+		fmt.Fprintf(w, "OK     %s (UID: %s)\n", p.Source, order.OrderUID)
+		for _, v := range result.Violations {
+			fmt.Fprintf(w, "       %s %s: %s\n", v.Action, v.Rule, v.Message)
+		}
+	}
+	return rejected
+}
+
+// Replay sends payloads in order, waiting for each delivery, at no more than
+// ratePerSec messages per second (no limit if zero).
+func Replay(ctx context.Context, producer *KafkaProducer, payloads []Payload, key string, headers []kafka.Header, ratePerSec float64) (int, error) {
+	limit := rate.Inf
+	if ratePerSec > 0 {
+		limit = rate.Limit(ratePerSec)
+	}
+	limiter := rate.NewLimiter(limit, 1)
+
+	sent := 0
+	for _, p := range payloads {
+		if err := limiter.Wait(ctx); err != nil {
+			return sent, err
+		}
+
+		msg := producer.NewMessage(p.Value)
+		if key != "" {
+			msg.Key = []byte(key)
+		}
+		for _, h := range headers {
+			setHeader(msg, h.Key, string(h.Value))
+		}
+		if err := producer.Send(ctx, msg); err != nil {
+			return sent, fmt.Errorf("%s: %w", p.Source, err)
+		}
+		sent++
+		fmt.Printf("Sent %s (%d/%d)\n", p.Source, sent, len(payloads))
+	}
+	return sent, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFixture(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPayloads_Files(t *testing.T) {
+	dir := t.TempDir()
+	for name, tc := range map[string]struct {
+		content string
+		sources []string
+		values  []string
+	}{
+		"single.json": {
+			content: `{"order_uid": "a"}`,
+			sources: []string{"[0]"},
+			values:  []string{`{"order_uid": "a"}`},
+		},
+		"concatenated.json": {
+			content: "{\"order_uid\":\"a\"}\n{\"order_uid\":\"b\"}\n",
+			sources: []string{"[0]", "[1]"},
+			values:  []string{`{"order_uid":"a"}`, `{"order_uid":"b"}`},
+		},
+		"array.json": {
+			content: ` [{"order_uid":"a"}, {"order_uid" : "b"}] `,
+			sources: []string{"[0]", "[1]"},
+			values:  []string{`{"order_uid":"a"}`, `{"order_uid" : "b"}`},
+		},
+		"orders.ndjson": {
+			content: "{\"order_uid\":\"a\"}\n\n  {\"order_uid\":\"b\"}  \n",
+			sources: []string{":1", ":3"},
+			values:  []string{`{"order_uid":"a"}`, `{"order_uid":"b"}`},
+		},
+	} {
+		path := writeFixture(t, dir, name, tc.content)
+
+		payloads, err := LoadPayloads(path)
+		require.NoError(t, err, name)
+		require.Len(t, payloads, len(tc.values), name)
+		for i, p := range payloads {
+			assert.Equal(t, path+tc.sources[i], p.Source, name)
+			assert.Equal(t, tc.values[i], string(p.Value), name)
+		}
+	}
+}
+
+func TestLoadPayloads_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "b.ndjson", "{\"order_uid\":\"b1\"}\n{\"order_uid\":\"b2\"}\n")
+	writeFixture(t, dir, "a.json", `{"order_uid":"a"}`)
+	writeFixture(t, dir, "nested/c.jsonl", `{"order_uid":"c"}`)
+	writeFixture(t, dir, "README.md", "not a fixture")
+
+	payloads, err := LoadPayloads(dir)
+	require.NoError(t, err)
+
+	var values []string
+	for _, p := range payloads {
+		values = append(values, string(p.Value))
+	}
+	assert.Equal(t, []string{
+		`{"order_uid":"a"}`, `{"order_uid":"b1"}`, `{"order_uid":"b2"}`, `{"order_uid":"c"}`,
+	}, values)
+}
+
+func TestLoadPayloads_Invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadPayloads(writeFixture(t, dir, "broken.json", `[{"order_uid":`))
+	require.Error(t, err)
+
+	_, err = LoadPayloads(writeFixture(t, dir, "orders.txt", `{}`))
+	require.Error(t, err)
+
+	_, err = LoadPayloads(filepath.Join(dir, "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestHeaderFlags(t *testing.T) {
+	var headers headerFlags
+	require.NoError(t, headers.Set("x-debug=1"))
+	require.NoError(t, headers.Set("note=a=b"))
+	require.Error(t, headers.Set("missing-value"))
+	require.Error(t, headers.Set("=value"))
+
+	assert.Equal(t, headerFlags{
+		{Key: "x-debug", Value: []byte("1")},
+		{Key: "note", Value: []byte("a=b")},
+	}, headers)
+	assert.Equal(t, "x-debug=1,note=a=b", headers.String())
+}
+
+func TestValidatePayloads(t *testing.T) {
+	generator := NewOrderGenerator(1)
+	valid, err := json.Marshal(generator.GenerateValidOrder())
+	require.NoError(t, err)
+
+	invalid := generator.GenerateValidOrder()
+	invalid.Locale = "es"
+	invalidValue, err := json.Marshal(invalid)
+	require.NoError(t, err)
+
+	payloads := []Payload{
+		{Source: "valid.json[0]", Value: valid},
+		{Source: "invalid.json[0]", Value: invalidValue},
+		{Source: "broken.ndjson:1", Value: []byte(`{"order_uid":`)},
+	}
+	decoder := ingest.NewDecoder(rules.NewEngine(map[string]rules.Action{
+		rules.RuleItemTotalPrice: rules.ActionOff,
+	}))
+
+	var out bytes.Buffer
+	rejected := ValidatePayloads(&out, decoder, payloads)
+
+	assert.Equal(t, 2, rejected)
+	assert.Contains(t, out.String(), "OK     valid.json[0]")
+	assert.Contains(t, out.String(), "REJECT invalid.json[0]: validation failed: Locale: oneof")
+	assert.Contains(t, out.String(), "REJECT broken.ndjson:1: decode failed")
+}
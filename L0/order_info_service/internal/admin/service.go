@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/warmup"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+)
+
+type Consumer interface {
+	Pause() error
+	Resume() error
+	Paused() bool
+	Seek(partition int32, offset int64) error
+	SeekToTime(partition int32, ts time.Time) (int64, error)
+}
+
+type ConsumerStatus struct {
+	Paused bool `json:"paused"`
+}
+
+type SeekRequest struct {
+	Partition int32      `json:"partition"`
+	Offset    *int64     `json:"offset,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+type SeekResult struct {
+	Partition int32 `json:"partition"`
+	Offset    int64 `json:"offset"`
+}
+
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+type AdminProvider interface {
+	CacheStats() cache.Stats
+	EvictOrder(string) error
+	ClearCache()
+	WarmUpCache(int) (warmup.Progress, error)
+	WarmUpProgress() warmup.Progress
+	ConsumerStatus() (*ConsumerStatus, error)
+	PauseConsumer() (*ConsumerStatus, error)
+	ResumeConsumer() (*ConsumerStatus, error)
+	SeekConsumer(*SeekRequest) (*SeekResult, error)
+	LogLevel() (*LogLevel, error)
+	SetLogLevel(*LogLevel) (*LogLevel, error)
+}
+
+type Service struct {
+	cache    cache.Cache
+	warmup   warmup.WarmupProvider
+	consumer Consumer
+	levels   logger.LevelController
+	logger   logger.Logger
+}
+
+func NewService(cache cache.Cache, warmup warmup.WarmupProvider, consumer Consumer, levels logger.LevelController, logger logger.Logger) *Service {
+	return &Service{
+		cache:    cache,
+		warmup:   warmup,
+		consumer: consumer,
+		levels:   levels,
+		logger:   logger,
+	}
+}
+
+func (s *Service) CacheStats() cache.Stats {
+	return s.cache.Stats()
+}
+
+func (s *Service) EvictOrder(orderUID string) error {
+	if !s.cache.Delete(orderUID) {
+		return fmt.Errorf("%w: order %s is not cached", srvcerrors.ErrNotFound, orderUID)
+	}
+	s.logger.Info("admin: evicted order from cache", zap.String("order_uid", orderUID))
+	return nil
+}
+
+func (s *Service) ClearCache() {
+	s.cache.Clear()
+	s.logger.Info("admin: cache cleared")
+}
+
+func (s *Service) WarmUpCache(limit int) (warmup.Progress, error) {
+	progress, err := s.warmup.Trigger(limit)
+	if err != nil {
+		return progress, err
+	}
+
+	s.logger.Info("admin: cache warmup triggered", zap.Int("limit", limit))
+	return progress, nil
+}
+
+func (s *Service) WarmUpProgress() warmup.Progress {
+	return s.warmup.Progress()
+}
+
+func (s *Service) ConsumerStatus() (*ConsumerStatus, error) {
+	if s.consumer == nil {
+		return nil, srvcerrors.ErrKafka
+	}
+	return &ConsumerStatus{Paused: s.consumer.Paused()}, nil
+}
+
+func (s *Service) PauseConsumer() (*ConsumerStatus, error) {
+	if s.consumer == nil {
+		return nil, srvcerrors.ErrKafka
+	}
+	if err := s.consumer.Pause(); err != nil {
+		return nil, err
+	}
+	return s.ConsumerStatus()
+}
+
+func (s *Service) ResumeConsumer() (*ConsumerStatus, error) {
+	if s.consumer == nil {
+		return nil, srvcerrors.ErrKafka
+	}
+	if err := s.consumer.Resume(); err != nil {
+		return nil, err
+	}
+	return s.ConsumerStatus()
+}
+
+func (s *Service) SeekConsumer(req *SeekRequest) (*SeekResult, error) {
+	if s.consumer == nil {
+		return nil, srvcerrors.ErrKafka
+	}
+	if req.Partition < 0 || (req.Offset == nil) == (req.Timestamp == nil) {
+		return nil, fmt.Errorf("%w: seek needs a partition and exactly one of offset or timestamp", srvcerrors.ErrInvalidInput)
+	}
+
+	if req.Offset != nil {
+		if err := s.consumer.Seek(req.Partition, *req.Offset); err != nil {
+			return nil, err
+		}
+		return &SeekResult{Partition: req.Partition, Offset: *req.Offset}, nil
+	}
+
+	offset, err := s.consumer.SeekToTime(req.Partition, *req.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return &SeekResult{Partition: req.Partition, Offset: offset}, nil
+}
+
+func (s *Service) LogLevel() (*LogLevel, error) {
+	if s.levels == nil {
+		return nil, fmt.Errorf("%w: log level is not adjustable", srvcerrors.ErrUnavailable)
+	}
+	return &LogLevel{Level: s.levels.Level()}, nil
+}
+
+func (s *Service) SetLogLevel(req *LogLevel) (*LogLevel, error) {
+	if s.levels == nil {
+		return nil, fmt.Errorf("%w: log level is not adjustable", srvcerrors.ErrUnavailable)
+	}
+
+	previous := s.levels.Level()
+	if err := s.levels.SetLevel(req.Level); err != nil {
+		return nil, err
+	}
+
+	s.logger.Warn("admin: log level changed", zap.String("from", previous), zap.String("to", s.levels.Level()))
+	return s.LogLevel()
+}
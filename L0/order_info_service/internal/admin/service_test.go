@@ -0,0 +1,160 @@
+package admin_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/admin"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/warmup"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWarmup struct {
+	limit int
+}
+
+func (f *fakeWarmup) Trigger(limit int) (warmup.Progress, error) {
+	f.limit = limit
+	return warmup.Progress{State: warmup.StateRunning, Limit: limit}, nil
+}
+
+func (f *fakeWarmup) Progress() warmup.Progress {
+	return warmup.Progress{State: warmup.StateCompleted, Limit: f.limit, Loaded: f.limit}
+}
+
+type fakeConsumer struct {
+	paused    bool
+	partition int32
+	offset    int64
+	ts        time.Time
+}
+
+func (f *fakeConsumer) Pause() error  { f.paused = true; return nil }
+func (f *fakeConsumer) Resume() error { f.paused = false; return nil }
+func (f *fakeConsumer) Paused() bool  { return f.paused }
+
+func (f *fakeConsumer) Seek(partition int32, offset int64) error {
+	f.partition, f.offset = partition, offset
+	return nil
+}
+
+func (f *fakeConsumer) SeekToTime(partition int32, ts time.Time) (int64, error) {
+	f.partition, f.ts = partition, ts
+	return 17, nil
+}
+
+func TestService_Cache(t *testing.T) {
+	c := cache.NewLocalCache()
+	c.SetOrder(&model.Order{OrderUID: "order-1"})
+	c.SetOrder(&model.Order{OrderUID: "order-2"})
+	svc := admin.NewService(c, &fakeWarmup{}, nil, nil, &loggertest.MockLogger{})
+
+	assert.Equal(t, 2, svc.CacheStats().Orders)
+
+	require.NoError(t, svc.EvictOrder("order-1"))
+	require.ErrorIs(t, svc.EvictOrder("order-1"), srvcerrors.ErrNotFound)
+
+	svc.ClearCache()
+	stats := svc.CacheStats()
+	assert.Equal(t, 0, stats.Orders)
+	assert.EqualValues(t, 2, stats.Evictions)
+}
+
+func TestService_WarmUpCache(t *testing.T) {
+	warmer := &fakeWarmup{}
+	svc := admin.NewService(cache.NewLocalCache(), warmer, nil, nil, &loggertest.MockLogger{})
+
+	progress, err := svc.WarmUpCache(500)
+
+	require.NoError(t, err)
+	assert.Equal(t, warmup.StateRunning, progress.State)
+	assert.Equal(t, 500, warmer.limit)
+	assert.Equal(t, 500, svc.WarmUpProgress().Loaded)
+}
+
+func TestService_Consumer(t *testing.T) {
+	consumer := &fakeConsumer{}
+	svc := admin.NewService(cache.NewLocalCache(), &fakeWarmup{}, consumer, nil, &loggertest.MockLogger{})
+
+	status, err := svc.PauseConsumer()
+	require.NoError(t, err)
+	assert.True(t, status.Paused)
+
+	status, err = svc.ResumeConsumer()
+	require.NoError(t, err)
+	assert.False(t, status.Paused)
+
+	offset := int64(42)
+	result, err := svc.SeekConsumer(&admin.SeekRequest{Partition: 1, Offset: &offset})
+	require.NoError(t, err)
+	assert.Equal(t, &admin.SeekResult{Partition: 1, Offset: 42}, result)
+	assert.EqualValues(t, 42, consumer.offset)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	result, err = svc.SeekConsumer(&admin.SeekRequest{Partition: 0, Timestamp: &ts})
+	require.NoError(t, err)
+	assert.EqualValues(t, 17, result.Offset)
+	assert.Equal(t, ts, consumer.ts)
+}
+
+func TestService_SeekInvalid(t *testing.T) {
+	svc := admin.NewService(cache.NewLocalCache(), &fakeWarmup{}, &fakeConsumer{}, nil, &loggertest.MockLogger{})
+	offset := int64(1)
+	ts := time.Now()
+
+	for _, req := range []*admin.SeekRequest{
+		{Partition: 0},
+		{Partition: 0, Offset: &offset, Timestamp: &ts},
+		{Partition: -1, Offset: &offset},
+	} {
+		_, err := svc.SeekConsumer(req)
+		require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	}
+}
+
+func TestService_NoConsumer(t *testing.T) {
+	svc := admin.NewService(cache.NewLocalCache(), &fakeWarmup{}, nil, nil, &loggertest.MockLogger{})
+
+	_, err := svc.PauseConsumer()
+
+	require.ErrorIs(t, err, srvcerrors.ErrKafka)
+}
+
+type fakeLevels struct {
+	level string
+}
+
+func (f *fakeLevels) Level() string { return f.level }
+
+func (f *fakeLevels) SetLevel(level string) error {
+	if level != "debug" && level != "info" {
+		return srvcerrors.ErrInvalidInput
+	}
+	f.level = level
+	return nil
+}
+
+func TestService_LogLevel(t *testing.T) {
+	levels := &fakeLevels{level: "info"}
+	svc := admin.NewService(cache.NewLocalCache(), &fakeWarmup{}, nil, levels, &loggertest.MockLogger{})
+
+	level, err := svc.SetLogLevel(&admin.LogLevel{Level: "debug"})
+	require.NoError(t, err)
+	assert.Equal(t, &admin.LogLevel{Level: "debug"}, level)
+
+	_, err = svc.SetLogLevel(&admin.LogLevel{Level: "loud"})
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+
+	level, err = svc.LogLevel()
+	require.NoError(t, err)
+	assert.Equal(t, "debug", level.Level)
+
+	svc = admin.NewService(cache.NewLocalCache(), &fakeWarmup{}, nil, nil, &loggertest.MockLogger{})
+	_, err = svc.LogLevel()
+	require.ErrorIs(t, err, srvcerrors.ErrUnavailable)
+}
@@ -0,0 +1,251 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultRange  = 30 * 24 * time.Hour
+	DefaultBucket = model.BucketDay
+	DefaultLimit  = 10
+	MaxLimit      = 100
+)
+
+var maxRangeByBucket = map[string]time.Duration{
+	model.BucketHour:  31 * 24 * time.Hour,
+	model.BucketDay:   2 * 366 * 24 * time.Hour,
+	model.BucketWeek:  5 * 366 * 24 * time.Hour,
+	model.BucketMonth: 10 * 366 * 24 * time.Hour,
+}
+
+type AnalyticsProvider interface {
+	Revenue(context.Context, model.AnalyticsQuery) ([]*model.RevenuePoint, error)
+	OrderCounts(context.Context, string, model.AnalyticsQuery) ([]*model.OrderCountPoint, error)
+	TopBrands(context.Context, model.AnalyticsQuery) ([]*model.BrandStat, error)
+	TopProducts(context.Context, model.AnalyticsQuery) ([]*model.ProductStat, error)
+	BasketSize(context.Context, model.AnalyticsQuery) ([]*model.BasketPoint, error)
+}
+
+type Converter interface {
+	Convert(float64, string, string) (float64, error)
+}
+
+type Service struct {
+	repo      repository.AnalyticsRepositoryProvider
+	logger    logger.Logger
+	converter Converter
+	now       func() time.Time
+}
+
+type Option func(*Service)
+
+func WithConverter(converter Converter) Option {
+	return func(s *Service) {
+		s.converter = converter
+	}
+}
+
+func NewService(repo repository.AnalyticsRepositoryProvider, logger logger.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *Service) Revenue(ctx context.Context, q model.AnalyticsQuery) ([]*model.RevenuePoint, error) {
+	q, err := s.normalize(q)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.RevenueByCurrency(ctx, q)
+	if err != nil {
+		s.logError("analytics: failed to get revenue", q, err)
+		return nil, err
+	}
+
+	if q.Currency == "" {
+		return points, nil
+	}
+	return s.normalizeRevenue(points, q.Currency)
+}
+
+func (s *Service) OrderCounts(ctx context.Context, groupBy string, q model.AnalyticsQuery) ([]*model.OrderCountPoint, error) {
+	if groupBy != model.GroupByDeliveryService && groupBy != model.GroupByLocale {
+		return nil, fmt.Errorf("%w: group_by must be one of %s, %s",
+			srvcerrors.ErrInvalidInput, model.GroupByDeliveryService, model.GroupByLocale)
+	}
+
+	q, err := s.normalize(q)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.OrderCounts(ctx, groupBy, q)
+	if err != nil {
+		s.logError("analytics: failed to get order counts", q, err)
+		return nil, err
+	}
+	return points, nil
+}
+
+func (s *Service) TopBrands(ctx context.Context, q model.AnalyticsQuery) ([]*model.BrandStat, error) {
+	q, err := s.normalize(q)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.TopBrands(ctx, q)
+	if err != nil {
+		s.logError("analytics: failed to get top brands", q, err)
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (s *Service) TopProducts(ctx context.Context, q model.AnalyticsQuery) ([]*model.ProductStat, error) {
+	q, err := s.normalize(q)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.TopProducts(ctx, q)
+	if err != nil {
+		s.logError("analytics: failed to get top products", q, err)
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (s *Service) BasketSize(ctx context.Context, q model.AnalyticsQuery) ([]*model.BasketPoint, error) {
+	q, err := s.normalize(q)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.BasketSize(ctx, q)
+	if err != nil {
+		s.logError("analytics: failed to get basket size", q, err)
+		return nil, err
+	}
+
+	if q.Currency == "" {
+		return points, nil
+	}
+	return s.normalizeBasket(points, q.Currency)
+}
+
+func (s *Service) normalize(q model.AnalyticsQuery) (model.AnalyticsQuery, error) {
+	if q.To.IsZero() {
+		q.To = s.now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-DefaultRange)
+	}
+	if q.Bucket == "" {
+		q.Bucket = DefaultBucket
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultLimit
+	}
+
+	maxRange, ok := maxRangeByBucket[q.Bucket]
+	if !ok {
+		return q, fmt.Errorf("%w: bucket must be one of hour, day, week, month", srvcerrors.ErrInvalidInput)
+	}
+	if !q.From.Before(q.To) {
+		return q, fmt.Errorf("%w: from must be before to", srvcerrors.ErrInvalidInput)
+	}
+	if q.To.Sub(q.From) > maxRange {
+		return q, fmt.Errorf("%w: range is too wide for %s buckets", srvcerrors.ErrInvalidInput, q.Bucket)
+	}
+	if q.Limit < 1 || q.Limit > MaxLimit {
+		return q, fmt.Errorf("%w: limit must be between 1 and %d", srvcerrors.ErrInvalidInput, MaxLimit)
+	}
+	if q.Currency != "" {
+		if s.converter == nil {
+			return q, fmt.Errorf("%w: currency conversion is not configured", srvcerrors.ErrInvalidInput)
+		}
+		q.Currency = strings.ToUpper(q.Currency)
+	}
+
+	return q, nil
+}
+
+func (s *Service) normalizeRevenue(points []*model.RevenuePoint, currency string) ([]*model.RevenuePoint, error) {
+	normalized := make([]*model.RevenuePoint, 0, len(points))
+	revenue := make(map[time.Time]float64)
+	byBucket := make(map[time.Time]*model.RevenuePoint)
+
+	for _, point := range points {
+		converted, err := s.converter.Convert(float64(point.Revenue), point.Currency, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		merged, ok := byBucket[point.Bucket]
+		if !ok {
+			merged = &model.RevenuePoint{Bucket: point.Bucket, Currency: currency}
+			byBucket[point.Bucket] = merged
+			normalized = append(normalized, merged)
+		}
+		merged.Orders += point.Orders
+		revenue[point.Bucket] += converted
+	}
+
+	for _, point := range normalized {
+		point.Revenue = int64(math.Round(revenue[point.Bucket]))
+	}
+	return normalized, nil
+}
+
+func (s *Service) normalizeBasket(points []*model.BasketPoint, currency string) ([]*model.BasketPoint, error) {
+	normalized := make([]*model.BasketPoint, 0, len(points))
+	byBucket := make(map[time.Time]*model.BasketPoint)
+
+	for _, point := range points {
+		converted, err := s.converter.Convert(point.AvgAmount, point.Currency, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		merged, ok := byBucket[point.Bucket]
+		if !ok {
+			merged = &model.BasketPoint{Bucket: point.Bucket, Currency: currency}
+			byBucket[point.Bucket] = merged
+			normalized = append(normalized, merged)
+		}
+
+		orders := float64(point.Orders)
+		total := float64(merged.Orders) + orders
+		merged.AvgItems = (merged.AvgItems*float64(merged.Orders) + point.AvgItems*orders) / total
+		merged.AvgAmount = (merged.AvgAmount*float64(merged.Orders) + converted*orders) / total
+		merged.Orders += point.Orders
+	}
+	return normalized, nil
+}
+
+func (s *Service) logError(msg string, q model.AnalyticsQuery, err error) {
+	s.logger.Error(msg,
+		zap.Time("from", q.From),
+		zap.Time("to", q.To),
+		zap.String("bucket", q.Bucket),
+		zap.Error(err))
+}
@@ -0,0 +1,176 @@
+package analytics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/analytics"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) RevenueByCurrency(ctx context.Context, q model.AnalyticsQuery) ([]*model.RevenuePoint, error) {
+	args := m.Called(ctx, q)
+	points, _ := args.Get(0).([]*model.RevenuePoint)
+	return points, args.Error(1)
+}
+
+func (m *MockRepository) OrderCounts(ctx context.Context, groupBy string, q model.AnalyticsQuery) ([]*model.OrderCountPoint, error) {
+	args := m.Called(ctx, groupBy, q)
+	points, _ := args.Get(0).([]*model.OrderCountPoint)
+	return points, args.Error(1)
+}
+
+func (m *MockRepository) TopBrands(ctx context.Context, q model.AnalyticsQuery) ([]*model.BrandStat, error) {
+	args := m.Called(ctx, q)
+	stats, _ := args.Get(0).([]*model.BrandStat)
+	return stats, args.Error(1)
+}
+
+func (m *MockRepository) TopProducts(ctx context.Context, q model.AnalyticsQuery) ([]*model.ProductStat, error) {
+	args := m.Called(ctx, q)
+	stats, _ := args.Get(0).([]*model.ProductStat)
+	return stats, args.Error(1)
+}
+
+func (m *MockRepository) BasketSize(ctx context.Context, q model.AnalyticsQuery) ([]*model.BasketPoint, error) {
+	args := m.Called(ctx, q)
+	points, _ := args.Get(0).([]*model.BasketPoint)
+	return points, args.Error(1)
+}
+
+func TestService_Revenue_AppliesDefaults(t *testing.T) {
+	repo := new(MockRepository)
+	repo.On("RevenueByCurrency", mock.Anything, mock.MatchedBy(func(q model.AnalyticsQuery) bool {
+		return q.Bucket == analytics.DefaultBucket &&
+			q.Limit == analytics.DefaultLimit &&
+			q.To.Sub(q.From) == analytics.DefaultRange
+	})).Return([]*model.RevenuePoint{{Currency: "RUB", Revenue: 100, Orders: 1}}, nil)
+
+	svc := analytics.NewService(repo, &loggertest.MockLogger{})
+
+	points, err := svc.Revenue(context.Background(), model.AnalyticsQuery{})
+
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	repo.AssertExpectations(t)
+}
+
+func TestService_InvalidQueries(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		q    model.AnalyticsQuery
+	}{
+		{"unknown bucket", model.AnalyticsQuery{Bucket: "year"}},
+		{"from after to", model.AnalyticsQuery{From: now, To: now.Add(-time.Hour)}},
+		{"range too wide", model.AnalyticsQuery{From: now.Add(-60 * 24 * time.Hour), To: now, Bucket: model.BucketHour}},
+		{"limit too large", model.AnalyticsQuery{Limit: analytics.MaxLimit + 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			svc := analytics.NewService(repo, &loggertest.MockLogger{})
+
+			_, err := svc.TopBrands(context.Background(), tt.q)
+
+			require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+			repo.AssertNotCalled(t, "TopBrands")
+		})
+	}
+}
+
+func TestService_OrderCounts_UnsupportedGroupBy(t *testing.T) {
+	repo := new(MockRepository)
+	svc := analytics.NewService(repo, &loggertest.MockLogger{})
+
+	_, err := svc.OrderCounts(context.Background(), "customer_id", model.AnalyticsQuery{})
+
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	repo.AssertNotCalled(t, "OrderCounts")
+}
+
+func TestService_BasketSize_RepositoryError(t *testing.T) {
+	repo := new(MockRepository)
+	repo.On("BasketSize", mock.Anything, mock.Anything).Return(nil, srvcerrors.ErrDatabase)
+
+	svc := analytics.NewService(repo, &loggertest.MockLogger{})
+
+	points, err := svc.BasketSize(context.Background(), model.AnalyticsQuery{})
+
+	assert.Nil(t, points)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+}
+
+type fixedConverter map[string]float64
+
+func (c fixedConverter) Convert(amount float64, from, to string) (float64, error) {
+	fromRate, ok := c[from]
+	if !ok {
+		return 0, srvcerrors.ErrInvalidInput
+	}
+	toRate, ok := c[to]
+	if !ok {
+		return 0, srvcerrors.ErrInvalidInput
+	}
+	return amount * fromRate / toRate, nil
+}
+
+func TestService_Revenue_NormalizesCurrency(t *testing.T) {
+	bucket := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := new(MockRepository)
+	repo.On("RevenueByCurrency", mock.Anything, mock.Anything).Return([]*model.RevenuePoint{
+		{Bucket: bucket, Currency: "RUB", Revenue: 9000, Orders: 2},
+		{Bucket: bucket, Currency: "USD", Revenue: 50, Orders: 1},
+	}, nil)
+
+	svc := analytics.NewService(repo, &loggertest.MockLogger{}, analytics.WithConverter(fixedConverter{"RUB": 1, "USD": 90}))
+
+	points, err := svc.Revenue(context.Background(), model.AnalyticsQuery{Currency: "usd"})
+
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.Equal(t, "USD", points[0].Currency)
+	assert.EqualValues(t, 150, points[0].Revenue)
+	assert.EqualValues(t, 3, points[0].Orders)
+}
+
+func TestService_BasketSize_NormalizesCurrency(t *testing.T) {
+	bucket := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := new(MockRepository)
+	repo.On("BasketSize", mock.Anything, mock.Anything).Return([]*model.BasketPoint{
+		{Bucket: bucket, Currency: "RUB", Orders: 1, AvgItems: 1, AvgAmount: 900},
+		{Bucket: bucket, Currency: "USD", Orders: 3, AvgItems: 3, AvgAmount: 30},
+	}, nil)
+
+	svc := analytics.NewService(repo, &loggertest.MockLogger{}, analytics.WithConverter(fixedConverter{"RUB": 1, "USD": 90}))
+
+	points, err := svc.BasketSize(context.Background(), model.AnalyticsQuery{Currency: "RUB"})
+
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.EqualValues(t, 4, points[0].Orders)
+	assert.InDelta(t, 2.5, points[0].AvgItems, 0.001)
+	assert.InDelta(t, 2250, points[0].AvgAmount, 0.001)
+}
+
+func TestService_Currency_WithoutConverter(t *testing.T) {
+	repo := new(MockRepository)
+	svc := analytics.NewService(repo, &loggertest.MockLogger{})
+
+	_, err := svc.Revenue(context.Background(), model.AnalyticsQuery{Currency: "USD"})
+
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	repo.AssertNotCalled(t, "RevenueByCurrency")
+}
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"time"
+
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 )
 
@@ -8,5 +10,23 @@ type Cache interface {
 	GetOrderByUID(string) (*model.Order, error)
 	GetItemsByOrderUID(string, int, int) ([]*model.Item, error)
 	SetOrder(*model.Order)
+	SetOrderIfAbsent(*model.Order) bool
+	Delete(string) bool
 	Clear()
+	GetEvicted(string) (*model.Order, bool)
+	GetEvictedItems(string, int, int) ([]*model.Item, bool)
+	Stats() Stats
+}
+
+type Snapshotter interface {
+	SaveSnapshot(string, int64, time.Time) (SnapshotInfo, error)
+	LoadSnapshot(string, int64) (SnapshotInfo, error)
+}
+
+type Stats struct {
+	Orders    int    `json:"orders"`
+	Items     int    `json:"items"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
 }
\ No newline at end of file
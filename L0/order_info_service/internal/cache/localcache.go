@@ -1,23 +1,65 @@
 package cache
 
 import (
+	"container/list"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
 )
 
+const (
+	DefaultEvictedCapacity = 1000
+	DefaultEvictedTTL      = 10 * time.Minute
+)
+
 type LocalCache struct {
-	orders map[string]*model.Order
-	mu sync.RWMutex
+	orders    map[string]*model.Order
+	mu        sync.RWMutex
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+
+	evicted         map[string]*list.Element
+	evictedOrder    *list.List
+	evictedCapacity int
+	evictedTTL      time.Duration
+	now             func() time.Time
+}
+
+type evictedEntry struct {
+	order     *model.Order
+	evictedAt time.Time
 }
 
-func NewLocalCache() *LocalCache{
-	return &LocalCache{
-		orders: make(map[string]*model.Order),
+type Option func(*LocalCache)
+
+// WithEvictedRetention keeps up to capacity evicted orders for ttl so they can
+// still be served as stale data while the database is unavailable.
+func WithEvictedRetention(capacity int, ttl time.Duration) Option {
+	return func(l *LocalCache) {
+		l.evictedCapacity = capacity
+		l.evictedTTL = ttl
 	}
 }
+
+func NewLocalCache(opts ...Option) *LocalCache{
+	l := &LocalCache{
+		orders:          make(map[string]*model.Order),
+		evicted:         make(map[string]*list.Element),
+		evictedOrder:    list.New(),
+		evictedCapacity: DefaultEvictedCapacity,
+		evictedTTL:      DefaultEvictedTTL,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
  
 func (l *LocalCache) GetOrderByUID(orderID string) (*model.Order, error){
 	l.mu.Lock()
@@ -25,8 +67,10 @@ func (l *LocalCache) GetOrderByUID(orderID string) (*model.Order, error){
 	
 	order, ok := l.orders[orderID]
 	if !ok {
+		l.misses.Add(1)
 		return nil, fmt.Errorf("%w: order %s not found in cache", srvcerrors.ErrNotFound, orderID)
 	}
+	l.hits.Add(1)
 	
 	orderCopy := *order
     orderCopy.Items = nil
@@ -39,17 +83,24 @@ func (l *LocalCache) GetItemsByOrderUID(orderID string, lastID, limit int) ([]*m
 	
 	order, ok := l.orders[orderID]
 	if !ok {
+		l.misses.Add(1)
 		return nil, fmt.Errorf("%w: failed to get items of order %s: order not found in cache", srvcerrors.ErrNotFound, orderID)
 	}
+	l.hits.Add(1)
 	
-	if len(order.Items) == 0 {
-		return []*model.Item{}, nil
+	return PageItems(order.Items, lastID, limit), nil
+}
+
+// PageItems returns up to limit items with ID greater than lastID.
+func PageItems(items []*model.Item, lastID, limit int) []*model.Item {
+	if len(items) == 0 {
+		return []*model.Item{}
 	}
 	
 	startIndex := 0
 	if lastID > 0 {
 		found := false
-		for i, item := range order.Items {
+		for i, item := range items {
 			if item.ID > lastID {
 				startIndex = i
 				found = true
@@ -57,14 +108,14 @@ func (l *LocalCache) GetItemsByOrderUID(orderID string, lastID, limit int) ([]*m
 			}
 		}
 		if !found {
-			return []*model.Item{}, nil
+			return []*model.Item{}
 		}
 	}
 	
 	endIndex := startIndex + limit
-	endIndex = min(endIndex, len(order.Items))
+	endIndex = min(endIndex, len(items))
 	
-	return order.Items[startIndex:endIndex], nil
+	return items[startIndex:endIndex]
 }
 
 func (l *LocalCache) SetOrder(order *model.Order) {
@@ -72,11 +123,132 @@ func (l *LocalCache) SetOrder(order *model.Order) {
 	defer l.mu.Unlock()
 	
 	l.orders[order.OrderUID] = order
+	l.forgetEvicted(order.OrderUID)
+}
+
+func (l *LocalCache) SetOrderIfAbsent(order *model.Order) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.orders[order.OrderUID]; ok {
+		return false
+	}
+	l.orders[order.OrderUID] = order
+	l.forgetEvicted(order.OrderUID)
+	return true
+}
+
+func (l *LocalCache) Delete(orderID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	order, ok := l.orders[orderID]
+	if !ok {
+		return false
+	}
+	delete(l.orders, orderID)
+	l.evictions.Add(1)
+	l.rememberEvicted(order)
+	return true
 }
 
 func (l *LocalCache) Clear(){
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	
+	l.evictions.Add(uint64(len(l.orders)))
+	for _, order := range l.orders {
+		l.rememberEvicted(order)
+	}
 	l.orders = make(map[string]*model.Order)
+}
+
+// SetEvictedRetention changes the evicted order retention at runtime,
+// dropping the oldest entries above the new capacity.
+func (l *LocalCache) SetEvictedRetention(capacity int, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.evictedCapacity = capacity
+	l.evictedTTL = ttl
+	for l.evictedOrder.Len() > 0 && l.evictedOrder.Len() > capacity {
+		l.forgetEvicted(l.evictedOrder.Front().Value.(*evictedEntry).order.OrderUID)
+	}
+}
+
+// GetEvicted returns a copy of an order that was recently evicted or cleared
+// from the cache, without its items. The order may be outdated.
+func (l *LocalCache) GetEvicted(orderID string) (*model.Order, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	order, ok := l.lookupEvicted(orderID)
+	if !ok {
+		return nil, false
+	}
+	orderCopy := *order
+	orderCopy.Items = nil
+	return &orderCopy, true
+}
+
+// GetEvictedItems returns a page of the items of a recently evicted order.
+func (l *LocalCache) GetEvictedItems(orderID string, lastID, limit int) ([]*model.Item, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	order, ok := l.lookupEvicted(orderID)
+	if !ok {
+		return nil, false
+	}
+	return PageItems(order.Items, lastID, limit), true
+}
+
+func (l *LocalCache) lookupEvicted(orderID string) (*model.Order, bool) {
+	elem, ok := l.evicted[orderID]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*evictedEntry)
+	if l.now().Sub(entry.evictedAt) > l.evictedTTL {
+		l.forgetEvicted(orderID)
+		return nil, false
+	}
+	return entry.order, true
+}
+
+func (l *LocalCache) rememberEvicted(order *model.Order) {
+	if l.evictedCapacity <= 0 {
+		return
+	}
+
+	l.forgetEvicted(order.OrderUID)
+	l.evicted[order.OrderUID] = l.evictedOrder.PushBack(&evictedEntry{order: order, evictedAt: l.now()})
+	for l.evictedOrder.Len() > l.evictedCapacity {
+		oldest := l.evictedOrder.Front()
+		l.evictedOrder.Remove(oldest)
+		delete(l.evicted, oldest.Value.(*evictedEntry).order.OrderUID)
+	}
+}
+
+func (l *LocalCache) forgetEvicted(orderID string) {
+	if elem, ok := l.evicted[orderID]; ok {
+		l.evictedOrder.Remove(elem)
+		delete(l.evicted, orderID)
+	}
+}
+
+func (l *LocalCache) Stats() Stats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	stats := Stats{
+		Orders:    len(l.orders),
+		Hits:      l.hits.Load(),
+		Misses:    l.misses.Load(),
+		Evictions: l.evictions.Load(),
+	}
+	for _, order := range l.orders {
+		stats.Items += len(order.Items)
+	}
+	return stats
 }
\ No newline at end of file
@@ -1,7 +1,11 @@
 package cache
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
@@ -89,6 +93,238 @@ func TestClear(t *testing.T) {
 	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
 }
 
+func TestSetOrderIfAbsent(t *testing.T) {
+	cache := NewLocalCache()
+	fresh := generateTestOrder("order-1")
+	fresh.TrackNumber = "fresh"
+	stale := generateTestOrder("order-1")
+	stale.TrackNumber = "stale"
+
+	require.True(t, cache.SetOrderIfAbsent(fresh))
+	require.False(t, cache.SetOrderIfAbsent(stale))
+
+	got, err := cache.GetOrderByUID("order-1")
+	require.NoError(t, err)
+	require.Equal(t, "fresh", got.TrackNumber)
+}
+
+func TestDelete(t *testing.T) {
+	cache := NewLocalCache()
+	cache.SetOrder(generateTestOrder("order-1"))
+	cache.SetOrder(generateTestOrder("order-2"))
+
+	require.True(t, cache.Delete("order-1"))
+	require.False(t, cache.Delete("order-1"))
+
+	_, err := cache.GetOrderByUID("order-1")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+	_, err = cache.GetOrderByUID("order-2")
+	require.NoError(t, err)
+}
+
+func TestStats(t *testing.T) {
+	cache := NewLocalCache()
+	cache.SetOrder(generateTestOrder("order-1"))
+	cache.SetOrder(generateTestOrder("order-2"))
+
+	_, _ = cache.GetOrderByUID("order-1")
+	_, _ = cache.GetItemsByOrderUID("order-2", 0, 1)
+	_, _ = cache.GetOrderByUID("nonexistent")
+	cache.Delete("order-1")
+
+	require.Equal(t, Stats{Orders: 1, Items: 2, Hits: 2, Misses: 1, Evictions: 1}, cache.Stats())
+
+	cache.Clear()
+
+	require.Equal(t, Stats{Hits: 2, Misses: 1, Evictions: 2}, cache.Stats())
+}
+
+func TestGetEvicted(t *testing.T) {
+	t.Run("delete and clear", func(t *testing.T) {
+		cache := NewLocalCache()
+		cache.SetOrder(generateTestOrder("order-1"))
+		cache.SetOrder(generateTestOrder("order-2"))
+
+		require.True(t, cache.Delete("order-1"))
+		evicted, ok := cache.GetEvicted("order-1")
+		require.True(t, ok)
+		require.Nil(t, evicted.Items)
+		evicted.TrackNumber = "changed"
+		again, _ := cache.GetEvicted("order-1")
+		require.NotEqual(t, "changed", again.TrackNumber)
+
+		items, ok := cache.GetEvictedItems("order-1", 0, 10)
+		require.True(t, ok)
+		require.Len(t, items, 2)
+		_, ok = cache.GetEvictedItems("order-2", 0, 10)
+		require.False(t, ok)
+
+		_, ok = cache.GetEvicted("order-2")
+		require.False(t, ok)
+		cache.Clear()
+		_, ok = cache.GetEvicted("order-2")
+		require.True(t, ok)
+
+		cache.SetOrder(generateTestOrder("order-1"))
+		_, ok = cache.GetEvicted("order-1")
+		require.False(t, ok)
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		cache := NewLocalCache(WithEvictedRetention(2, time.Minute))
+		for _, uid := range []string{"order-1", "order-2", "order-3"} {
+			cache.SetOrder(generateTestOrder(uid))
+			cache.Delete(uid)
+		}
+
+		_, ok := cache.GetEvicted("order-1")
+		require.False(t, ok)
+		_, ok = cache.GetEvicted("order-3")
+		require.True(t, ok)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		cache := NewLocalCache(WithEvictedRetention(10, time.Minute))
+		cache.now = func() time.Time { return now }
+		cache.SetOrder(generateTestOrder("order-1"))
+		cache.Delete("order-1")
+
+		now = now.Add(time.Minute)
+		_, ok := cache.GetEvicted("order-1")
+		require.True(t, ok)
+
+		now = now.Add(time.Second)
+		_, ok = cache.GetEvicted("order-1")
+		require.False(t, ok)
+	})
+
+	t.Run("resize", func(t *testing.T) {
+		cache := NewLocalCache(WithEvictedRetention(10, time.Minute))
+		for _, uid := range []string{"order-1", "order-2", "order-3"} {
+			cache.SetOrder(generateTestOrder(uid))
+			cache.Delete(uid)
+		}
+
+		cache.SetEvictedRetention(1, time.Minute)
+		_, ok := cache.GetEvicted("order-2")
+		require.False(t, ok)
+		_, ok = cache.GetEvicted("order-3")
+		require.True(t, ok)
+
+		cache.SetEvictedRetention(0, time.Minute)
+		_, ok = cache.GetEvicted("order-3")
+		require.False(t, ok)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cache := NewLocalCache(WithEvictedRetention(0, time.Minute))
+		cache.SetOrder(generateTestOrder("order-1"))
+		cache.Delete("order-1")
+		_, ok := cache.GetEvicted("order-1")
+		require.False(t, ok)
+	})
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots", "cache.snapshot")
+	watermark := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	source := NewLocalCache()
+	source.SetOrder(generateTestOrder("order-1"))
+	source.SetOrder(generateTestOrder("order-2"))
+
+	saved, err := source.SaveSnapshot(path, 1<<20, watermark)
+	require.NoError(t, err)
+	require.Equal(t, 2, saved.Orders)
+
+	target := NewLocalCache()
+	kept := generateTestOrder("order-1")
+	kept.TrackNumber = "kept"
+	target.SetOrder(kept)
+
+	loaded, err := target.LoadSnapshot(path, 1<<20)
+	require.NoError(t, err)
+	require.Equal(t, uint16(SnapshotVersion), loaded.Version)
+	require.True(t, watermark.Equal(loaded.Watermark))
+	require.Equal(t, saved.Size, loaded.Size)
+	require.Equal(t, []string{"order-2"}, loaded.OrderUIDs)
+
+	got, err := target.GetOrderByUID("order-1")
+	require.NoError(t, err)
+	require.Equal(t, "kept", got.TrackNumber)
+
+	items, err := target.GetItemsByOrderUID("order-2", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+}
+
+func TestSnapshotRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	source := NewLocalCache()
+	source.SetOrder(generateTestOrder("order-1"))
+	saved, err := source.SaveSnapshot(path, 0, time.Time{})
+	require.NoError(t, err)
+
+	t.Run("too large to save", func(t *testing.T) {
+		_, err := source.SaveSnapshot(filepath.Join(t.TempDir(), "cache.snapshot"), 16, time.Time{})
+		require.ErrorIs(t, err, ErrSnapshotTooLarge)
+	})
+
+	t.Run("too large to load", func(t *testing.T) {
+		_, err := NewLocalCache().LoadSnapshot(path, saved.Size-1)
+		require.ErrorIs(t, err, ErrSnapshotTooLarge)
+	})
+
+	t.Run("inflates past the limit", func(t *testing.T) {
+		big := NewLocalCache()
+		order := generateTestOrder("order-1")
+		order.TrackNumber = strings.Repeat("a", 1<<20)
+		big.SetOrder(order)
+		inflating := filepath.Join(t.TempDir(), "cache.snapshot")
+		saved, err := big.SaveSnapshot(inflating, 0, time.Time{})
+		require.NoError(t, err)
+		require.Less(t, saved.Size, int64(64<<10))
+
+		target := NewLocalCache()
+		_, err = target.LoadSnapshot(inflating, 64<<10)
+		require.ErrorIs(t, err, ErrSnapshotTooLarge)
+		require.Equal(t, 0, target.Stats().Orders)
+
+		_, err = big.SaveSnapshot(filepath.Join(t.TempDir(), "cache.snapshot"), 64<<10, time.Time{})
+		require.ErrorIs(t, err, ErrSnapshotTooLarge)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[len(data)-1] ^= 0xff
+		corrupted := filepath.Join(t.TempDir(), "cache.snapshot")
+		require.NoError(t, os.WriteFile(corrupted, data, 0o644))
+
+		target := NewLocalCache()
+		_, err = target.LoadSnapshot(corrupted, 0)
+		require.ErrorIs(t, err, ErrSnapshotCorrupt)
+		require.Equal(t, 0, target.Stats().Orders)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[5] = SnapshotVersion + 1
+		other := filepath.Join(t.TempDir(), "cache.snapshot")
+		require.NoError(t, os.WriteFile(other, data, 0o644))
+
+		_, err = NewLocalCache().LoadSnapshot(other, 0)
+		require.ErrorIs(t, err, ErrSnapshotVersion)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewLocalCache().LoadSnapshot(filepath.Join(t.TempDir(), "missing"), 0)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
 func generateTestOrder(uid string) *model.Order {
 	return &model.Order{
 		OrderUID:    uid,
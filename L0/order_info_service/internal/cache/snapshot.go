@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+)
+
+const SnapshotVersion = 1
+
+var snapshotMagic = [4]byte{'O', 'C', 'S', 'N'}
+
+var (
+	ErrSnapshotVersion  = fmt.Errorf("unsupported cache snapshot version")
+	ErrSnapshotCorrupt  = fmt.Errorf("cache snapshot is corrupted")
+	ErrSnapshotTooLarge = fmt.Errorf("cache snapshot exceeds size limit")
+)
+
+type SnapshotInfo struct {
+	Version   uint16
+	SavedAt   time.Time
+	Watermark time.Time
+	Orders    int
+	Size      int64
+	OrderUIDs []string
+}
+
+type snapshotHeader struct {
+	Magic     [4]byte
+	Version   uint16
+	_         uint16
+	SavedAt   int64
+	Watermark int64
+	Length    uint64
+	Checksum  uint32
+}
+
+var snapshotHeaderSize = int64(binary.Size(snapshotHeader{}))
+
+// SaveSnapshot writes every cached order to a gzip-compressed file guarded by
+// a versioned header and a CRC32 of the payload. Watermark is the last upsert
+// time the cache is known to reflect. maxSize bounds both the file and the
+// uncompressed orders, so LoadSnapshot never inflates more than it.
+func (l *LocalCache) SaveSnapshot(path string, maxSize int64, watermark time.Time) (SnapshotInfo, error) {
+	l.mu.RLock()
+	orders := make([]*model.Order, 0, len(l.orders))
+	for _, order := range l.orders {
+		orders = append(orders, order)
+	}
+	data, err := json.Marshal(orders)
+	l.mu.RUnlock()
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("encode cache snapshot: %w", err)
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return SnapshotInfo{}, fmt.Errorf("%w: %d bytes uncompressed, limit %d", ErrSnapshotTooLarge, len(data), maxSize)
+	}
+
+	var payload bytes.Buffer
+	zw := gzip.NewWriter(&payload)
+	if _, err := zw.Write(data); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("compress cache snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("compress cache snapshot: %w", err)
+	}
+
+	size := snapshotHeaderSize + int64(payload.Len())
+	if maxSize > 0 && size > maxSize {
+		return SnapshotInfo{}, fmt.Errorf("%w: %d bytes, limit %d", ErrSnapshotTooLarge, size, maxSize)
+	}
+
+	savedAt := time.Now().UTC()
+	header := snapshotHeader{
+		Magic:     snapshotMagic,
+		Version:   SnapshotVersion,
+		SavedAt:   savedAt.UnixNano(),
+		Watermark: unixNano(watermark),
+		Length:    uint64(payload.Len()),
+		Checksum:  crc32.ChecksumIEEE(payload.Bytes()),
+	}
+
+	if err := writeSnapshotFile(path, header, payload.Bytes()); err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	return SnapshotInfo{
+		Version:   SnapshotVersion,
+		SavedAt:   savedAt,
+		Watermark: watermark,
+		Orders:    len(orders),
+		Size:      size,
+	}, nil
+}
+
+// LoadSnapshot restores orders from a file written by SaveSnapshot. Orders that
+// are already cached are kept as is.
+func (l *LocalCache) LoadSnapshot(path string, maxSize int64) (SnapshotInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("open cache snapshot: %w", err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("stat cache snapshot: %w", err)
+	}
+	if maxSize > 0 && stat.Size() > maxSize {
+		return SnapshotInfo{}, fmt.Errorf("%w: %d bytes, limit %d", ErrSnapshotTooLarge, stat.Size(), maxSize)
+	}
+
+	var header snapshotHeader
+	if err := binary.Read(f, binary.BigEndian, &header); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("%w: read header: %v", ErrSnapshotCorrupt, err)
+	}
+	if header.Magic != snapshotMagic {
+		return SnapshotInfo{}, fmt.Errorf("%w: bad magic", ErrSnapshotCorrupt)
+	}
+	if header.Version != SnapshotVersion {
+		return SnapshotInfo{}, fmt.Errorf("%w: %d", ErrSnapshotVersion, header.Version)
+	}
+	if int64(header.Length) != stat.Size()-snapshotHeaderSize {
+		return SnapshotInfo{}, fmt.Errorf("%w: payload length mismatch", ErrSnapshotCorrupt)
+	}
+
+	payload := make([]byte, header.Length)
+	if _, err := io.ReadFull(f, payload); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("%w: read payload: %v", ErrSnapshotCorrupt, err)
+	}
+	if crc32.ChecksumIEEE(payload) != header.Checksum {
+		return SnapshotInfo{}, fmt.Errorf("%w: checksum mismatch", ErrSnapshotCorrupt)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+	defer zr.Close()
+
+	// A small file can still inflate to far more than maxSize, so the
+	// decompressed stream is bounded as well.
+	var orders []*model.Order
+	var r io.Reader = zr
+	limited := &io.LimitedReader{R: zr, N: maxSize + 1}
+	if maxSize > 0 {
+		r = limited
+	}
+	if err := json.NewDecoder(r).Decode(&orders); err != nil {
+		if maxSize > 0 && limited.N <= 0 {
+			return SnapshotInfo{}, fmt.Errorf("%w: more than %d bytes uncompressed", ErrSnapshotTooLarge, maxSize)
+		}
+		return SnapshotInfo{}, fmt.Errorf("%w: decode orders: %v", ErrSnapshotCorrupt, err)
+	}
+
+	info := SnapshotInfo{
+		Version:   header.Version,
+		SavedAt:   time.Unix(0, header.SavedAt).UTC(),
+		Watermark: fromUnixNano(header.Watermark),
+		Size:      stat.Size(),
+		OrderUIDs: make([]string, 0, len(orders)),
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, order := range orders {
+		if order == nil || order.OrderUID == "" {
+			continue
+		}
+		if _, ok := l.orders[order.OrderUID]; ok {
+			continue
+		}
+		l.orders[order.OrderUID] = order
+		l.forgetEvicted(order.OrderUID)
+		info.OrderUIDs = append(info.OrderUIDs, order.OrderUID)
+	}
+	info.Orders = len(info.OrderUIDs)
+
+	return info, nil
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
+
+func writeSnapshotFile(path string, header snapshotHeader, payload []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create cache snapshot directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create cache snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := binary.Write(tmp, binary.BigEndian, header); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache snapshot header: %w", err)
+	}
+	if _, err := tmp.Write(payload); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache snapshot payload: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync cache snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close cache snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace cache snapshot: %w", err)
+	}
+	return nil
+}
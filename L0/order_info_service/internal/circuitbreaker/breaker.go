@@ -0,0 +1,158 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+)
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 10 * time.Second
+	DefaultHalfOpenRequests = 1
+)
+
+var ErrOpen = fmt.Errorf("%w: circuit breaker is open", srvcerrors.ErrUnavailable)
+
+type Config struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenRequests int
+}
+
+// Breaker opens after FailureThreshold consecutive database failures and
+// rejects calls with ErrOpen for OpenTimeout. After that it lets through up to
+// HalfOpenRequests probes: a successful probe closes it, a failed one opens it
+// again.
+type Breaker struct {
+	cfg      Config
+	logger   logger.Logger
+	now      func() time.Time
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+func New(cfg Config, logger logger.Logger) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultFailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = DefaultOpenTimeout
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = DefaultHalfOpenRequests
+	}
+	return &Breaker{
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+		state:  StateClosed,
+	}
+}
+
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Tripped reports whether the breaker is not closed, i.e. the database is
+// considered unavailable or is being probed.
+func (b *Breaker) Tripped() bool {
+	return b.State() != StateClosed
+}
+
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.setState(StateHalfOpen)
+		b.probes = 0
+	}
+
+	switch b.state {
+	case StateOpen:
+		return ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.cfg.HalfOpenRequests {
+			return ErrOpen
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call. Database errors and calls
+// that ran out of time count as failures; not found, invalid input and calls
+// cancelled by the caller do not. A cancelled probe frees its slot, so the
+// half-open breaker can let another one through.
+func (b *Breaker) Record(ctx context.Context, err error) {
+	cancelled := err != nil && errors.Is(ctx.Err(), context.Canceled)
+	failed := errors.Is(err, srvcerrors.ErrDatabase) || errors.Is(err, context.DeadlineExceeded)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if cancelled {
+		if b.state == StateHalfOpen && b.probes > 0 {
+			b.probes--
+		}
+		return
+	}
+
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.open(err)
+		}
+	case StateHalfOpen:
+		if failed {
+			b.open(err)
+			return
+		}
+		b.failures = 0
+		b.setState(StateClosed)
+	}
+}
+
+func (b *Breaker) open(err error) {
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+	b.logger.Error("circuit breaker: opened",
+		zap.Int("failures", b.failures),
+		zap.Duration("open_timeout", b.cfg.OpenTimeout),
+		zap.Error(err))
+}
+
+func (b *Breaker) setState(state string) {
+	if b.state == state {
+		return
+	}
+	if state != StateOpen {
+		b.logger.Info("circuit breaker: state changed",
+			zap.String("from", b.state),
+			zap.String("to", state))
+	}
+	b.state = state
+}
@@ -0,0 +1,160 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBreaker(cfg Config) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	b := New(cfg, &loggertest.MockLogger{})
+	b.now = clock.Now
+	return b, clock
+}
+
+func fail(t *testing.T, b *Breaker, times int) {
+	t.Helper()
+	for i := 0; i < times; i++ {
+		require.NoError(t, b.Allow())
+		b.Record(context.Background(), srvcerrors.ErrDatabase)
+	}
+}
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 3, OpenTimeout: time.Minute})
+
+	fail(t, b, 2)
+	require.NoError(t, b.Allow())
+	b.Record(context.Background(), nil)
+	fail(t, b, 2)
+	require.Equal(t, StateClosed, b.State())
+
+	fail(t, b, 1)
+	require.Equal(t, StateOpen, b.State())
+	require.True(t, b.Tripped())
+
+	err := b.Allow()
+	require.ErrorIs(t, err, ErrOpen)
+	require.ErrorIs(t, err, srvcerrors.ErrUnavailable)
+}
+
+func TestBreaker_IgnoresNonDatabaseErrors(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 1})
+
+	for _, err := range []error{srvcerrors.ErrNotFound, srvcerrors.ErrInvalidInput, errors.New("other")} {
+		require.NoError(t, b.Allow())
+		b.Record(context.Background(), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, b.Allow())
+	b.Record(ctx, srvcerrors.ErrDatabase)
+
+	require.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_CountsDeadlineExceeded(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 2})
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	require.NoError(t, b.Allow())
+	b.Record(ctx, fmt.Errorf("%w: query timed out", srvcerrors.ErrDatabase))
+	require.NoError(t, b.Allow())
+	b.Record(ctx, context.DeadlineExceeded)
+
+	require.Equal(t, StateOpen, b.State())
+}
+
+func TestBreaker_CancelledProbeReleasesSlot(t *testing.T) {
+	b, clock := newTestBreaker(Config{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	fail(t, b, 1)
+	clock.now = clock.now.Add(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, b.Allow())
+	b.Record(ctx, srvcerrors.ErrDatabase)
+	require.Equal(t, StateHalfOpen, b.State())
+
+	require.NoError(t, b.Allow())
+	b.Record(context.Background(), nil)
+	require.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	b, clock := newTestBreaker(Config{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	fail(t, b, 1)
+
+	clock.now = clock.now.Add(30 * time.Second)
+	require.ErrorIs(t, b.Allow(), ErrOpen)
+
+	clock.now = clock.now.Add(30 * time.Second)
+	require.NoError(t, b.Allow())
+	require.Equal(t, StateHalfOpen, b.State())
+	require.ErrorIs(t, b.Allow(), ErrOpen)
+
+	t.Run("failed probe reopens", func(t *testing.T) {
+		b.Record(context.Background(), srvcerrors.ErrDatabase)
+		require.Equal(t, StateOpen, b.State())
+		require.ErrorIs(t, b.Allow(), ErrOpen)
+	})
+
+	t.Run("successful probe closes", func(t *testing.T) {
+		clock.now = clock.now.Add(time.Minute)
+		require.NoError(t, b.Allow())
+		b.Record(context.Background(), srvcerrors.ErrNotFound)
+		require.Equal(t, StateClosed, b.State())
+		require.False(t, b.Tripped())
+		require.NoError(t, b.Allow())
+	})
+}
+
+type fakeRepository struct {
+	repository.RepositoryProvider
+	err   error
+	calls int
+}
+
+func (f *fakeRepository) GetOrderByUID(context.Context, string) (*model.Order, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &model.Order{OrderUID: "order-1"}, nil
+}
+
+func TestRepository_FailsFastWhenOpen(t *testing.T) {
+	repo := &fakeRepository{err: srvcerrors.ErrDatabase}
+	b, _ := newTestBreaker(Config{FailureThreshold: 2, OpenTimeout: time.Minute})
+	guarded := NewRepository(repo, b)
+
+	for i := 0; i < 2; i++ {
+		_, err := guarded.GetOrderByUID(context.Background(), "order-1")
+		require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	}
+
+	_, err := guarded.GetOrderByUID(context.Background(), "order-1")
+	require.ErrorIs(t, err, srvcerrors.ErrUnavailable)
+	require.Equal(t, 2, repo.calls)
+	require.Same(t, b, guarded.Breaker())
+}
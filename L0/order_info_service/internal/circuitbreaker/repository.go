@@ -0,0 +1,83 @@
+package circuitbreaker
+
+import (
+	"context"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+)
+
+// Repository guards every RepositoryProvider call with a Breaker.
+type Repository struct {
+	repo    repository.RepositoryProvider
+	breaker *Breaker
+}
+
+func NewRepository(repo repository.RepositoryProvider, breaker *Breaker) *Repository {
+	return &Repository{repo: repo, breaker: breaker}
+}
+
+func (r *Repository) Breaker() *Breaker {
+	return r.breaker
+}
+
+func (r *Repository) UpsertOrder(ctx context.Context, order *model.Order) (*model.Order, bool, error) {
+	var created bool
+	stored, err := call(ctx, r.breaker, func() (stored *model.Order, err error) {
+		stored, created, err = r.repo.UpsertOrder(ctx, order)
+		return stored, err
+	})
+	return stored, created, err
+}
+
+func (r *Repository) GetOrderByUID(ctx context.Context, orderUID string) (*model.Order, error) {
+	return call(ctx, r.breaker, func() (*model.Order, error) {
+		return r.repo.GetOrderByUID(ctx, orderUID)
+	})
+}
+
+func (r *Repository) OrderExists(ctx context.Context, orderUID string) (bool, error) {
+	return call(ctx, r.breaker, func() (bool, error) {
+		return r.repo.OrderExists(ctx, orderUID)
+	})
+}
+
+func (r *Repository) GetAllOrders(ctx context.Context, limit int) ([]*model.Order, error) {
+	return call(ctx, r.breaker, func() ([]*model.Order, error) {
+		return r.repo.GetAllOrders(ctx, limit)
+	})
+}
+
+func (r *Repository) GetRecentOrders(ctx context.Context, before *model.OrderCursor, limit int) ([]*model.Order, error) {
+	return call(ctx, r.breaker, func() ([]*model.Order, error) {
+		return r.repo.GetRecentOrders(ctx, before, limit)
+	})
+}
+
+func (r *Repository) GetOrdersByUIDs(ctx context.Context, orderUIDs []string) (map[string]*model.Order, error) {
+	return call(ctx, r.breaker, func() (map[string]*model.Order, error) {
+		return r.repo.GetOrdersByUIDs(ctx, orderUIDs)
+	})
+}
+
+func (r *Repository) GetItemsByOrderUID(ctx context.Context, orderUID string, lastID, limit int) ([]*model.Item, error) {
+	return call(ctx, r.breaker, func() ([]*model.Item, error) {
+		return r.repo.GetItemsByOrderUID(ctx, orderUID, lastID, limit)
+	})
+}
+
+func (r *Repository) GetItemsByOrderUIDs(ctx context.Context, orderUIDs []string, lastID, limit int) (map[string][]*model.Item, error) {
+	return call(ctx, r.breaker, func() (map[string][]*model.Item, error) {
+		return r.repo.GetItemsByOrderUIDs(ctx, orderUIDs, lastID, limit)
+	})
+}
+
+func call[T any](ctx context.Context, breaker *Breaker, fn func() (T, error)) (T, error) {
+	if err := breaker.Allow(); err != nil {
+		var zero T
+		return zero, err
+	}
+	result, err := fn()
+	breaker.Record(ctx, err)
+	return result, err
+}
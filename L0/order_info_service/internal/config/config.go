@@ -0,0 +1,259 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/invalidation"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/outbox"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/rules"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
+)
+
+// Config holds every setting of the service. Values come from Default, then
+// the config file, then environment variables, each overriding the previous.
+type Config struct {
+	InstanceID string `yaml:"instance_id" toml:"instance_id" env:"INSTANCE_ID"`
+	Storage    string `yaml:"storage" toml:"storage" env:"STORAGE"`
+
+	Log      Log      `yaml:"log" toml:"log"`
+	DB       DB       `yaml:"db" toml:"db"`
+	Breaker  Breaker  `yaml:"breaker" toml:"breaker"`
+	Kafka    Kafka    `yaml:"kafka" toml:"kafka"`
+	HTTP     HTTP     `yaml:"http" toml:"http"`
+	GRPC     GRPC     `yaml:"grpc" toml:"grpc"`
+	Events   Events   `yaml:"events" toml:"events"`
+	Warmup   Warmup   `yaml:"warmup" toml:"warmup"`
+	Cache    Cache    `yaml:"cache" toml:"cache"`
+	Tracing  Tracing  `yaml:"tracing" toml:"tracing"`
+	Webhook  Webhook  `yaml:"webhook" toml:"webhook"`
+	Exchange Exchange `yaml:"exchange" toml:"exchange"`
+	Outbox   Outbox   `yaml:"outbox" toml:"outbox"`
+	Rules    Rules    `yaml:"rules" toml:"rules"`
+}
+
+// Storage backends of orders. The memory storage needs no database and keeps
+// nothing across restarts; features built on Postgres are disabled with it.
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+type Log struct {
+	Level          string   `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	Format         string   `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+	RedactKeys     []string `yaml:"redact_keys" toml:"redact_keys" env:"LOG_REDACT_KEYS" envSeparator:","`
+	RedactPatterns []string `yaml:"redact_patterns" toml:"redact_patterns" env:"LOG_REDACT_PATTERNS" envSeparator:";"`
+}
+
+type DB struct {
+	Host     string `yaml:"host" toml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" toml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" toml:"user" env:"DB_USER"`
+	Password string `yaml:"password" toml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" toml:"name" env:"DB_NAME"`
+
+	ReplicaHosts         []string      `yaml:"replica_hosts" toml:"replica_hosts" env:"DB_REPLICA_HOSTS" envSeparator:","`
+	ReplicaMaxLag        time.Duration `yaml:"replica_max_lag" toml:"replica_max_lag" env:"DB_REPLICA_MAX_LAG"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" toml:"replica_check_interval" env:"DB_REPLICA_CHECK_INTERVAL"`
+}
+
+type Breaker struct {
+	FailureThreshold int           `yaml:"failure_threshold" toml:"failure_threshold" env:"DB_BREAKER_FAILURE_THRESHOLD"`
+	OpenTimeout      time.Duration `yaml:"open_timeout" toml:"open_timeout" env:"DB_BREAKER_OPEN_TIMEOUT"`
+	HalfOpenRequests int           `yaml:"half_open_requests" toml:"half_open_requests" env:"DB_BREAKER_HALF_OPEN_REQUESTS"`
+}
+
+type Kafka struct {
+	BootstrapServers  string        `yaml:"bootstrap_servers" toml:"bootstrap_servers" env:"KAFKA_BOOTSTRAP_SERVERS"`
+	GroupID           string        `yaml:"group_id" toml:"group_id" env:"KAFKA_GROUP_ID"`
+	Topic             string        `yaml:"topic" toml:"topic" env:"KAFKA_TOPIC"`
+	AutoOffset        string        `yaml:"auto_offset" toml:"auto_offset" env:"KAFKA_AUTO_OFFSET"`
+	SessionTimeout    time.Duration `yaml:"session_timeout" toml:"session_timeout" env:"KAFKA_SESSION_TIMEOUT"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" toml:"heartbeat_interval" env:"KAFKA_HEARTBEAT_INTERVAL"`
+	MaxPollInterval   time.Duration `yaml:"max_poll_interval" toml:"max_poll_interval" env:"KAFKA_MAX_POLL_INTERVAL"`
+	MaxRetries        int           `yaml:"max_retries" toml:"max_retries" env:"KAFKA_MAX_RETRIES"`
+	HandlerTimeout    time.Duration `yaml:"handler_timeout" toml:"handler_timeout" env:"KAFKA_HANDLER_TIMEOUT"`
+	CleanupInterval   time.Duration `yaml:"cleanup_interval" toml:"cleanup_interval" env:"KAFKA_CLEANUP_INTERVAL"`
+	MaxAge            time.Duration `yaml:"max_age" toml:"max_age" env:"KAFKA_MAX_AGE"`
+}
+
+type HTTP struct {
+	Port        string   `yaml:"port" toml:"port" env:"SERVER_PORT"`
+	AdminToken  string   `yaml:"admin_token" toml:"admin_token" env:"ADMIN_TOKEN"`
+	CORSOrigins []string `yaml:"cors_origins" toml:"cors_origins" env:"HTTP_CORS_ORIGINS" envSeparator:","`
+	MaxItemPage int      `yaml:"max_item_page" toml:"max_item_page" env:"HTTP_MAX_ITEM_PAGE"`
+	// RateLimit is the requests per second allowed per client IP, 0 for no limit.
+	RateLimit float64 `yaml:"rate_limit" toml:"rate_limit" env:"HTTP_RATE_LIMIT"`
+	RateBurst int     `yaml:"rate_burst" toml:"rate_burst" env:"HTTP_RATE_BURST"`
+}
+
+type GRPC struct {
+	Port          string        `yaml:"port" toml:"port" env:"GRPC_PORT"`
+	WatchInterval time.Duration `yaml:"watch_interval" toml:"watch_interval" env:"GRPC_WATCH_INTERVAL"`
+}
+
+type Events struct {
+	BufferSize int `yaml:"buffer_size" toml:"buffer_size" env:"EVENTS_BUFFER_SIZE"`
+}
+
+type Warmup struct {
+	Limit     int `yaml:"limit" toml:"limit" env:"WARMUP_LIMIT"`
+	BatchSize int `yaml:"batch_size" toml:"batch_size" env:"WARMUP_BATCH_SIZE"`
+}
+
+type Cache struct {
+	SnapshotPath    string `yaml:"snapshot_path" toml:"snapshot_path" env:"CACHE_SNAPSHOT_PATH"`
+	SnapshotMaxSize int64  `yaml:"snapshot_max_size" toml:"snapshot_max_size" env:"CACHE_SNAPSHOT_MAX_SIZE"`
+
+	EvictedCapacity int           `yaml:"evicted_capacity" toml:"evicted_capacity" env:"CACHE_EVICTED_CAPACITY"`
+	EvictedTTL      time.Duration `yaml:"evicted_ttl" toml:"evicted_ttl" env:"CACHE_EVICTED_TTL"`
+
+	InvalidationChannel      string        `yaml:"invalidation_channel" toml:"invalidation_channel" env:"CACHE_INVALIDATION_CHANNEL"`
+	InvalidationMode         string        `yaml:"invalidation_mode" toml:"invalidation_mode" env:"CACHE_INVALIDATION_MODE"`
+	InvalidationMinReconnect time.Duration `yaml:"invalidation_min_reconnect" toml:"invalidation_min_reconnect" env:"CACHE_INVALIDATION_MIN_RECONNECT"`
+	InvalidationMaxReconnect time.Duration `yaml:"invalidation_max_reconnect" toml:"invalidation_max_reconnect" env:"CACHE_INVALIDATION_MAX_RECONNECT"`
+}
+
+type Tracing struct {
+	Exporter     string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" toml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	ServiceName  string  `yaml:"service_name" toml:"service_name" env:"TRACING_SERVICE_NAME"`
+	SampleRatio  float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+type Webhook struct {
+	Workers        int           `yaml:"workers" toml:"workers" env:"WEBHOOK_WORKERS"`
+	BatchSize      int           `yaml:"batch_size" toml:"batch_size" env:"WEBHOOK_BATCH_SIZE"`
+	PollInterval   time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL"`
+	MaxAttempts    int           `yaml:"max_attempts" toml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" toml:"initial_backoff" env:"WEBHOOK_INITIAL_BACKOFF"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" toml:"max_backoff" env:"WEBHOOK_MAX_BACKOFF"`
+	Timeout        time.Duration `yaml:"timeout" toml:"timeout" env:"WEBHOOK_TIMEOUT"`
+}
+
+type Exchange struct {
+	RatesFile string `yaml:"rates_file" toml:"rates_file" env:"EXCHANGE_RATES_FILE"`
+}
+
+// Outbox publishes order stored events to Topic. An empty topic disables the
+// outbox.
+type Outbox struct {
+	Topic          string        `yaml:"topic" toml:"topic" env:"OUTBOX_TOPIC"`
+	BatchSize      int           `yaml:"batch_size" toml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+	PollInterval   time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	PublishTimeout time.Duration `yaml:"publish_timeout" toml:"publish_timeout" env:"OUTBOX_PUBLISH_TIMEOUT"`
+}
+
+type Rules struct {
+	PaymentAmountAction  string `yaml:"payment_amount" toml:"payment_amount" env:"RULE_PAYMENT_AMOUNT_ACTION"`
+	ItemTotalPriceAction string `yaml:"item_total_price" toml:"item_total_price" env:"RULE_ITEM_TOTAL_PRICE_ACTION"`
+}
+
+// Engine builds the rule engine with the configured actions.
+func (r Rules) Engine() (*rules.Engine, error) {
+	configured := map[string]string{
+		rules.RulePaymentAmount:  r.PaymentAmountAction,
+		rules.RuleItemTotalPrice: r.ItemTotalPriceAction,
+	}
+
+	actions := make(map[string]rules.Action, len(configured))
+	for rule, value := range configured {
+		action, err := rules.ParseAction(value)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule, err)
+		}
+		actions[rule] = action
+	}
+	return rules.NewEngine(actions), nil
+}
+
+func Default() Config {
+	return Config{
+		Storage: StoragePostgres,
+		Log: Log{
+			Level:      "info",
+			Format:     logger.FormatJSON,
+			RedactKeys: append([]string(nil), logger.DefaultRedactKeys...),
+		},
+		DB: DB{
+			Host:                 "localhost",
+			Port:                 "5432",
+			User:                 "postgres",
+			Password:             "postgres",
+			Name:                 "orders",
+			ReplicaMaxLag:        5 * time.Second,
+			ReplicaCheckInterval: 5 * time.Second,
+		},
+		Breaker: Breaker{
+			FailureThreshold: 5,
+			OpenTimeout:      10 * time.Second,
+			HalfOpenRequests: 1,
+		},
+		Kafka: Kafka{
+			BootstrapServers:  "localhost:9092",
+			GroupID:           "order-info-service",
+			Topic:             "orders",
+			AutoOffset:        "earliest",
+			SessionTimeout:    10 * time.Second,
+			HeartbeatInterval: 3 * time.Second,
+			MaxPollInterval:   5 * time.Minute,
+			MaxRetries:        3,
+			HandlerTimeout:    30 * time.Second,
+			CleanupInterval:   5 * time.Minute,
+			MaxAge:            30 * time.Minute,
+		},
+		HTTP: HTTP{
+			Port:        "8080",
+			CORSOrigins: []string{"http://localhost:8000", "http://localhost:8080"},
+			MaxItemPage: 100,
+			RateBurst:   20,
+		},
+		GRPC: GRPC{
+			Port:          "9090",
+			WatchInterval: time.Second,
+		},
+		Events: Events{
+			BufferSize: 16,
+		},
+		Warmup: Warmup{
+			Limit:     100,
+			BatchSize: 100,
+		},
+		Cache: Cache{
+			SnapshotPath:             "./data/cache.snapshot",
+			SnapshotMaxSize:          64 << 20,
+			EvictedCapacity:          1000,
+			EvictedTTL:               10 * time.Minute,
+			InvalidationChannel:      "order_changes",
+			InvalidationMode:         invalidation.ModeRefresh,
+			InvalidationMinReconnect: time.Second,
+			InvalidationMaxReconnect: time.Minute,
+		},
+		Tracing: Tracing{
+			Exporter:     tracing.ExporterNone,
+			OTLPEndpoint: "localhost:4317",
+			ServiceName:  "order-info-service",
+			SampleRatio:  1,
+		},
+		Webhook: Webhook{
+			Workers:        4,
+			BatchSize:      100,
+			PollInterval:   time.Second,
+			MaxAttempts:    5,
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Minute,
+			Timeout:        10 * time.Second,
+		},
+		Outbox: Outbox{
+			BatchSize:      outbox.DefaultBatchSize,
+			PollInterval:   outbox.DefaultPollInterval,
+			PublishTimeout: outbox.DefaultPublishTimeout,
+		},
+		Rules: Rules{
+			PaymentAmountAction:  string(rules.ActionFlag),
+			ItemTotalPriceAction: string(rules.ActionFlag),
+		},
+	}
+}
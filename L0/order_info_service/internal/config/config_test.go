@@ -0,0 +1,223 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+	assert.Equal(t, 30*time.Second, cfg.Kafka.HandlerTimeout)
+	assert.Equal(t, 100, cfg.HTTP.MaxItemPage)
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+log:
+  level: debug
+  format: console
+db:
+  host: db.internal
+  replica_hosts: [replica-1, "replica-2:5433"]
+kafka:
+  topic: orders-from-file
+  handler_timeout: 45s
+http:
+  cors_origins: ["https://orders.example.com"]
+  max_item_page: 50
+`)
+	t.Setenv("KAFKA_TOPIC", "orders-from-env")
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, "console", cfg.Log.Format)
+	assert.Equal(t, "db.internal", cfg.DB.Host)
+	assert.Equal(t, "5432", cfg.DB.Port)
+	assert.Equal(t, []string{"replica-1", "replica-2:5433"}, cfg.DB.ReplicaHosts)
+	assert.Equal(t, "orders-from-env", cfg.Kafka.Topic)
+	assert.Equal(t, 45*time.Second, cfg.Kafka.HandlerTimeout)
+	assert.Equal(t, []string{"https://orders.example.com"}, cfg.HTTP.CORSOrigins)
+	assert.Equal(t, 50, cfg.HTTP.MaxItemPage)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+instance_id = "node-1"
+
+[cache]
+evicted_capacity = 10
+evicted_ttl = "1m"
+
+[rules]
+payment_amount = "reject"
+`)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", cfg.InstanceID)
+	assert.Equal(t, 10, cfg.Cache.EvictedCapacity)
+	assert.Equal(t, time.Minute, cfg.Cache.EvictedTTL)
+	assert.Equal(t, "reject", cfg.Rules.PaymentAmountAction)
+	assert.Equal(t, "flag", cfg.Rules.ItemTotalPriceAction)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown.yaml":  "kafka:\n  topik: orders\n",
+		"unknown.toml":  "[kafka]\ntopik = \"orders\"\n",
+		"broken.yaml":   "kafka: [",
+		"invalid.yaml":  "http:\n  max_item_page: 0\n",
+		"config.json":   "{}",
+		"duration.toml": "[kafka]\nhandler_timeout = \"soon\"\n",
+	} {
+		_, err := Load(writeFile(t, name, content))
+		assert.ErrorIs(t, err, srvcerrors.ErrInvalidInput, name)
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoad_InvalidEnv(t *testing.T) {
+	t.Setenv("KAFKA_MAX_RETRIES", "many")
+
+	_, err := Load("")
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Log.Level = "loud"
+	cfg.Log.RedactPatterns = []string{"("}
+	cfg.DB.Port = "postgres"
+	cfg.Kafka.Topic = ""
+	cfg.Kafka.AutoOffset = "smallest"
+	cfg.Tracing.SampleRatio = 2
+	cfg.Warmup.Limit = 0
+	cfg.Rules.PaymentAmountAction = "ignore"
+
+	err := cfg.Validate()
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	for _, key := range []string{
+		"log.level", "log.redact_patterns", "db.port", "kafka.topic",
+		"kafka.auto_offset", "tracing.sample_ratio", "warmup.limit", "rules.payment_amount",
+	} {
+		assert.Contains(t, err.Error(), key+":")
+	}
+	assert.NotContains(t, err.Error(), "http.port")
+}
+
+func TestValidate_Outbox(t *testing.T) {
+	cfg := Default()
+	cfg.Outbox.BatchSize = 0
+	require.NoError(t, cfg.Validate(), "disabled outbox is not validated")
+
+	cfg.Outbox.Topic = cfg.Kafka.Topic
+	err := cfg.Validate()
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "outbox.topic:")
+	assert.Contains(t, err.Error(), "outbox.batch_size:")
+}
+
+func TestValidate_Storage(t *testing.T) {
+	cfg := Default()
+	cfg.Storage = "sqlite"
+	err := cfg.Validate()
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "storage:")
+
+	cfg = Default()
+	cfg.Storage = StorageMemory
+	cfg.DB.Host = ""
+	cfg.DB.Port = ""
+	require.NoError(t, cfg.Validate(), "memory storage does not need db settings")
+
+	cfg.Outbox.Topic = "order-events"
+	err = cfg.Validate()
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "outbox.topic:")
+}
+
+func TestReloader_Reload(t *testing.T) {
+	path := writeFile(t, "config.yaml", "log:\n  level: info\nkafka:\n  topic: orders\n")
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	r := NewReloader(path, cfg, &loggertest.MockLogger{})
+	var applied, previous []Config
+	r.OnReload(func(prev, next Config) {
+		previous = append(previous, prev)
+		applied = append(applied, next)
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: debug\nhttp:\n  max_item_page: 20\n  rate_limit: 5\n  rate_burst: 10\nkafka:\n  topic: orders-v2\n"), 0o600))
+	require.NoError(t, r.Reload())
+	require.Len(t, applied, 1)
+	assert.Equal(t, "debug", applied[0].Log.Level)
+	assert.Equal(t, "info", previous[0].Log.Level)
+	assert.Equal(t, 20, applied[0].HTTP.MaxItemPage)
+	assert.Equal(t, 5.0, applied[0].HTTP.RateLimit)
+	assert.Equal(t, 10, applied[0].HTTP.RateBurst)
+	assert.Equal(t, "orders", applied[0].Kafka.Topic, "topic needs a restart")
+	assert.Equal(t, applied[0], r.Current())
+
+	require.NoError(t, r.Reload())
+	require.Len(t, applied, 1, "nothing changed")
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: loud\n"), 0o600))
+	require.ErrorIs(t, r.Reload(), srvcerrors.ErrInvalidInput)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "debug", r.Current().Log.Level)
+}
+
+func TestReloader_SIGHUP(t *testing.T) {
+	path := writeFile(t, "config.yaml", "log:\n  level: info\n")
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	r := NewReloader(path, cfg, &loggertest.MockLogger{})
+	reloaded := make(chan Config, 1)
+	r.OnReload(func(_, next Config) { reloaded <- next })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		r.Wait()
+	}()
+	r.Start(ctx)
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: warn\n"), 0o600))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	select {
+	case cfg := <-reloaded:
+		assert.Equal(t, "warn", cfg.Log.Level)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded on SIGHUP")
+	}
+}
+
+func TestLoad_Example(t *testing.T) {
+	cfg, err := Load("../../config.example.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
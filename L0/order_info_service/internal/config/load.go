@@ -0,0 +1,72 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/caarlos0/env/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvFile names the environment variable holding the config file path.
+const EnvFile = "CONFIG_FILE"
+
+// Load builds the configuration from defaults, the YAML or TOML file at path
+// (skipped if path is empty) and environment variables, then validates it.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := decodeFile(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := env.Parse(&cfg); err != nil {
+		return Config{}, fmt.Errorf("%w: %v", srvcerrors.ErrInvalidInput, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		// An empty file decodes to io.EOF and leaves the defaults in place.
+		if err := dec.Decode(cfg); err != nil && len(bytes.TrimSpace(data)) > 0 {
+			return fmt.Errorf("%w: config %s: %v", srvcerrors.ErrInvalidInput, path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%w: config %s: %v", srvcerrors.ErrInvalidInput, path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, key := range undecoded {
+				keys[i] = key.String()
+			}
+			sort.Strings(keys)
+			return fmt.Errorf("%w: config %s: unknown keys %s", srvcerrors.ErrInvalidInput, path, strings.Join(keys, ", "))
+		}
+	default:
+		return fmt.Errorf("%w: config %s: unsupported format %q, expected .yaml, .yml or .toml",
+			srvcerrors.ErrInvalidInput, path, ext)
+	}
+	return nil
+}
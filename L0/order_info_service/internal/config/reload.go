@@ -0,0 +1,124 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"go.uber.org/zap"
+)
+
+// reloadable lists the settings applied on reload. Everything else is read
+// once at startup and needs a restart.
+var reloadable = []struct {
+	key  string
+	copy func(dst *Config, src Config)
+}{
+	{"log.level", func(dst *Config, src Config) { dst.Log.Level = src.Log.Level }},
+	{"http.max_item_page", func(dst *Config, src Config) { dst.HTTP.MaxItemPage = src.HTTP.MaxItemPage }},
+	{"http.rate_limit", func(dst *Config, src Config) { dst.HTTP.RateLimit = src.HTTP.RateLimit }},
+	{"http.rate_burst", func(dst *Config, src Config) { dst.HTTP.RateBurst = src.HTTP.RateBurst }},
+	{"cache.evicted_capacity", func(dst *Config, src Config) { dst.Cache.EvictedCapacity = src.Cache.EvictedCapacity }},
+	{"cache.evicted_ttl", func(dst *Config, src Config) { dst.Cache.EvictedTTL = src.Cache.EvictedTTL }},
+}
+
+// Reloader reloads the configuration on SIGHUP and hands the reloadable
+// settings to the registered callbacks.
+type Reloader struct {
+	path    string
+	logger  logger.Logger
+	mu      sync.Mutex
+	current Config
+	apply   []func(prev, next Config)
+	wg      sync.WaitGroup
+}
+
+func NewReloader(path string, current Config, logger logger.Logger) *Reloader {
+	return &Reloader{
+		path:    path,
+		logger:  logger,
+		current: current,
+	}
+}
+
+// OnReload registers fn to be called with the previous and the new
+// configuration after every reload that changed a reloadable setting. Settings
+// that can also be changed at runtime should only be applied if they differ,
+// so a reload does not undo an unrelated runtime change.
+func (r *Reloader) OnReload(fn func(prev, next Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apply = append(r.apply, fn)
+}
+
+func (r *Reloader) Current() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload loads the configuration again. An invalid configuration is rejected
+// as a whole and the current one stays in effect.
+func (r *Reloader) Reload() error {
+	next, err := Load(r.path)
+	if err != nil {
+		r.logger.Error("config: reload failed, keeping current config", zap.Error(err))
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated := r.current
+	var changed []string
+	for _, setting := range reloadable {
+		before := updated
+		setting.copy(&updated, next)
+		if !reflect.DeepEqual(before, updated) {
+			changed = append(changed, setting.key)
+		}
+	}
+	if !reflect.DeepEqual(updated, next) {
+		r.logger.Warn("config: some changed settings need a restart and were ignored", zap.String("path", r.path))
+	}
+	if len(changed) == 0 {
+		r.logger.Info("config: reloaded, nothing to apply", zap.String("path", r.path))
+		return nil
+	}
+
+	prev := r.current
+	r.current = updated
+	for _, fn := range r.apply {
+		fn(prev, updated)
+	}
+	r.logger.Info("config: reloaded", zap.String("path", r.path), zap.Strings("changed", changed))
+	return nil
+}
+
+// Start reloads the configuration on every SIGHUP until ctx is done.
+func (r *Reloader) Start(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				_ = r.Reload()
+			}
+		}
+	}()
+}
+
+func (r *Reloader) Wait() {
+	r.wg.Wait()
+}
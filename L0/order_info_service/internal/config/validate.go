@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/invalidation"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/rules"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/warmup"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+)
+
+type validator struct {
+	problems []error
+}
+
+func (v *validator) check(ok bool, key, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validator) required(key, value string) {
+	v.check(value != "", key, "is required")
+}
+
+func (v *validator) positive(key string, value int) {
+	v.check(value > 0, key, "must be positive, got %d", value)
+}
+
+func (v *validator) duration(key string, value time.Duration) {
+	v.check(value > 0, key, "must be positive, got %s", value)
+}
+
+func (v *validator) port(key, value string) {
+	port, err := strconv.Atoi(value)
+	v.check(err == nil && port > 0 && port <= 65535, key, "invalid port %q", value)
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.check(false, key, "unknown value %q, expected one of %v", value, allowed)
+}
+
+// Validate reports every invalid setting at once, so a broken config file
+// can be fixed in one go.
+func (c Config) Validate() error {
+	v := &validator{}
+
+	_, err := logger.ParseLevel(c.Log.Level)
+	v.check(err == nil, "log.level", "unknown level %q, expected debug, info, warn or error", c.Log.Level)
+	v.oneOf("log.format", c.Log.Format, logger.FormatJSON, logger.FormatConsole)
+	for _, pattern := range c.Log.RedactPatterns {
+		_, err := regexp.Compile(pattern)
+		v.check(err == nil, "log.redact_patterns", "invalid pattern %q: %v", pattern, err)
+	}
+
+	v.oneOf("storage", c.Storage, StoragePostgres, StorageMemory)
+	if c.Storage == StoragePostgres {
+		v.required("db.host", c.DB.Host)
+		v.port("db.port", c.DB.Port)
+		v.required("db.user", c.DB.User)
+		v.required("db.name", c.DB.Name)
+		v.check(c.DB.ReplicaMaxLag >= 0, "db.replica_max_lag", "must not be negative")
+		v.duration("db.replica_check_interval", c.DB.ReplicaCheckInterval)
+	}
+
+	v.positive("breaker.failure_threshold", c.Breaker.FailureThreshold)
+	v.duration("breaker.open_timeout", c.Breaker.OpenTimeout)
+	v.positive("breaker.half_open_requests", c.Breaker.HalfOpenRequests)
+
+	v.required("kafka.bootstrap_servers", c.Kafka.BootstrapServers)
+	v.required("kafka.group_id", c.Kafka.GroupID)
+	v.required("kafka.topic", c.Kafka.Topic)
+	v.oneOf("kafka.auto_offset", c.Kafka.AutoOffset, "earliest", "latest", "none")
+	v.duration("kafka.session_timeout", c.Kafka.SessionTimeout)
+	v.duration("kafka.heartbeat_interval", c.Kafka.HeartbeatInterval)
+	v.check(c.Kafka.HeartbeatInterval < c.Kafka.SessionTimeout, "kafka.heartbeat_interval",
+		"must be lower than kafka.session_timeout")
+	v.duration("kafka.max_poll_interval", c.Kafka.MaxPollInterval)
+	v.check(c.Kafka.MaxRetries >= 0, "kafka.max_retries", "must not be negative")
+	v.duration("kafka.handler_timeout", c.Kafka.HandlerTimeout)
+	v.duration("kafka.cleanup_interval", c.Kafka.CleanupInterval)
+	v.duration("kafka.max_age", c.Kafka.MaxAge)
+
+	v.port("http.port", c.HTTP.Port)
+	v.check(len(c.HTTP.CORSOrigins) > 0, "http.cors_origins", "is required")
+	v.positive("http.max_item_page", c.HTTP.MaxItemPage)
+	v.check(c.HTTP.RateLimit >= 0, "http.rate_limit", "must not be negative")
+	v.positive("http.rate_burst", c.HTTP.RateBurst)
+
+	v.port("grpc.port", c.GRPC.Port)
+	v.duration("grpc.watch_interval", c.GRPC.WatchInterval)
+
+	v.positive("events.buffer_size", c.Events.BufferSize)
+
+	v.check(c.Warmup.Limit >= 1 && c.Warmup.Limit <= warmup.MaxLimit, "warmup.limit",
+		"must be between 1 and %d, got %d", warmup.MaxLimit, c.Warmup.Limit)
+	v.positive("warmup.batch_size", c.Warmup.BatchSize)
+
+	v.check(c.Cache.SnapshotMaxSize > 0, "cache.snapshot_max_size", "must be positive")
+	v.check(c.Cache.EvictedCapacity >= 0, "cache.evicted_capacity", "must not be negative")
+	v.duration("cache.evicted_ttl", c.Cache.EvictedTTL)
+	v.oneOf("cache.invalidation_mode", c.Cache.InvalidationMode, invalidation.ModeRefresh, invalidation.ModeEvict)
+	v.duration("cache.invalidation_min_reconnect", c.Cache.InvalidationMinReconnect)
+	v.check(c.Cache.InvalidationMaxReconnect >= c.Cache.InvalidationMinReconnect, "cache.invalidation_max_reconnect",
+		"must not be lower than cache.invalidation_min_reconnect")
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter,
+		tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP, tracing.ExporterMemory)
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio",
+		"must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	v.positive("webhook.workers", c.Webhook.Workers)
+	v.positive("webhook.batch_size", c.Webhook.BatchSize)
+	v.duration("webhook.poll_interval", c.Webhook.PollInterval)
+	v.positive("webhook.max_attempts", c.Webhook.MaxAttempts)
+	v.duration("webhook.initial_backoff", c.Webhook.InitialBackoff)
+	v.check(c.Webhook.MaxBackoff >= c.Webhook.InitialBackoff, "webhook.max_backoff",
+		"must not be lower than webhook.initial_backoff")
+	v.duration("webhook.timeout", c.Webhook.Timeout)
+
+	if c.Outbox.Topic != "" {
+		v.check(c.Storage == StoragePostgres, "outbox.topic", "requires storage %q", StoragePostgres)
+		v.check(c.Outbox.Topic != c.Kafka.Topic, "outbox.topic", "must differ from kafka.topic")
+		v.positive("outbox.batch_size", c.Outbox.BatchSize)
+		v.duration("outbox.poll_interval", c.Outbox.PollInterval)
+		v.duration("outbox.publish_timeout", c.Outbox.PublishTimeout)
+	}
+
+	_, err = rules.ParseAction(c.Rules.PaymentAmountAction)
+	v.check(err == nil, "rules.payment_amount", "%v", err)
+	_, err = rules.ParseAction(c.Rules.ItemTotalPriceAction)
+	v.check(err == nil, "rules.item_total_price", "%v", err)
+
+	if len(v.problems) > 0 {
+		return fmt.Errorf("%w: invalid config: %w", srvcerrors.ErrInvalidInput, errors.Join(v.problems...))
+	}
+	return nil
+}
@@ -3,73 +3,243 @@ package controller
 import (
 	"context"
 	"errors"
-	"fmt"
+	"sync/atomic"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
 )
 
+const tracerName = "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
+
 type ControllerProvider interface {
 	GetOrderByUID(context.Context, string) (*model.Order, error)
+	GetOrdersByUIDs(context.Context, []string) (map[string]*model.Order, error)
 	GetItemsByOrderUID(context.Context, string, int, int) ([]*model.Item, error)
+	GetItemsByOrderUIDs(context.Context, []string, int, int) (map[string][]*model.Item, error)
+}
+
+// Breaker reports whether the database is currently considered unavailable.
+type Breaker interface {
+	Tripped() bool
 }
 
 type Controller struct {
-	repo   repository.RepositoryProvider
-	cache  cache.Cache
-	logger logger.Logger
+	repo    repository.RepositoryProvider
+	cache   cache.Cache
+	logger  logger.Logger
+	breaker Breaker
 }
 
-func NewController(r repository.RepositoryProvider, c cache.Cache, l logger.Logger) *Controller {
-	return &Controller{
+type Option func(*Controller)
+
+// WithBreaker makes cache hits count as stale while the breaker is tripped,
+// since invalidations cannot be observed while the database is down.
+func WithBreaker(b Breaker) Option {
+	return func(ctrl *Controller) {
+		ctrl.breaker = b
+	}
+}
+
+func NewController(r repository.RepositoryProvider, c cache.Cache, l logger.Logger, opts ...Option) *Controller {
+	ctrl := &Controller{
 		repo:   r,
 		cache:  c,
 		logger: l,
 	}
+	for _, opt := range opts {
+		opt(ctrl)
+	}
+	return ctrl
+}
+
+type staleKey struct{}
+
+// WithStaleTracking returns a context in which the controller records whether
+// the response was served from possibly outdated data. Check it with IsStale.
+func WithStaleTracking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, staleKey{}, new(atomic.Bool))
+}
+
+func IsStale(ctx context.Context) bool {
+	stale, ok := ctx.Value(staleKey{}).(*atomic.Bool)
+	return ok && stale.Load()
+}
+
+func markStale(ctx context.Context) {
+	if stale, ok := ctx.Value(staleKey{}).(*atomic.Bool); ok {
+		stale.Store(true)
+	}
+}
+
+// log returns the controller logger with the request fields carried by ctx.
+func (ctrl *Controller) log(ctx context.Context) logger.Logger {
+	return logger.FromContext(ctx, ctrl.logger)
+}
+
+func (ctrl *Controller) tripped() bool {
+	return ctrl.breaker != nil && ctrl.breaker.Tripped()
+}
+
+// evicted returns a recently evicted copy of the order when the database could
+// not be reached.
+func (ctrl *Controller) evicted(ctx context.Context, orderID string, err error) (*model.Order, bool) {
+	if !unreachable(err) {
+		return nil, false
+	}
+	order, ok := ctrl.cache.GetEvicted(orderID)
+	if !ok {
+		return nil, false
+	}
+	ctrl.servedStale(ctx, orderID, err)
+	return order, true
 }
 
-func (ctrl *Controller) GetOrderByUID(ctx context.Context, orderID string) (*model.Order, error) {
-	ctrl.logger.Info("controller: request to get order by id",
+// evictedItems is evicted for a page of the order items.
+func (ctrl *Controller) evictedItems(ctx context.Context, orderID string, lastID, limit int, err error) ([]*model.Item, bool) {
+	if !unreachable(err) {
+		return nil, false
+	}
+	items, ok := ctrl.cache.GetEvictedItems(orderID, lastID, limit)
+	if !ok {
+		return nil, false
+	}
+	ctrl.servedStale(ctx, orderID, err)
+	return items, true
+}
+
+func (ctrl *Controller) servedStale(ctx context.Context, orderID string, err error) {
+	ctrl.log(ctx).Warn("controller: database unavailable, serving stale order",
+		zap.String("order_uid", orderID),
+		zap.Error(err))
+	markStale(ctx)
+}
+
+func unreachable(err error) bool {
+	return errors.Is(err, srvcerrors.ErrUnavailable) || errors.Is(err, srvcerrors.ErrDatabase)
+}
+
+func (ctrl *Controller) GetOrderByUID(ctx context.Context, orderID string) (order *model.Order, err error) {
+	ctx, span := startSpan(ctx, "Controller.GetOrderByUID", attribute.String("order_uid", orderID))
+	defer func() { endSpan(ctx, span, err) }()
+
+	ctrl.log(ctx).Info("controller: request to get order by id",
 		zap.String("order_uid", orderID))
 	
-	order, err := ctrl.cache.GetOrderByUID(orderID)
+	order, err = ctrl.cache.GetOrderByUID(orderID)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
 	if err == nil {
+		if ctrl.tripped() {
+			markStale(ctx)
+		}
 		return order, nil
 	}
 
 	order, err = ctrl.repo.GetOrderByUID(ctx, orderID)
 	if err != nil {
-		logError(ctrl.logger, "controller: failed to get order by id", orderID, err)
+		if stale, ok := ctrl.evicted(ctx, orderID, err); ok {
+			return stale, nil
+		}
+		logError(ctrl.log(ctx), "controller: failed to get order by id", orderID, err)
 		return nil, err
 	}
 	ctrl.cache.SetOrder(order)
 	return order, nil
 }
 
-func (ctrl *Controller) GetItemsByOrderUID(ctx context.Context, orderID string, lastID, limit int) ([]*model.Item, error) {
-	ctrl.logger.Info("controller: request to get items by order id", 
+// GetOrdersByUIDs serves cached orders and loads the rest in one repository
+// call. Orders that do not exist are left out of the result.
+func (ctrl *Controller) GetOrdersByUIDs(ctx context.Context, orderIDs []string) (_ map[string]*model.Order, err error) {
+	ctx, span := startSpan(ctx, "Controller.GetOrdersByUIDs", attribute.Int("orders", len(orderIDs)))
+	defer func() { endSpan(ctx, span, err) }()
+
+	ctrl.log(ctx).Info("controller: request to get orders by ids",
+		zap.Strings("order_uids", orderIDs))
+
+	result := make(map[string]*model.Order, len(orderIDs))
+	missed := make([]string, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		order, err := ctrl.cache.GetOrderByUID(orderID)
+		if err == nil {
+			result[orderID] = order
+			continue
+		}
+		missed = append(missed, orderID)
+	}
+
+	span.SetAttributes(attribute.Int("cache.hits", len(result)))
+	if len(result) != 0 && ctrl.tripped() {
+		markStale(ctx)
+	}
+	if len(missed) == 0 {
+		return result, nil
+	}
+
+	fetched, err := ctrl.repo.GetOrdersByUIDs(ctx, missed)
+	if err != nil {
+		stale := make(map[string]*model.Order, len(missed))
+		for _, orderID := range missed {
+			order, ok := ctrl.evicted(ctx, orderID, err)
+			if !ok {
+				ctrl.log(ctx).Error("controller: failed to get orders by ids",
+					zap.Strings("order_uids", missed),
+					zap.Error(err))
+				return nil, err
+			}
+			stale[orderID] = order
+		}
+		for orderID, order := range stale {
+			result[orderID] = order
+		}
+		return result, nil
+	}
+
+	for orderID, order := range fetched {
+		ctrl.cache.SetOrder(order)
+		result[orderID] = order
+	}
+	return result, nil
+}
+
+func (ctrl *Controller) GetItemsByOrderUID(ctx context.Context, orderID string, lastID, limit int) (items []*model.Item, err error) {
+	ctx, span := startSpan(ctx, "Controller.GetItemsByOrderUID",
+		attribute.String("order_uid", orderID),
+		attribute.Int("last_id", lastID),
+		attribute.Int("limit", limit))
+	defer func() { endSpan(ctx, span, err) }()
+
+	ctrl.log(ctx).Info("controller: request to get items by order id", 
 		zap.String("order_uid", orderID),
 		zap.Int("limit", limit))
 	
-	items, err := ctrl.cache.GetItemsByOrderUID(orderID, lastID, limit)
+	items, err = ctrl.cache.GetItemsByOrderUID(orderID, lastID, limit)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil && len(items) != 0))
 	if err == nil && len(items) != 0 {
+		if ctrl.tripped() {
+			markStale(ctx)
+		}
 		return items, nil
 	}
 
 	items, err = ctrl.repo.GetItemsByOrderUID(ctx, orderID, lastID, limit)
 	if err != nil {
-		logError(ctrl.logger, "controller: failed to get items", orderID, err)
+		if stale, ok := ctrl.evictedItems(ctx, orderID, lastID, limit, err); ok {
+			return stale, nil
+		}
+		logError(ctrl.log(ctx), "controller: failed to get items", orderID, err)
 		return nil, err
 	}
 
 	order, err := ctrl.GetOrderByUID(ctx, orderID)
 	if err != nil {
-		ctrl.logger.Warn("controller: failed to update cache", 
+		ctrl.log(ctx).Warn("controller: failed to update cache", 
         zap.String("order_uid", orderID), 
         zap.Error(err))
 		return items, nil
@@ -80,17 +250,77 @@ func (ctrl *Controller) GetItemsByOrderUID(ctx context.Context, orderID string,
 	return items, nil
 }
 
-func WarmUpCache(ctx context.Context, repo repository.RepositoryProvider, cache cache.Cache, limit int) (int, error){
-	orders, err := repo.GetAllOrders(ctx, limit)
+func (ctrl *Controller) GetItemsByOrderUIDs(ctx context.Context, orderIDs []string, lastID, limit int) (_ map[string][]*model.Item, err error) {
+	ctx, span := startSpan(ctx, "Controller.GetItemsByOrderUIDs",
+		attribute.Int("orders", len(orderIDs)),
+		attribute.Int("last_id", lastID),
+		attribute.Int("limit", limit))
+	defer func() { endSpan(ctx, span, err) }()
+
+	ctrl.log(ctx).Info("controller: request to get items by order ids",
+		zap.Strings("order_uids", orderIDs),
+		zap.Int("limit", limit))
+
+	result := make(map[string][]*model.Item, len(orderIDs))
+	missed := make([]string, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		items, err := ctrl.cache.GetItemsByOrderUID(orderID, lastID, limit)
+		if err == nil && len(items) != 0 {
+			result[orderID] = items
+			continue
+		}
+		missed = append(missed, orderID)
+	}
+
+	span.SetAttributes(attribute.Int("cache.hits", len(result)))
+	if len(result) != 0 && ctrl.tripped() {
+		markStale(ctx)
+	}
+	if len(missed) == 0 {
+		return result, nil
+	}
+
+	fetched, err := ctrl.repo.GetItemsByOrderUIDs(ctx, missed, lastID, limit)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get orders to warmup cache: %w", err)
+		if ctrl.fillEvicted(ctx, result, missed, lastID, limit, err) {
+			return result, nil
+		}
+		ctrl.log(ctx).Error("controller: failed to get items of orders",
+			zap.Strings("order_uids", missed),
+			zap.Error(err))
+		return nil, err
 	}
-	
-	for _, order := range orders {
-		cache.SetOrder(order)
+
+	for _, orderID := range missed {
+		result[orderID] = fetched[orderID]
 	}
-	
-	return len(orders), nil
+	return result, nil
+}
+
+// fillEvicted completes result from recently evicted orders. It succeeds only if
+// every missed order is still retained.
+func (ctrl *Controller) fillEvicted(ctx context.Context, result map[string][]*model.Item, missed []string, lastID, limit int, err error) bool {
+	stale := make(map[string][]*model.Item, len(missed))
+	for _, orderID := range missed {
+		items, ok := ctrl.evictedItems(ctx, orderID, lastID, limit, err)
+		if !ok {
+			return false
+		}
+		stale[orderID] = items
+	}
+	for orderID, items := range stale {
+		result[orderID] = items
+	}
+	return true
+}
+
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func endSpan(ctx context.Context, span trace.Span, err error) {
+	span.SetAttributes(attribute.Bool("stale", IsStale(ctx)))
+	tracing.End(span, err)
 }
 
 func logError(logger logger.Logger, msg string, orderID string, err error) {
@@ -5,13 +5,17 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
-	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type MockRepository struct {
@@ -26,6 +30,11 @@ func (m *MockRepository) GetOrderByUID(ctx context.Context, orderID string) (*mo
     return nil, args.Error(1)
 }
 
+func (m *MockRepository) OrderExists(ctx context.Context, orderID string) (bool, error) {
+    args := m.Called(ctx, orderID)
+    return args.Bool(0), args.Error(1)
+}
+
 func (m *MockRepository) GetItemsByOrderUID(ctx context.Context, orderID string, lastID, limit int) ([]*model.Item, error) {
     args := m.Called(ctx, orderID, lastID, limit)
     if items, ok := args.Get(0).([]*model.Item); ok || args.Get(0) == nil {
@@ -34,6 +43,14 @@ func (m *MockRepository) GetItemsByOrderUID(ctx context.Context, orderID string,
     return nil, args.Error(1)
 }
 
+func (m *MockRepository) GetItemsByOrderUIDs(ctx context.Context, orderIDs []string, lastID, limit int) (map[string][]*model.Item, error) {
+    args := m.Called(ctx, orderIDs, lastID, limit)
+    if items, ok := args.Get(0).(map[string][]*model.Item); ok || args.Get(0) == nil {
+        return items, args.Error(1)
+    }
+    return nil, args.Error(1)
+}
+
 func (m *MockRepository) GetAllOrders(ctx context.Context, limit int) ([]*model.Order, error) {
     args := m.Called(ctx, limit)
     if orders, ok := args.Get(0).([]*model.Order); ok || args.Get(0) == nil {
@@ -42,25 +59,34 @@ func (m *MockRepository) GetAllOrders(ctx context.Context, limit int) ([]*model.
     return nil, args.Error(1)
 }
 
-func (m *MockRepository) UpsertOrder(ctx context.Context, o *model.Order) (*model.Order, error) {
+func (m *MockRepository) GetRecentOrders(ctx context.Context, before *model.OrderCursor, limit int) ([]*model.Order, error) {
+    args := m.Called(ctx, before, limit)
+    if orders, ok := args.Get(0).([]*model.Order); ok || args.Get(0) == nil {
+        return orders, args.Error(1)
+    }
+    return nil, args.Error(1)
+}
+
+func (m *MockRepository) GetOrdersByUIDs(ctx context.Context, orderIDs []string) (map[string]*model.Order, error) {
+    args := m.Called(ctx, orderIDs)
+    if orders, ok := args.Get(0).(map[string]*model.Order); ok || args.Get(0) == nil {
+        return orders, args.Error(1)
+    }
+    return nil, args.Error(1)
+}
+
+func (m *MockRepository) UpsertOrder(ctx context.Context, o *model.Order) (*model.Order, bool, error) {
     args := m.Called(ctx, o)
     if order, ok := args.Get(0).(*model.Order); ok || args.Get(0) == nil {
-        return order, args.Error(1)
+        return order, args.Bool(1), args.Error(2)
     }
-    return nil, args.Error(1)
+    return nil, false, args.Error(2)
 }
 
 type MockCache struct {
 	mock.Mock
 }
 
-type MockLogger struct{}
-
-func (n *MockLogger) Info(msg string, fields ...logger.Field) {}
-func (n *MockLogger) Error(msg string, fields ...logger.Field) {}
-func (n *MockLogger) Debug(msg string, fields ...logger.Field) {}
-func (n *MockLogger) Warn(msg string, fields ...logger.Field) {}
-
 func (m *MockCache) GetOrderByUID(orderID string) (*model.Order, error) {
     args := m.Called(orderID)
     if order, ok := args.Get(0).(*model.Order); ok || args.Get(0) == nil {
@@ -81,10 +107,45 @@ func (m *MockCache) SetOrder(order *model.Order) {
 	m.Called(order)
 }
 
+func (m *MockCache) SetOrderIfAbsent(order *model.Order) bool {
+	args := m.Called(order)
+	return args.Bool(0)
+}
+
+func (m *MockCache) Delete(orderID string) bool {
+	args := m.Called(orderID)
+	return args.Bool(0)
+}
+
 func (m *MockCache) Clear() {
 	m.Called()
 }
 
+func (m *MockCache) Stats() cache.Stats {
+	args := m.Called()
+	return args.Get(0).(cache.Stats)
+}
+
+func (m *MockCache) GetEvicted(orderID string) (*model.Order, bool) {
+	args := m.Called(orderID)
+	order, _ := args.Get(0).(*model.Order)
+	return order, args.Bool(1)
+}
+
+func (m *MockCache) GetEvictedItems(orderID string, lastID, limit int) ([]*model.Item, bool) {
+	args := m.Called(orderID, lastID, limit)
+	items, _ := args.Get(0).([]*model.Item)
+	return items, args.Bool(1)
+}
+
+type MockBreaker struct {
+	tripped bool
+}
+
+func (b *MockBreaker) Tripped() bool {
+	return b.tripped
+}
+
 func generateTestOrder(uid string, itemCount int) *model.Order {
 	order := &model.Order{
 		OrderUID:    uid,
@@ -114,7 +175,7 @@ func generateTestItems(count int) []*model.Item {
 func TestGetOrderByUID_CacheMiss_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockCache := new(MockCache)
-	mockLogger := new(MockLogger)
+	mockLogger := new(loggertest.MockLogger)
 	
 	order := generateTestOrder("ORDER-001", 2)
 	
@@ -134,10 +195,11 @@ func TestGetOrderByUID_CacheMiss_Success(t *testing.T) {
 func TestGetOrderByUID_RepositoryError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockCache := new(MockCache)
-	mockLogger := new(MockLogger)
+	mockLogger := new(loggertest.MockLogger)
 	
 	mockCache.On("GetOrderByUID", "ORDER-001").Return(nil, errors.New("not found"))
 	mockRepo.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(nil, srvcerrors.ErrDatabase)
+	mockCache.On("GetEvicted", "ORDER-001").Return(nil, false)
 	
 	ctrl := controller.NewController(mockRepo, mockCache, mockLogger)
 	
@@ -151,7 +213,7 @@ func TestGetOrderByUID_RepositoryError(t *testing.T) {
 func TestGetItemsByOrderUID_CacheMiss_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockCache := new(MockCache)
-	mockLogger := new(MockLogger)
+	mockLogger := new(loggertest.MockLogger)
 	
 	
 	items := generateTestItems(5)
@@ -177,10 +239,11 @@ func TestGetItemsByOrderUID_CacheMiss_Success(t *testing.T) {
 func TestGetItemsByOrderUID_RepositoryError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockCache := new(MockCache)
-	mockLogger := new(MockLogger)
+	mockLogger := new(loggertest.MockLogger)
 	
 	mockCache.On("GetItemsByOrderUID", "ORDER-001").Return(nil, errors.New("not found"))
 	mockRepo.On("GetItemsByOrderUID", mock.Anything, "ORDER-001", 0, 10).Return(nil, srvcerrors.ErrDatabase)
+	mockCache.On("GetEvictedItems", "ORDER-001", 0, 10).Return(nil, false)
 	
 	ctrl := controller.NewController(mockRepo, mockCache, mockLogger)
 	
@@ -194,7 +257,7 @@ func TestGetItemsByOrderUID_RepositoryError(t *testing.T) {
 func TestGetItemsByOrderUID_GetOrderError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockCache := new(MockCache)
-	mockLogger := new(MockLogger)
+	mockLogger := new(loggertest.MockLogger)
 	
 	items := generateTestItems(5)
 	
@@ -212,37 +275,204 @@ func TestGetItemsByOrderUID_GetOrderError(t *testing.T) {
 	assert.Equal(t, items, result)
 }
 
-func TestWarmUpCache_Success(t *testing.T) {
+func TestGetItemsByOrderUIDs_PartialCacheHit(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockCache := new(MockCache)
-	
-	orders := []*model.Order{
-		generateTestOrder("ORDER-001", 2),
-		generateTestOrder("ORDER-002", 3),
+	mockLogger := new(loggertest.MockLogger)
+
+	cached := generateTestItems(2)
+	fetched := map[string][]*model.Item{
+		"ORDER-002": generateTestItems(3),
 	}
-	
-	mockRepo.On("GetAllOrders", mock.Anything, 10).Return(orders, nil)
-	mockCache.On("SetOrder", orders[0]).Return()
-	mockCache.On("SetOrder", orders[1]).Return()
-	
-	ctx := context.Background()
-	amount, err := controller.WarmUpCache(ctx, mockRepo, mockCache, 10)
-	
+
+	mockCache.On("GetItemsByOrderUID", "ORDER-001").Return(cached, nil)
+	mockCache.On("GetItemsByOrderUID", "ORDER-002").Return(nil, errors.New("not found"))
+	mockCache.On("GetItemsByOrderUID", "ORDER-003").Return(nil, errors.New("not found"))
+	mockRepo.On("GetItemsByOrderUIDs", mock.Anything, []string{"ORDER-002", "ORDER-003"}, 0, 10).Return(fetched, nil)
+
+	ctrl := controller.NewController(mockRepo, mockCache, mockLogger)
+
+	result, err := ctrl.GetItemsByOrderUIDs(context.Background(), []string{"ORDER-001", "ORDER-002", "ORDER-003"}, 0, 10)
+
 	require.NoError(t, err)
-	require.Equal(t, 2, amount)
+	assert.Equal(t, cached, result["ORDER-001"])
+	assert.Equal(t, fetched["ORDER-002"], result["ORDER-002"])
+	assert.Empty(t, result["ORDER-003"])
+	mockRepo.AssertNumberOfCalls(t, "GetItemsByOrderUIDs", 1)
 }
 
-func TestWarmUpCache_RepositoryError(t *testing.T) {
+func TestGetOrdersByUIDs_PartialCacheHit(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockCache := new(MockCache)
-	
-	mockRepo.On("GetAllOrders", mock.Anything, 10).Return(nil, srvcerrors.ErrDatabase)
-	
-	ctx := context.Background()
-	amount, err := controller.WarmUpCache(ctx, mockRepo, mockCache, 10)
-	
+	mockLogger := new(loggertest.MockLogger)
+
+	cached := generateTestOrder("ORDER-001", 0)
+	fetched := generateTestOrder("ORDER-002", 2)
+
+	mockCache.On("GetOrderByUID", "ORDER-001").Return(cached, nil)
+	mockCache.On("GetOrderByUID", "ORDER-002").Return(nil, errors.New("not found"))
+	mockCache.On("GetOrderByUID", "ORDER-003").Return(nil, errors.New("not found"))
+	mockRepo.On("GetOrdersByUIDs", mock.Anything, []string{"ORDER-002", "ORDER-003"}).
+		Return(map[string]*model.Order{"ORDER-002": fetched}, nil)
+	mockCache.On("SetOrder", fetched).Return()
+
+	ctrl := controller.NewController(mockRepo, mockCache, mockLogger)
+
+	result, err := ctrl.GetOrdersByUIDs(context.Background(), []string{"ORDER-001", "ORDER-002", "ORDER-003"})
+
+	require.NoError(t, err)
+	assert.Equal(t, cached, result["ORDER-001"])
+	assert.Equal(t, fetched, result["ORDER-002"])
+	assert.NotContains(t, result, "ORDER-003")
+	mockRepo.AssertNumberOfCalls(t, "GetOrdersByUIDs", 1)
+	mockCache.AssertExpectations(t)
+}
+
+func TestGetOrderByUID_CacheHitWhileTripped_Stale(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockCache := new(MockCache)
+
+	order := generateTestOrder("ORDER-001", 1)
+	mockCache.On("GetOrderByUID", "ORDER-001").Return(order, nil)
+
+	ctx := controller.WithStaleTracking(context.Background())
+
+	ctrl := controller.NewController(mockRepo, mockCache, new(loggertest.MockLogger))
+	_, err := ctrl.GetOrderByUID(ctx, "ORDER-001")
+	require.NoError(t, err)
+	assert.False(t, controller.IsStale(ctx))
+
+	ctrl = controller.NewController(mockRepo, mockCache, new(loggertest.MockLogger), controller.WithBreaker(&MockBreaker{tripped: true}))
+	result, err := ctrl.GetOrderByUID(ctx, "ORDER-001")
+	require.NoError(t, err)
+	assert.Equal(t, order, result)
+	assert.True(t, controller.IsStale(ctx))
+	mockRepo.AssertNotCalled(t, "GetOrderByUID", mock.Anything, mock.Anything)
+}
+
+func TestGetOrderByUID_Unavailable_ServesEvicted(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockCache := new(MockCache)
+
+	order := generateTestOrder("ORDER-001", 1)
+	mockCache.On("GetOrderByUID", "ORDER-001").Return(nil, srvcerrors.ErrNotFound)
+	mockCache.On("GetEvicted", "ORDER-001").Return(order, true)
+	mockRepo.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(nil, srvcerrors.ErrUnavailable)
+
+	ctrl := controller.NewController(mockRepo, mockCache, new(loggertest.MockLogger))
+
+	ctx := controller.WithStaleTracking(context.Background())
+	result, err := ctrl.GetOrderByUID(ctx, "ORDER-001")
+
+	require.NoError(t, err)
+	assert.Equal(t, order, result)
+	assert.True(t, controller.IsStale(ctx))
+	mockCache.AssertNotCalled(t, "SetOrder", mock.Anything)
+}
+
+func TestGetOrderByUID_Unavailable_NotRetained(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockCache := new(MockCache)
+
+	mockCache.On("GetOrderByUID", "ORDER-001").Return(nil, srvcerrors.ErrNotFound)
+	mockCache.On("GetEvicted", "ORDER-001").Return(nil, false)
+	mockRepo.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(nil, srvcerrors.ErrUnavailable)
+
+	ctrl := controller.NewController(mockRepo, mockCache, new(loggertest.MockLogger))
+
+	_, err := ctrl.GetOrderByUID(context.Background(), "ORDER-001")
+	assert.ErrorIs(t, err, srvcerrors.ErrUnavailable)
+}
+
+func TestGetOrderByUID_NotFound_SkipsEvicted(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockCache := new(MockCache)
+
+	mockCache.On("GetOrderByUID", "ORDER-001").Return(nil, srvcerrors.ErrNotFound)
+	mockRepo.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(nil, srvcerrors.ErrNotFound)
+
+	ctrl := controller.NewController(mockRepo, mockCache, new(loggertest.MockLogger))
+
+	_, err := ctrl.GetOrderByUID(context.Background(), "ORDER-001")
+	assert.ErrorIs(t, err, srvcerrors.ErrNotFound)
+	mockCache.AssertNotCalled(t, "GetEvicted", mock.Anything)
+}
+
+func TestGetItemsByOrderUID_Unavailable_ServesEvictedPage(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockCache := new(MockCache)
+
+	order := generateTestOrder("ORDER-001", 5)
+	for i, item := range order.Items {
+		item.ID = i + 1
+	}
+	mockCache.On("GetItemsByOrderUID", "ORDER-001").Return(nil, srvcerrors.ErrNotFound)
+	mockCache.On("GetEvictedItems", "ORDER-001", 2, 2).Return(order.Items[2:4], true)
+	mockRepo.On("GetItemsByOrderUID", mock.Anything, "ORDER-001", 2, 2).Return(nil, srvcerrors.ErrDatabase)
+
+	ctrl := controller.NewController(mockRepo, mockCache, new(loggertest.MockLogger))
+
+	ctx := controller.WithStaleTracking(context.Background())
+	result, err := ctrl.GetItemsByOrderUID(ctx, "ORDER-001", 2, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, order.Items[2:4], result)
+	assert.True(t, controller.IsStale(ctx))
+}
+
+func TestGetItemsByOrderUIDs_Unavailable(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockCache := new(MockCache)
+
+	cached := generateTestItems(2)
+	evicted := generateTestOrder("ORDER-002", 3)
+	mockCache.On("GetItemsByOrderUID", "ORDER-001").Return(cached, nil)
+	mockCache.On("GetItemsByOrderUID", "ORDER-002").Return(nil, srvcerrors.ErrNotFound)
+	mockCache.On("GetItemsByOrderUID", "ORDER-003").Return(nil, srvcerrors.ErrNotFound)
+	mockCache.On("GetEvictedItems", "ORDER-002", 0, 10).Return(evicted.Items, true)
+	mockCache.On("GetEvictedItems", "ORDER-003", 0, 10).Return(nil, false)
+	mockRepo.On("GetItemsByOrderUIDs", mock.Anything, []string{"ORDER-002"}, 0, 10).Return(nil, srvcerrors.ErrUnavailable)
+	mockRepo.On("GetItemsByOrderUIDs", mock.Anything, []string{"ORDER-002", "ORDER-003"}, 0, 10).Return(nil, srvcerrors.ErrUnavailable)
+
+	ctrl := controller.NewController(mockRepo, mockCache, new(loggertest.MockLogger))
+
+	ctx := controller.WithStaleTracking(context.Background())
+	result, err := ctrl.GetItemsByOrderUIDs(ctx, []string{"ORDER-001", "ORDER-002"}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, cached, result["ORDER-001"])
+	assert.Equal(t, evicted.Items, result["ORDER-002"])
+	assert.True(t, controller.IsStale(ctx))
+
+	_, err = ctrl.GetItemsByOrderUIDs(context.Background(), []string{"ORDER-001", "ORDER-002", "ORDER-003"}, 0, 10)
+	assert.ErrorIs(t, err, srvcerrors.ErrUnavailable)
+}
+
+func TestGetOrderByUID_Spans(t *testing.T) {
+	provider, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterMemory, SampleRatio: 1})
+	require.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	mockRepo := new(MockRepository)
+	mockCache := new(MockCache)
+
+	order := generateTestOrder("ORDER-001", 1)
+	mockCache.On("GetOrderByUID", "ORDER-001").Return(order, nil)
+	mockCache.On("GetOrderByUID", "ORDER-002").Return(nil, srvcerrors.ErrNotFound)
+	mockCache.On("GetEvicted", "ORDER-002").Return(nil, false)
+	mockRepo.On("GetOrderByUID", mock.Anything, "ORDER-002").Return(nil, srvcerrors.ErrDatabase)
+
+	ctrl := controller.NewController(mockRepo, mockCache, new(loggertest.MockLogger))
+	_, err = ctrl.GetOrderByUID(context.Background(), "ORDER-001")
+	require.NoError(t, err)
+	_, err = ctrl.GetOrderByUID(context.Background(), "ORDER-002")
 	require.Error(t, err)
-	require.Equal(t, 0, amount)
-	assert.Contains(t, err.Error(), "failed to get orders to warmup cache")
-	assert.ErrorIs(t, err, srvcerrors.ErrDatabase)
-}
\ No newline at end of file
+
+	spans := provider.Spans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "Controller.GetOrderByUID", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.String("order_uid", "ORDER-001"))
+	assert.Contains(t, spans[0].Attributes, attribute.Bool("cache.hit", true))
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Contains(t, spans[1].Attributes, attribute.Bool("cache.hit", false))
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+
+func ScanRevenuePointFromRow(row RowScanner) (*model.RevenuePoint, error) {
+	var point model.RevenuePoint
+	if err := row.Scan(&point.Bucket, &point.Currency, &point.Revenue, &point.Orders); err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+func ScanOrderCountPointFromRow(row RowScanner) (*model.OrderCountPoint, error) {
+	var point model.OrderCountPoint
+	if err := row.Scan(&point.Bucket, &point.Key, &point.Orders); err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+func ScanBrandStatFromRow(row RowScanner) (*model.BrandStat, error) {
+	var stat model.BrandStat
+	if err := row.Scan(&stat.Bucket, &stat.Brand, &stat.Items, &stat.Orders); err != nil {
+		return nil, err
+	}
+	return &stat, nil
+}
+
+func ScanProductStatFromRow(row RowScanner) (*model.ProductStat, error) {
+	var stat model.ProductStat
+	if err := row.Scan(&stat.Bucket, &stat.NmID, &stat.Items, &stat.Orders); err != nil {
+		return nil, err
+	}
+	return &stat, nil
+}
+
+func ScanBasketPointFromRow(row RowScanner) (*model.BasketPoint, error) {
+	var point model.BasketPoint
+	if err := row.Scan(&point.Bucket, &point.Currency, &point.Orders, &point.AvgItems, &point.AvgAmount); err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+
+func ScanExchangeRateFromRow(row RowScanner) (*model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	if err := row.Scan(&rate.Currency, &rate.Rate, &rate.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+
+func ScanOrderFlagFromRow(row RowScanner) (*model.OrderFlag, error) {
+	var flag model.OrderFlag
+	if err := row.Scan(&flag.Rule, &flag.Message, &flag.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
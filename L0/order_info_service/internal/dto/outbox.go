@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+
+func ScanOutboxMessageFromRow(row RowScanner) (*model.OutboxMessage, error) {
+	var msg model.OutboxMessage
+	if err := row.Scan(&msg.ID, &msg.EventID, &msg.OrderUID, &msg.EventType, &msg.Payload, &msg.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"database/sql"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/lib/pq"
+)
+
+func ScanQuarantinedOrderFromRow(row RowScanner) (*model.QuarantinedOrder, error) {
+	var q model.QuarantinedOrder
+	var payload []byte
+	var kafkaTimestamp, resubmittedAt sql.NullTime
+
+	if err := row.Scan(
+		&q.ID,
+		&q.OrderUID,
+		&payload,
+		&q.Stage,
+		pq.Array(&q.Errors),
+		&q.Topic,
+		&q.Partition,
+		&q.Offset,
+		&q.Key,
+		&kafkaTimestamp,
+		&q.Status,
+		&q.ReceivedAt,
+		&q.UpdatedAt,
+		&resubmittedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	q.Payload = payload
+	if kafkaTimestamp.Valid {
+		q.KafkaTimestamp = &kafkaTimestamp.Time
+	}
+	if resubmittedAt.Valid {
+		q.ResubmittedAt = &resubmittedAt.Time
+	}
+	return &q, nil
+}
@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"encoding/json"
+
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 )
 
@@ -30,6 +32,86 @@ func ScanOrderFromRow(row RowScanner) (*model.Order, error){
 	return &order, nil
 }
 
+// ScanUpsertedOrderFromRow scans the order row returned by an upsert followed
+// by whether the row was inserted rather than updated.
+func ScanUpsertedOrderFromRow(row RowScanner) (*model.Order, bool, error) {
+	var order model.Order
+	var inserted bool
+
+	if err := row.Scan(
+		&order.OrderUID,
+		&order.TrackNumber,
+		&order.Entry,
+		&order.Locale,
+		&order.InternalSignature,
+		&order.CustomerID,
+		&order.DeliveryService,
+		&order.Shardkey,
+		&order.SmID,
+		&order.DateCreated,
+		&order.OofShard,
+		&inserted,
+	); err != nil {
+		return nil, false, err
+	}
+
+	return &order, inserted, nil
+}
+
+func ScanHydratedOrderFromRow(row RowScanner) (*model.Order, error) {
+	var order model.Order
+	var flags, items []byte
+
+	if err := row.Scan(
+		&order.OrderUID,
+		&order.TrackNumber,
+		&order.Entry,
+		&order.Locale,
+		&order.InternalSignature,
+		&order.CustomerID,
+		&order.DeliveryService,
+		&order.Shardkey,
+		&order.SmID,
+		&order.DateCreated,
+		&order.OofShard,
+		&order.Delivery.OrderUID,
+		&order.Delivery.Name,
+		&order.Delivery.Phone,
+		&order.Delivery.Zip,
+		&order.Delivery.City,
+		&order.Delivery.Address,
+		&order.Delivery.Region,
+		&order.Delivery.Email,
+		&order.Payment.Transaction,
+		&order.Payment.RequestID,
+		&order.Payment.Currency,
+		&order.Payment.Provider,
+		&order.Payment.Amount,
+		&order.Payment.PaymentDT,
+		&order.Payment.Bank,
+		&order.Payment.DeliveryCost,
+		&order.Payment.GoodsTotal,
+		&order.Payment.CustomFee,
+		&flags,
+		&items,
+	); err != nil {
+		return nil, err
+	}
+
+	if flags != nil {
+		if err := json.Unmarshal(flags, &order.Flags); err != nil {
+			return nil, err
+		}
+	}
+	if items != nil {
+		if err := json.Unmarshal(items, &order.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	return &order, nil
+}
+
 func ScanDeliveryFromRow(row RowScanner) (*model.Delivery, error){
 	var delivery model.Delivery
 	
@@ -92,4 +174,32 @@ func ScanItemFromRow(row RowScanner) (*model.Item, error){
 	}
 	
 	return &item, nil
-}
\ No newline at end of file
+}
+
+func ScanItemSearchHitFromRow(row RowScanner) (*model.ItemSearchHit, error) {
+	var item model.Item
+	var hit model.ItemSearchHit
+
+	if err := row.Scan(
+		&item.ID,
+		&item.OrderUID,
+		&item.ChrtID,
+		&item.TrackNumber,
+		&item.Price,
+		&item.RID,
+		&item.Name,
+		&item.Sale,
+		&item.Size,
+		&item.TotalPrice,
+		&item.NmID,
+		&item.Brand,
+		&item.Status,
+		&hit.Rank,
+	); err != nil {
+		return nil, err
+	}
+
+	hit.OrderUID = item.OrderUID
+	hit.Item = &item
+	return &hit, nil
+}
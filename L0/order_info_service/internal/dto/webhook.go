@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/lib/pq"
+)
+
+func ScanWebhookSubscriptionFromRow(row RowScanner) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+
+	if err := row.Scan(
+		&sub.ID,
+		&sub.URL,
+		&sub.Secret,
+		pq.Array(&sub.Events),
+		&sub.Active,
+		&sub.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+func ScanWebhookDeliveryFromRow(row RowScanner) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+
+	if err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.OrderUID,
+		&delivery.Attempt,
+		&delivery.StatusCode,
+		&delivery.Success,
+		&delivery.Error,
+		&delivery.DurationMs,
+		&delivery.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func ScanWebhookPendingDeliveryFromRow(row RowScanner) (*model.WebhookPendingDelivery, error) {
+	var pending model.WebhookPendingDelivery
+
+	if err := row.Scan(
+		&pending.ID,
+		&pending.SubscriptionID,
+		&pending.URL,
+		&pending.Secret,
+		&pending.Active,
+		&pending.EventID,
+		&pending.EventType,
+		&pending.OrderUID,
+		&pending.Payload,
+		&pending.Attempts,
+	); err != nil {
+		return nil, err
+	}
+
+	return &pending, nil
+}
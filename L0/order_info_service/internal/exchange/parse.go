@@ -0,0 +1,105 @@
+package exchange
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported exchange rates file %q, expected .csv or .json", srvcerrors.ErrInvalidInput, path)
+	}
+}
+
+func ParseRates(r io.Reader, format string) ([]*model.ExchangeRate, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatJSON:
+		return parseJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: unsupported exchange rates format %q", srvcerrors.ErrInvalidInput, format)
+	}
+}
+
+func parseCSV(r io.Reader) ([]*model.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	rates := make([]*model.ExchangeRate, 0)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed exchange rates csv: %v", srvcerrors.ErrInvalidInput, err)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("%w: invalid rate on line %d: %q", srvcerrors.ErrInvalidInput, line, record[1])
+		}
+
+		rates = append(rates, &model.ExchangeRate{
+			Currency: strings.ToUpper(strings.TrimSpace(record[0])),
+			Rate:     rate,
+		})
+	}
+
+	return rates, nil
+}
+
+func parseJSON(r io.Reader) ([]*model.ExchangeRate, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+
+	var list []*model.ExchangeRate
+	if err := json.Unmarshal(data, &list); err == nil {
+		for _, rate := range list {
+			if rate != nil {
+				rate.Currency = strings.ToUpper(strings.TrimSpace(rate.Currency))
+			}
+		}
+		return list, nil
+	}
+
+	var byCurrency map[string]float64
+	if err := json.Unmarshal(data, &byCurrency); err != nil {
+		return nil, fmt.Errorf("%w: exchange rates json must be a list of {currency, rate} or a {currency: rate} object", srvcerrors.ErrInvalidInput)
+	}
+
+	rates := make([]*model.ExchangeRate, 0, len(byCurrency))
+	for currency, rate := range byCurrency {
+		rates = append(rates, &model.ExchangeRate{
+			Currency: strings.ToUpper(strings.TrimSpace(currency)),
+			Rate:     rate,
+		})
+	}
+	return rates, nil
+}
@@ -0,0 +1,210 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type ExchangeProvider interface {
+	Rates() []*model.ExchangeRate
+	SetRates(context.Context, []*model.ExchangeRate) ([]*model.ExchangeRate, error)
+	Convert(float64, string, string) (float64, error)
+	ConvertOrder(*model.Order, string) (*model.Order, error)
+	ConvertItems([]*model.Item, string, string) ([]*model.Item, error)
+}
+
+type Service struct {
+	repo      repository.ExchangeRateRepositoryProvider
+	logger    logger.Logger
+	validator *validator.Validate
+
+	mu    sync.RWMutex
+	rates map[string]*model.ExchangeRate
+}
+
+func NewService(repo repository.ExchangeRateRepositoryProvider, logger logger.Logger) *Service {
+	return &Service{
+		repo:      repo,
+		logger:    logger,
+		validator: validator.New(),
+		rates:     make(map[string]*model.ExchangeRate),
+	}
+}
+
+func (s *Service) Load(ctx context.Context) error {
+	rates, err := s.repo.ListRates(ctx)
+	if err != nil {
+		return err
+	}
+	s.replace(rates)
+	s.logger.Info("exchange: rates loaded", zap.Int("count", len(rates)))
+	return nil
+}
+
+func (s *Service) LoadFile(ctx context.Context, path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open exchange rates file: %w", err)
+	}
+	defer file.Close()
+
+	rates, err := ParseRates(file, format)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.SetRates(ctx, rates); err != nil {
+		return err
+	}
+	return s.Load(ctx)
+}
+
+func (s *Service) SetRates(ctx context.Context, rates []*model.ExchangeRate) ([]*model.ExchangeRate, error) {
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: at least one exchange rate is required", srvcerrors.ErrInvalidInput)
+	}
+	for _, rate := range rates {
+		if rate == nil {
+			return nil, fmt.Errorf("%w: exchange rate must not be null", srvcerrors.ErrInvalidInput)
+		}
+		rate.Currency = strings.ToUpper(strings.TrimSpace(rate.Currency))
+		if err := s.validator.Struct(rate); err != nil {
+			return nil, fmt.Errorf("%w: invalid exchange rate %q: %v", srvcerrors.ErrInvalidInput, rate.Currency, err)
+		}
+	}
+
+	stored, err := s.repo.UpsertRates(ctx, rates)
+	if err != nil {
+		s.logger.Error("exchange: failed to store rates", zap.Error(err))
+		return nil, err
+	}
+
+	s.mu.Lock()
+	for _, rate := range stored {
+		s.rates[rate.Currency] = rate
+	}
+	s.mu.Unlock()
+
+	s.logger.Info("exchange: rates updated", zap.Int("count", len(stored)))
+	return stored, nil
+}
+
+func (s *Service) Rates() []*model.ExchangeRate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := make([]*model.ExchangeRate, 0, len(s.rates))
+	for _, rate := range s.rates {
+		copied := *rate
+		rates = append(rates, &copied)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Currency < rates[j].Currency
+	})
+	return rates
+}
+
+func (s *Service) Convert(amount float64, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return amount, nil
+	}
+
+	s.mu.RLock()
+	fromRate, fromOK := s.rates[from]
+	toRate, toOK := s.rates[to]
+	s.mu.RUnlock()
+
+	if !fromOK {
+		return 0, fmt.Errorf("%w: no exchange rate for currency %q", srvcerrors.ErrInvalidInput, from)
+	}
+	if !toOK {
+		return 0, fmt.Errorf("%w: no exchange rate for currency %q", srvcerrors.ErrInvalidInput, to)
+	}
+
+	return amount * fromRate.Rate / toRate.Rate, nil
+}
+
+func (s *Service) ConvertOrder(order *model.Order, to string) (*model.Order, error) {
+	converted := *order
+	from := order.Payment.Currency
+	converted.Payment.Currency = strings.ToUpper(to)
+
+	payment := &converted.Payment
+	for _, field := range []*int{&payment.Amount, &payment.DeliveryCost, &payment.GoodsTotal, &payment.CustomFee} {
+		value, err := s.convertInt(*field, from, to)
+		if err != nil {
+			return nil, err
+		}
+		*field = value
+	}
+
+	items, err := s.ConvertItems(order.Items, from, to)
+	if err != nil {
+		return nil, err
+	}
+	converted.Items = items
+
+	return &converted, nil
+}
+
+func (s *Service) ConvertItems(items []*model.Item, from, to string) ([]*model.Item, error) {
+	if items == nil {
+		return nil, nil
+	}
+
+	converted := make([]*model.Item, 0, len(items))
+	for _, item := range items {
+		copied := *item
+
+		price, err := s.convertInt(item.Price, from, to)
+		if err != nil {
+			return nil, err
+		}
+		totalPrice, err := s.convertInt(item.TotalPrice, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		copied.Price = price
+		copied.TotalPrice = totalPrice
+		converted = append(converted, &copied)
+	}
+	return converted, nil
+}
+
+func (s *Service) convertInt(amount int, from, to string) (int, error) {
+	value, err := s.Convert(float64(amount), from, to)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(value)), nil
+}
+
+func (s *Service) replace(rates []*model.ExchangeRate) {
+	byCurrency := make(map[string]*model.ExchangeRate, len(rates))
+	for _, rate := range rates {
+		byCurrency[rate.Currency] = rate
+	}
+
+	s.mu.Lock()
+	s.rates = byCurrency
+	s.mu.Unlock()
+}
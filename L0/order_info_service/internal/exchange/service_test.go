@@ -0,0 +1,140 @@
+package exchange_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/exchange"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	rates map[string]*model.ExchangeRate
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{rates: make(map[string]*model.ExchangeRate)}
+}
+
+func (f *fakeRepository) ListRates(context.Context) ([]*model.ExchangeRate, error) {
+	rates := make([]*model.ExchangeRate, 0, len(f.rates))
+	for _, rate := range f.rates {
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func (f *fakeRepository) UpsertRates(_ context.Context, rates []*model.ExchangeRate) ([]*model.ExchangeRate, error) {
+	for _, rate := range rates {
+		copied := *rate
+		f.rates[rate.Currency] = &copied
+	}
+	return rates, nil
+}
+
+func newServiceWithRates(t *testing.T) *exchange.Service {
+	svc := exchange.NewService(newFakeRepository(), &loggertest.MockLogger{})
+	_, err := svc.SetRates(context.Background(), []*model.ExchangeRate{
+		{Currency: "RUB", Rate: 1},
+		{Currency: "USD", Rate: 90},
+	})
+	require.NoError(t, err)
+	return svc
+}
+
+func TestParseRates_CSV(t *testing.T) {
+	rates, err := exchange.ParseRates(strings.NewReader("currency,rate\nusd, 90.5\nRUB,1\n"), exchange.FormatCSV)
+
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "USD", rates[0].Currency)
+	assert.Equal(t, 90.5, rates[0].Rate)
+}
+
+func TestParseRates_JSON(t *testing.T) {
+	list, err := exchange.ParseRates(strings.NewReader(`[{"currency":"usd","rate":90}]`), exchange.FormatJSON)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "USD", list[0].Currency)
+
+	object, err := exchange.ParseRates(strings.NewReader(`{"EUR": 100, "RUB": 1}`), exchange.FormatJSON)
+	require.NoError(t, err)
+	assert.Len(t, object, 2)
+}
+
+func TestParseRates_Invalid(t *testing.T) {
+	_, err := exchange.ParseRates(strings.NewReader("USD,abc\nRUB,x\n"), exchange.FormatCSV)
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+
+	_, err = exchange.ParseRates(strings.NewReader(`"rates"`), exchange.FormatJSON)
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+
+	_, err = exchange.FormatFromPath("rates.xml")
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+}
+
+func TestService_Convert(t *testing.T) {
+	svc := newServiceWithRates(t)
+
+	value, err := svc.Convert(180, "USD", "rub")
+	require.NoError(t, err)
+	assert.Equal(t, 16200.0, value)
+
+	value, err = svc.Convert(900, "RUB", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, value)
+
+	_, err = svc.Convert(1, "RUB", "EUR")
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+}
+
+func TestService_ConvertOrder(t *testing.T) {
+	svc := newServiceWithRates(t)
+	order := &model.Order{
+		OrderUID: "order-1",
+		Payment:  model.Payment{Currency: "RUB", Amount: 1817, DeliveryCost: 1500, GoodsTotal: 317},
+		Items:    []*model.Item{{ID: 1, Price: 453, TotalPrice: 317}},
+	}
+
+	converted, err := svc.ConvertOrder(order, "usd")
+
+	require.NoError(t, err)
+	assert.Equal(t, "USD", converted.Payment.Currency)
+	assert.Equal(t, 20, converted.Payment.Amount)
+	assert.Equal(t, 17, converted.Payment.DeliveryCost)
+	assert.Equal(t, 5, converted.Items[0].Price)
+	assert.Equal(t, "RUB", order.Payment.Currency)
+	assert.Equal(t, 453, order.Items[0].Price)
+}
+
+func TestService_SetRates_Invalid(t *testing.T) {
+	svc := exchange.NewService(newFakeRepository(), &loggertest.MockLogger{})
+
+	_, err := svc.SetRates(context.Background(), []*model.ExchangeRate{{Currency: "USD", Rate: 0}})
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+
+	_, err = svc.SetRates(context.Background(), []*model.ExchangeRate{{Currency: "DOLLAR", Rate: 1}})
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+
+	_, err = svc.SetRates(context.Background(), nil)
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	assert.Empty(t, svc.Rates())
+}
+
+func TestService_Load(t *testing.T) {
+	repo := newFakeRepository()
+	repo.rates["USD"] = &model.ExchangeRate{Currency: "USD", Rate: 90}
+	repo.rates["RUB"] = &model.ExchangeRate{Currency: "RUB", Rate: 1}
+
+	svc := exchange.NewService(repo, &loggertest.MockLogger{})
+	require.NoError(t, svc.Load(context.Background()))
+
+	rates := svc.Rates()
+	require.Len(t, rates, 2)
+	assert.Equal(t, "RUB", rates[0].Currency)
+}
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"go.uber.org/zap"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+const (
+	maxQueryDepth   = 10
+	maxRequestBytes = 64 << 10
+)
+
+type Handler struct {
+	ctrl   controller.ControllerProvider
+	logger logger.Logger
+	schema *graphql.Schema
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func NewHandler(ctrl controller.ControllerProvider, logger logger.Logger) *Handler {
+	schema := graphql.MustParseSchema(schemaSDL, &Resolver{ctrl: ctrl},
+		graphql.MaxDepth(maxQueryDepth),
+	)
+
+	return &Handler{
+		ctrl:   ctrl,
+		logger: logger,
+		schema: schema,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				http.Error(w, "invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		body := http.MaxBytesReader(w, r.Body, maxRequestBytes)
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if req.Query == "" {
+		http.Error(w, "query is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := withLoaders(r.Context(), newLoaders(h.ctrl))
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	for _, qErr := range resp.Errors {
+		h.sanitizeError(qErr)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("graphql: failed to write response", zap.Error(err))
+	}
+}
+
+func (h *Handler) sanitizeError(qErr *gqlerrors.QueryError) {
+	err := qErr.ResolverError
+	if err == nil {
+		return
+	}
+
+	code, message := "INTERNAL", "Internal server error"
+	switch {
+	case errors.Is(err, srvcerrors.ErrNotFound):
+		code, message = "NOT_FOUND", "Order not found"
+	case errors.Is(err, srvcerrors.ErrInvalidInput):
+		code, message = "BAD_REQUEST", "Invalid request parameters"
+	case errors.Is(err, srvcerrors.ErrDatabase):
+		code, message = "INTERNAL", "Database error"
+	}
+
+	if code == "INTERNAL" {
+		h.logger.Error("graphql: resolver failed",
+			zap.Any("path", qErr.Path),
+			zap.Error(err))
+	} else {
+		h.logger.Warn("graphql: client error",
+			zap.Any("path", qErr.Path),
+			zap.Error(err))
+	}
+
+	qErr.Message = message
+	qErr.Extensions = map[string]interface{}{"code": code}
+}
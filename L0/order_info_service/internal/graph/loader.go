@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+const loaderWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+type itemsKey struct {
+	OrderUID string
+	LastID   int
+	Limit    int
+}
+
+type pageKey struct {
+	LastID int
+	Limit  int
+}
+
+type loaders struct {
+	orders *dataloader.Loader[string, *model.Order]
+	items  *dataloader.Loader[itemsKey, []*model.Item]
+}
+
+// newLoaders creates request-scoped loaders: orders are fetched in one
+// controller call, and item pages requested by sibling orders are collected
+// into one controller call per (last_id, limit) pair.
+func newLoaders(ctrl controller.ControllerProvider) *loaders {
+	return &loaders{
+		orders: dataloader.NewBatchedLoader(
+			ordersBatchFunc(ctrl),
+			dataloader.WithWait[string, *model.Order](loaderWait),
+		),
+		items: dataloader.NewBatchedLoader(
+			itemsBatchFunc(ctrl),
+			dataloader.WithWait[itemsKey, []*model.Item](loaderWait),
+		),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
+
+func ordersBatchFunc(ctrl controller.ControllerProvider) dataloader.BatchFunc[string, *model.Order] {
+	return func(ctx context.Context, keys []string) []*dataloader.Result[*model.Order] {
+		results := make([]*dataloader.Result[*model.Order], len(keys))
+
+		orders, err := ctrl.GetOrdersByUIDs(ctx, keys)
+		for i, key := range keys {
+			switch order, ok := orders[key]; {
+			case err != nil:
+				results[i] = &dataloader.Result[*model.Order]{Error: err}
+			case !ok:
+				results[i] = &dataloader.Result[*model.Order]{
+					Error: fmt.Errorf("%w: order %s", srvcerrors.ErrNotFound, key),
+				}
+			default:
+				results[i] = &dataloader.Result[*model.Order]{Data: order}
+			}
+		}
+
+		return results
+	}
+}
+
+func itemsBatchFunc(ctrl controller.ControllerProvider) dataloader.BatchFunc[itemsKey, []*model.Item] {
+	return func(ctx context.Context, keys []itemsKey) []*dataloader.Result[[]*model.Item] {
+		results := make([]*dataloader.Result[[]*model.Item], len(keys))
+
+		groups := make(map[pageKey][]int)
+		for i, key := range keys {
+			page := pageKey{LastID: key.LastID, Limit: key.Limit}
+			groups[page] = append(groups[page], i)
+		}
+
+		for page, indexes := range groups {
+			orderIDs := make([]string, 0, len(indexes))
+			for _, i := range indexes {
+				orderIDs = append(orderIDs, keys[i].OrderUID)
+			}
+
+			items, err := ctrl.GetItemsByOrderUIDs(ctx, orderIDs, page.LastID, page.Limit)
+			for _, i := range indexes {
+				if err != nil {
+					results[i] = &dataloader.Result[[]*model.Item]{Error: err}
+					continue
+				}
+				results[i] = &dataloader.Result[[]*model.Item]{Data: items[keys[i].OrderUID]}
+			}
+		}
+
+		return results
+	}
+}
@@ -0,0 +1,249 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	defaultItemsLimit = 10
+	maxItemsLimit     = 100
+	cursorPrefix      = "item:"
+)
+
+type Resolver struct {
+	ctrl controller.ControllerProvider
+}
+
+func (r *Resolver) Order(ctx context.Context, args struct{ UID string }) (*orderResolver, error) {
+	if strings.TrimSpace(args.UID) == "" {
+		return nil, srvcerrors.ErrInvalidInput
+	}
+
+	order, err := r.ctrl.GetOrderByUID(ctx, args.UID)
+	if err != nil {
+		return nil, err
+	}
+	return &orderResolver{ctrl: r.ctrl, order: order}, nil
+}
+
+func (r *Resolver) Orders(ctx context.Context, args struct{ UIDs []string }) ([]*orderResolver, error) {
+	for _, uid := range args.UIDs {
+		if strings.TrimSpace(uid) == "" {
+			return nil, srvcerrors.ErrInvalidInput
+		}
+	}
+
+	fetched, err := r.loadOrders(ctx, args.UIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*orderResolver, 0, len(fetched))
+	for _, order := range fetched {
+		orders = append(orders, &orderResolver{ctrl: r.ctrl, order: order})
+	}
+	return orders, nil
+}
+
+func (r *Resolver) loadOrders(ctx context.Context, uids []string) ([]*model.Order, error) {
+	if l := loadersFromContext(ctx); l != nil {
+		orders, errs := l.orders.LoadMany(ctx, uids)()
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+		return orders, nil
+	}
+
+	fetched, err := r.ctrl.GetOrdersByUIDs(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]*model.Order, 0, len(uids))
+	for _, uid := range uids {
+		order, ok := fetched[uid]
+		if !ok {
+			return nil, fmt.Errorf("%w: order %s", srvcerrors.ErrNotFound, uid)
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+type orderResolver struct {
+	ctrl  controller.ControllerProvider
+	order *model.Order
+}
+
+func (o *orderResolver) OrderUid() string          { return o.order.OrderUID }
+func (o *orderResolver) TrackNumber() string       { return o.order.TrackNumber }
+func (o *orderResolver) Entry() string             { return o.order.Entry }
+func (o *orderResolver) Locale() string            { return o.order.Locale }
+func (o *orderResolver) InternalSignature() string { return o.order.InternalSignature }
+func (o *orderResolver) CustomerId() string        { return o.order.CustomerID }
+func (o *orderResolver) DeliveryService() string   { return o.order.DeliveryService }
+func (o *orderResolver) Shardkey() string          { return o.order.Shardkey }
+func (o *orderResolver) SmId() int32               { return int32(o.order.SmID) }
+func (o *orderResolver) OofShard() string          { return o.order.OofShard }
+
+func (o *orderResolver) DateCreated() graphql.Time {
+	return graphql.Time{Time: o.order.DateCreated}
+}
+
+func (o *orderResolver) Delivery() *deliveryResolver {
+	return &deliveryResolver{delivery: &o.order.Delivery}
+}
+
+func (o *orderResolver) Payment() *paymentResolver {
+	return &paymentResolver{payment: &o.order.Payment}
+}
+
+type itemsArgs struct {
+	First *int32
+	After *string
+}
+
+func (o *orderResolver) Items(ctx context.Context, args itemsArgs) (*itemConnectionResolver, error) {
+	limit := defaultItemsLimit
+	if args.First != nil {
+		if *args.First <= 0 {
+			return nil, srvcerrors.ErrInvalidInput
+		}
+		limit = min(int(*args.First), maxItemsLimit)
+	}
+
+	lastID := 0
+	if args.After != nil {
+		var err error
+		if lastID, err = decodeCursor(*args.After); err != nil {
+			return nil, err
+		}
+	}
+
+	// one extra item tells whether there is a next page
+	items, err := o.loadItems(ctx, lastID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNext := len(items) > limit
+	if hasNext {
+		items = items[:limit]
+	}
+	return &itemConnectionResolver{items: items, hasNext: hasNext}, nil
+}
+
+func (o *orderResolver) loadItems(ctx context.Context, lastID, limit int) ([]*model.Item, error) {
+	if l := loadersFromContext(ctx); l != nil {
+		return l.items.Load(ctx, itemsKey{OrderUID: o.order.OrderUID, LastID: lastID, Limit: limit})()
+	}
+	return o.ctrl.GetItemsByOrderUID(ctx, o.order.OrderUID, lastID, limit)
+}
+
+type deliveryResolver struct {
+	delivery *model.Delivery
+}
+
+func (d *deliveryResolver) Name() string    { return d.delivery.Name }
+func (d *deliveryResolver) Phone() string   { return d.delivery.Phone }
+func (d *deliveryResolver) Zip() string     { return d.delivery.Zip }
+func (d *deliveryResolver) City() string    { return d.delivery.City }
+func (d *deliveryResolver) Address() string { return d.delivery.Address }
+func (d *deliveryResolver) Region() string  { return d.delivery.Region }
+func (d *deliveryResolver) Email() string   { return d.delivery.Email }
+
+type paymentResolver struct {
+	payment *model.Payment
+}
+
+func (p *paymentResolver) Transaction() string { return p.payment.Transaction }
+func (p *paymentResolver) RequestId() string   { return p.payment.RequestID }
+func (p *paymentResolver) Currency() string    { return p.payment.Currency }
+func (p *paymentResolver) Provider() string    { return p.payment.Provider }
+func (p *paymentResolver) Amount() int32       { return int32(p.payment.Amount) }
+func (p *paymentResolver) PaymentDt() int32    { return int32(p.payment.PaymentDT) }
+func (p *paymentResolver) Bank() string        { return p.payment.Bank }
+func (p *paymentResolver) DeliveryCost() int32 { return int32(p.payment.DeliveryCost) }
+func (p *paymentResolver) GoodsTotal() int32   { return int32(p.payment.GoodsTotal) }
+func (p *paymentResolver) CustomFee() int32    { return int32(p.payment.CustomFee) }
+
+type itemResolver struct {
+	item *model.Item
+}
+
+func (i *itemResolver) Id() int32           { return int32(i.item.ID) }
+func (i *itemResolver) ChrtId() int32       { return int32(i.item.ChrtID) }
+func (i *itemResolver) TrackNumber() string { return i.item.TrackNumber }
+func (i *itemResolver) Price() int32        { return int32(i.item.Price) }
+func (i *itemResolver) Rid() string         { return i.item.RID }
+func (i *itemResolver) Name() string        { return i.item.Name }
+func (i *itemResolver) Sale() int32         { return int32(i.item.Sale) }
+func (i *itemResolver) Size() string        { return i.item.Size }
+func (i *itemResolver) TotalPrice() int32   { return int32(i.item.TotalPrice) }
+func (i *itemResolver) NmId() int32         { return int32(i.item.NmID) }
+func (i *itemResolver) Brand() string       { return i.item.Brand }
+func (i *itemResolver) Status() int32       { return int32(i.item.Status) }
+
+type itemConnectionResolver struct {
+	items   []*model.Item
+	hasNext bool
+}
+
+func (c *itemConnectionResolver) Edges() []*itemEdgeResolver {
+	edges := make([]*itemEdgeResolver, 0, len(c.items))
+	for _, item := range c.items {
+		edges = append(edges, &itemEdgeResolver{item: item})
+	}
+	return edges
+}
+
+func (c *itemConnectionResolver) PageInfo() *pageInfoResolver {
+	info := &pageInfoResolver{hasNext: c.hasNext}
+	if len(c.items) > 0 {
+		cursor := encodeCursor(c.items[len(c.items)-1].ID)
+		info.endCursor = &cursor
+	}
+	return info
+}
+
+type itemEdgeResolver struct {
+	item *model.Item
+}
+
+func (e *itemEdgeResolver) Cursor() string      { return encodeCursor(e.item.ID) }
+func (e *itemEdgeResolver) Node() *itemResolver { return &itemResolver{item: e.item} }
+
+type pageInfoResolver struct {
+	hasNext   bool
+	endCursor *string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool  { return p.hasNext }
+func (p *pageInfoResolver) EndCursor() *string { return p.endCursor }
+
+func encodeCursor(id int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed cursor", srvcerrors.ErrInvalidInput)
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) || id < 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", srvcerrors.ErrInvalidInput)
+	}
+	return id, nil
+}
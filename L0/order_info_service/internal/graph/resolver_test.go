@@ -0,0 +1,199 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/graph"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockController struct {
+	mock.Mock
+}
+
+func (m *MockController) GetOrderByUID(ctx context.Context, orderID string) (*model.Order, error) {
+	args := m.Called(ctx, orderID)
+	if order, ok := args.Get(0).(*model.Order); ok || args.Get(0) == nil {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockController) GetOrdersByUIDs(ctx context.Context, orderIDs []string) (map[string]*model.Order, error) {
+	args := m.Called(ctx, orderIDs)
+	if orders, ok := args.Get(0).(map[string]*model.Order); ok || args.Get(0) == nil {
+		return orders, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockController) GetItemsByOrderUID(ctx context.Context, orderID string, lastID, limit int) ([]*model.Item, error) {
+	args := m.Called(ctx, orderID, lastID, limit)
+	if items, ok := args.Get(0).([]*model.Item); ok || args.Get(0) == nil {
+		return items, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockController) GetItemsByOrderUIDs(ctx context.Context, orderIDs []string, lastID, limit int) (map[string][]*model.Item, error) {
+	args := m.Called(ctx, orderIDs, lastID, limit)
+	if items, ok := args.Get(0).(map[string][]*model.Item); ok || args.Get(0) == nil {
+		return items, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func execQuery(t *testing.T, ctrl *MockController, query string) gqlResponse {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(string(body)))
+	rec := httptest.NewRecorder()
+
+	graph.NewHandler(ctrl, &loggertest.MockLogger{}).ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp gqlResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestGraphQL_PaymentOnly(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(&model.Order{
+		OrderUID: "ORDER-001",
+		Payment:  model.Payment{Transaction: "ORDER-001", Currency: "RUB", Amount: 500},
+	}, nil)
+
+	resp := execQuery(t, mockCtrl, `{ order(uid: "ORDER-001") { payment { currency amount } } }`)
+
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"order":{"payment":{"currency":"RUB","amount":500}}}`, string(resp.Data))
+	mockCtrl.AssertNotCalled(t, "GetItemsByOrderUIDs")
+}
+
+func TestGraphQL_NestedItemsAreBatched(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrdersByUIDs", mock.Anything, []string{"A", "B", "C"}).Return(map[string]*model.Order{
+		"A": {OrderUID: "A"},
+		"B": {OrderUID: "B"},
+		"C": {OrderUID: "C"},
+	}, nil)
+	mockCtrl.On("GetItemsByOrderUIDs", mock.Anything, mock.Anything, 0, 3).Return(map[string][]*model.Item{
+		"A": {{ID: 1, OrderUID: "A"}, {ID: 2, OrderUID: "A"}, {ID: 3, OrderUID: "A"}},
+		"B": {{ID: 4, OrderUID: "B"}},
+	}, nil)
+
+	resp := execQuery(t, mockCtrl, `{
+		orders(uids: ["A", "B", "C"]) {
+			orderUid
+			items(first: 2) {
+				edges { node { id } }
+				pageInfo { hasNextPage }
+			}
+		}
+	}`)
+
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"orders":[
+		{"orderUid":"A","items":{"edges":[{"node":{"id":1}},{"node":{"id":2}}],"pageInfo":{"hasNextPage":true}}},
+		{"orderUid":"B","items":{"edges":[{"node":{"id":4}}],"pageInfo":{"hasNextPage":false}}},
+		{"orderUid":"C","items":{"edges":[],"pageInfo":{"hasNextPage":false}}}
+	]}`, string(resp.Data))
+
+	mockCtrl.AssertNumberOfCalls(t, "GetOrdersByUIDs", 1)
+	mockCtrl.AssertNotCalled(t, "GetOrderByUID")
+	mockCtrl.AssertNumberOfCalls(t, "GetItemsByOrderUIDs", 1)
+	mockCtrl.AssertNotCalled(t, "GetItemsByOrderUID")
+	uids := mockCtrl.Calls[len(mockCtrl.Calls)-1].Arguments.Get(1).([]string)
+	assert.ElementsMatch(t, []string{"A", "B", "C"}, uids)
+}
+
+func TestGraphQL_ItemsCursor(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "A").Return(&model.Order{OrderUID: "A"}, nil)
+	mockCtrl.On("GetItemsByOrderUIDs", mock.Anything, []string{"A"}, 0, 2).Return(map[string][]*model.Item{
+		"A": {{ID: 7, OrderUID: "A"}, {ID: 8, OrderUID: "A"}},
+	}, nil).Once()
+	mockCtrl.On("GetItemsByOrderUIDs", mock.Anything, []string{"A"}, 7, 2).Return(map[string][]*model.Item{
+		"A": {{ID: 8, OrderUID: "A"}},
+	}, nil).Once()
+
+	first := execQuery(t, mockCtrl, `{ order(uid: "A") { items(first: 1) { pageInfo { endCursor } } } }`)
+	require.Empty(t, first.Errors)
+
+	var page struct {
+		Order struct {
+			Items struct {
+				PageInfo struct {
+					EndCursor string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"items"`
+		} `json:"order"`
+	}
+	require.NoError(t, json.Unmarshal(first.Data, &page))
+	cursor := page.Order.Items.PageInfo.EndCursor
+	require.NotEmpty(t, cursor)
+
+	second := execQuery(t, mockCtrl, `{ order(uid: "A") { items(first: 1, after: "`+cursor+`") { edges { node { id } } } } }`)
+	require.Empty(t, second.Errors)
+	assert.JSONEq(t, `{"order":{"items":{"edges":[{"node":{"id":8}}]}}}`, string(second.Data))
+	mockCtrl.AssertExpectations(t)
+}
+
+func TestGraphQL_NotFound(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "missing").Return(nil, srvcerrors.ErrNotFound)
+
+	resp := execQuery(t, mockCtrl, `{ order(uid: "missing") { orderUid } }`)
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "Order not found", resp.Errors[0].Message)
+	assert.Equal(t, "NOT_FOUND", resp.Errors[0].Extensions["code"])
+}
+
+func TestGraphQL_OrdersMissingUID(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrdersByUIDs", mock.Anything, []string{"A", "missing"}).Return(map[string]*model.Order{
+		"A": {OrderUID: "A"},
+	}, nil)
+
+	resp := execQuery(t, mockCtrl, `{ orders(uids: ["A", "missing"]) { orderUid } }`)
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "NOT_FOUND", resp.Errors[0].Extensions["code"])
+	mockCtrl.AssertNumberOfCalls(t, "GetOrdersByUIDs", 1)
+}
+
+func TestGraphQL_BodyTooLarge(t *testing.T) {
+	query := `{ order(uid: "` + strings.Repeat("A", 128<<10) + `") { orderUid } }`
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(string(body)))
+	rec := httptest.NewRecorder()
+
+	graph.NewHandler(new(MockController), &loggertest.MockLogger{}).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
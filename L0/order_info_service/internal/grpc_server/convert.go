@@ -0,0 +1,77 @@
+package grpcserver
+
+import (
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/api/orderpb"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func orderToProto(o *model.Order) *orderpb.Order {
+	items := make([]*orderpb.Item, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, itemToProto(item))
+	}
+
+	return &orderpb.Order{
+		OrderUid:          o.OrderUID,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Delivery:          deliveryToProto(&o.Delivery),
+		Payment:           paymentToProto(&o.Payment),
+		Items:             items,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerId:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.Shardkey,
+		SmId:              int64(o.SmID),
+		DateCreated:       timestamppb.New(o.DateCreated),
+		OofShard:          o.OofShard,
+	}
+}
+
+func deliveryToProto(d *model.Delivery) *orderpb.Delivery {
+	return &orderpb.Delivery{
+		OrderUid: d.OrderUID,
+		Name:     d.Name,
+		Phone:    d.Phone,
+		Zip:      d.Zip,
+		City:     d.City,
+		Address:  d.Address,
+		Region:   d.Region,
+		Email:    d.Email,
+	}
+}
+
+func paymentToProto(p *model.Payment) *orderpb.Payment {
+	return &orderpb.Payment{
+		Transaction:  p.Transaction,
+		RequestId:    p.RequestID,
+		Currency:     p.Currency,
+		Provider:     p.Provider,
+		Amount:       int64(p.Amount),
+		PaymentDt:    int64(p.PaymentDT),
+		Bank:         p.Bank,
+		DeliveryCost: int64(p.DeliveryCost),
+		GoodsTotal:   int64(p.GoodsTotal),
+		CustomFee:    int64(p.CustomFee),
+	}
+}
+
+func itemToProto(i *model.Item) *orderpb.Item {
+	return &orderpb.Item{
+		OrderUid:    i.OrderUID,
+		Id:          int64(i.ID),
+		ChrtId:      int64(i.ChrtID),
+		TrackNumber: i.TrackNumber,
+		Price:       int64(i.Price),
+		Rid:         i.RID,
+		Name:        i.Name,
+		Sale:        int64(i.Sale),
+		Size:        i.Size,
+		TotalPrice:  int64(i.TotalPrice),
+		NmId:        int64(i.NmID),
+		Brand:       i.Brand,
+		Status:      int64(i.Status),
+	}
+}
@@ -0,0 +1,104 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func LoggingUnaryInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		logCall(log, info.FullMethod, time.Since(start), err)
+		return resp, err
+	}
+}
+
+func LoggingStreamInterceptor(log logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		logCall(log, info.FullMethod, time.Since(start), err)
+		return err
+	}
+}
+
+func ErrorUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+		return resp, nil
+	}
+}
+
+func ErrorStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return toStatusError(err)
+		}
+		return nil
+	}
+}
+
+func logCall(log logger.Logger, method string, duration time.Duration, err error) {
+	code := status.Code(toStatusError(err))
+
+	fields := []logger.Field{
+		zap.String("method", method),
+		zap.Duration("duration", duration),
+		zap.String("code", code.String()),
+	}
+
+	switch code {
+	case codes.OK:
+		log.Debug("grpc: request completed", fields...)
+	case codes.Internal, codes.Unknown, codes.Unavailable:
+		log.Error("grpc: request failed", append(fields, zap.Error(err))...)
+	default:
+		log.Warn("grpc: client error", append(fields, zap.Error(err))...)
+	}
+}
+
+func toStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, srvcerrors.ErrNotFound):
+		return status.Error(codes.NotFound, "order not found")
+	case errors.Is(err, srvcerrors.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, "invalid request parameters")
+	case errors.Is(err, srvcerrors.ErrDatabase):
+		return status.Error(codes.Internal, "database error")
+	case errors.Is(err, srvcerrors.ErrKafka):
+		return status.Error(codes.Internal, "kafka service error")
+	case errors.Is(err, srvcerrors.ErrUnavailable):
+		return status.Error(codes.Unavailable, "service temporarily unavailable")
+	case errors.Is(err, srvcerrors.ErrConflict):
+		return status.Error(codes.Aborted, "resource state conflict")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,185 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/pubsub"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/api/orderpb"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultItemsLimit = 10
+	maxItemsLimit     = 100
+)
+
+type Server struct {
+	orderpb.UnimplementedOrderServiceServer
+
+	ctrl          controller.ControllerProvider
+	hub           *pubsub.Hub
+	logger        logger.Logger
+	watchInterval time.Duration
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewServer(ctrl controller.ControllerProvider, hub *pubsub.Hub, logger logger.Logger, watchInterval time.Duration) *Server {
+	return &Server{
+		ctrl:          ctrl,
+		hub:           hub,
+		logger:        logger,
+		watchInterval: watchInterval,
+		done:          make(chan struct{}),
+	}
+}
+
+// Shutdown ends every open WatchOrder stream. It must be called before
+// GracefulStop, which otherwise waits for watchers to hang up on their own.
+func (s *Server) Shutdown() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func NewGRPCServer(srv *Server, logger logger.Logger) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			LoggingUnaryInterceptor(logger),
+			ErrorUnaryInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			LoggingStreamInterceptor(logger),
+			ErrorStreamInterceptor(),
+		),
+	)
+	orderpb.RegisterOrderServiceServer(s, srv)
+	return s
+}
+
+func (s *Server) GetOrder(ctx context.Context, req *orderpb.GetOrderRequest) (*orderpb.Order, error) {
+	orderID := req.GetOrderUid()
+	if strings.TrimSpace(orderID) == "" {
+		return nil, srvcerrors.ErrInvalidInput
+	}
+
+	order, err := s.ctrl.GetOrderByUID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderToProto(order), nil
+}
+
+func (s *Server) ListOrderItems(ctx context.Context, req *orderpb.ListOrderItemsRequest) (*orderpb.ListOrderItemsResponse, error) {
+	orderID := req.GetOrderUid()
+	if strings.TrimSpace(orderID) == "" {
+		return nil, srvcerrors.ErrInvalidInput
+	}
+
+	limit := int(req.GetLimit())
+	if limit < 0 {
+		return nil, srvcerrors.ErrInvalidInput
+	}
+	if limit == 0 {
+		limit = defaultItemsLimit
+	}
+	limit = min(limit, maxItemsLimit)
+
+	lastID := int(req.GetLastId())
+	if lastID < 0 {
+		return nil, srvcerrors.ErrInvalidInput
+	}
+
+	items, err := s.ctrl.GetItemsByOrderUID(ctx, orderID, lastID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &orderpb.ListOrderItemsResponse{
+		Items:      make([]*orderpb.Item, 0, len(items)),
+		NextLastId: int64(lastID),
+		HasMore:    len(items) == limit,
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, itemToProto(item))
+	}
+	if len(items) > 0 {
+		resp.NextLastId = int64(items[len(items)-1].ID)
+	}
+
+	return resp, nil
+}
+
+func (s *Server) WatchOrder(req *orderpb.WatchOrderRequest, stream grpc.ServerStreamingServer[orderpb.Order]) error {
+	orderID := req.GetOrderUid()
+	if strings.TrimSpace(orderID) == "" {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	ctx := stream.Context()
+
+	// the hub only sees updates consumed by this instance, so polling stays
+	// as a fallback for updates ingested by other replicas
+	var updates <-chan *model.Order
+	if s.hub != nil {
+		sub := s.hub.Subscribe(orderID)
+		defer sub.Close()
+		updates = sub.Updates()
+	}
+
+	order, err := s.ctrl.GetOrderByUID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	last := orderToProto(order)
+	if err := stream.Send(last); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return nil
+		case _, ok := <-updates:
+			if !ok {
+				return nil
+			}
+		case <-ticker.C:
+		}
+
+		order, err := s.ctrl.GetOrderByUID(ctx, orderID)
+		if err != nil {
+			s.logger.Warn("grpc: failed to refresh watched order",
+				zap.String("order_uid", orderID),
+				zap.Error(err))
+			continue
+		}
+
+		current := orderToProto(order)
+		if proto.Equal(last, current) {
+			continue
+		}
+
+		if err := stream.Send(current); err != nil {
+			return err
+		}
+		last = current
+	}
+}
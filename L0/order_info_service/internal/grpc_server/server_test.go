@@ -0,0 +1,258 @@
+package grpcserver_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	grpcserver "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/grpc_server"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/pubsub"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/api/orderpb"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type MockController struct {
+	mock.Mock
+}
+
+func (m *MockController) GetOrderByUID(ctx context.Context, orderID string) (*model.Order, error) {
+	args := m.Called(ctx, orderID)
+	if order, ok := args.Get(0).(*model.Order); ok || args.Get(0) == nil {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockController) GetOrdersByUIDs(ctx context.Context, orderIDs []string) (map[string]*model.Order, error) {
+	args := m.Called(ctx, orderIDs)
+	if orders, ok := args.Get(0).(map[string]*model.Order); ok || args.Get(0) == nil {
+		return orders, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockController) GetItemsByOrderUID(ctx context.Context, orderID string, lastID, limit int) ([]*model.Item, error) {
+	args := m.Called(ctx, orderID, lastID, limit)
+	if items, ok := args.Get(0).([]*model.Item); ok || args.Get(0) == nil {
+		return items, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockController) GetItemsByOrderUIDs(ctx context.Context, orderIDs []string, lastID, limit int) (map[string][]*model.Item, error) {
+	args := m.Called(ctx, orderIDs, lastID, limit)
+	if items, ok := args.Get(0).(map[string][]*model.Item); ok || args.Get(0) == nil {
+		return items, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func newTestClient(t *testing.T, ctrl *MockController) orderpb.OrderServiceClient {
+	t.Helper()
+	return newTestClientWithHub(t, ctrl, nil, 10*time.Millisecond)
+}
+
+func newTestClientWithHub(t *testing.T, ctrl *MockController, hub *pubsub.Hub, watchInterval time.Duration) orderpb.OrderServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpcserver.NewGRPCServer(grpcserver.NewServer(ctrl, hub, &loggertest.MockLogger{}, watchInterval), &loggertest.MockLogger{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return orderpb.NewOrderServiceClient(conn)
+}
+
+func TestGRPC_GetOrder_Success(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").
+		Return(&model.Order{OrderUID: "ORDER-001", TrackNumber: "TRK-1"}, nil)
+
+	client := newTestClient(t, mockCtrl)
+
+	order, err := client.GetOrder(context.Background(), &orderpb.GetOrderRequest{OrderUid: "ORDER-001"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ORDER-001", order.GetOrderUid())
+	assert.Equal(t, "TRK-1", order.GetTrackNumber())
+	mockCtrl.AssertExpectations(t)
+}
+
+func TestGRPC_GetOrder_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"not found", srvcerrors.ErrNotFound, codes.NotFound},
+		{"database", srvcerrors.ErrDatabase, codes.Internal},
+		{"unavailable", srvcerrors.ErrUnavailable, codes.Unavailable},
+		{"conflict", srvcerrors.ErrConflict, codes.Aborted},
+		{"invalid input", srvcerrors.ErrInvalidInput, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := new(MockController)
+			mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(nil, tt.err)
+
+			client := newTestClient(t, mockCtrl)
+
+			_, err := client.GetOrder(context.Background(), &orderpb.GetOrderRequest{OrderUid: "ORDER-001"})
+
+			require.Error(t, err)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestGRPC_GetOrder_EmptyUID(t *testing.T) {
+	mockCtrl := new(MockController)
+	client := newTestClient(t, mockCtrl)
+
+	_, err := client.GetOrder(context.Background(), &orderpb.GetOrderRequest{OrderUid: " "})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockCtrl.AssertNotCalled(t, "GetOrderByUID")
+}
+
+func TestGRPC_ListOrderItems_Success(t *testing.T) {
+	mockCtrl := new(MockController)
+	items := []*model.Item{
+		{ID: 3, ChrtID: 1},
+		{ID: 4, ChrtID: 2},
+	}
+	mockCtrl.On("GetItemsByOrderUID", mock.Anything, "ORDER-001", 2, 2).Return(items, nil)
+
+	client := newTestClient(t, mockCtrl)
+
+	resp, err := client.ListOrderItems(context.Background(), &orderpb.ListOrderItemsRequest{
+		OrderUid: "ORDER-001",
+		LastId:   2,
+		Limit:    2,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.GetItems(), 2)
+	assert.Equal(t, int64(4), resp.GetNextLastId())
+	assert.True(t, resp.GetHasMore())
+	mockCtrl.AssertExpectations(t)
+}
+
+func TestGRPC_WatchOrder_SendsUpdates(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").
+		Return(&model.Order{OrderUID: "ORDER-001", TrackNumber: "TRK-1"}, nil).Once()
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").
+		Return(&model.Order{OrderUID: "ORDER-001", TrackNumber: "TRK-2"}, nil)
+
+	client := newTestClient(t, mockCtrl)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	stream, err := client.WatchOrder(ctx, &orderpb.WatchOrderRequest{OrderUid: "ORDER-001"})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "TRK-1", first.GetTrackNumber())
+
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "TRK-2", second.GetTrackNumber())
+}
+
+func TestGRPC_WatchOrder_HubNotification(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").
+		Return(&model.Order{OrderUID: "ORDER-001", TrackNumber: "TRK-1"}, nil).Once()
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").
+		Return(&model.Order{OrderUID: "ORDER-001", TrackNumber: "TRK-2"}, nil)
+
+	hub := pubsub.NewHub(pubsub.DefaultBufferSize)
+	client := newTestClientWithHub(t, mockCtrl, hub, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	stream, err := client.WatchOrder(ctx, &orderpb.WatchOrderRequest{OrderUid: "ORDER-001"})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "TRK-1", first.GetTrackNumber())
+
+	hub.Publish(&model.Order{OrderUID: "ORDER-001", TrackNumber: "TRK-2"})
+
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "TRK-2", second.GetTrackNumber())
+}
+
+func TestGRPC_WatchOrder_EndsOnShutdown(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").
+		Return(&model.Order{OrderUID: "ORDER-001", TrackNumber: "TRK-1"}, nil)
+
+	lis := bufconn.Listen(1024 * 1024)
+	orderSrv := grpcserver.NewServer(mockCtrl, nil, &loggertest.MockLogger{}, time.Hour)
+	srv := grpcserver.NewGRPCServer(orderSrv, &loggertest.MockLogger{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	stream, err := orderpb.NewOrderServiceClient(conn).WatchOrder(ctx, &orderpb.WatchOrderRequest{OrderUid: "ORDER-001"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	orderSrv.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		t.Fatal("GracefulStop waited for the open watch stream")
+	}
+
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/admin"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/labstack/echo/v4"
+)
+
+// adminAuth fails closed: without a configured token every admin request is
+// rejected.
+func (h *Handler) adminAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if !ok || h.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="admin"`)
+			return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+		}
+
+		return next(c)
+	}
+}
+
+func (h *Handler) getCacheStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.admin.CacheStats())
+}
+
+func (h *Handler) evictCachedOrder(c echo.Context) error {
+	orderID := c.Param("order_uid")
+	if strings.TrimSpace(orderID) == "" {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	if err := h.admin.EvictOrder(orderID); err != nil {
+		if errors.Is(err, srvcerrors.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Order not found in cache")
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) clearCache(c echo.Context) error {
+	h.admin.ClearCache()
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) warmUpCache(c echo.Context) error {
+	limit := h.warmUpLimit
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			return srvcerrors.ErrInvalidInput
+		}
+	}
+
+	progress, err := h.admin.WarmUpCache(limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, progress)
+}
+
+func (h *Handler) getWarmUpProgress(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.admin.WarmUpProgress())
+}
+
+func (h *Handler) getConsumerStatus(c echo.Context) error {
+	status, err := h.admin.ConsumerStatus()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+func (h *Handler) pauseConsumer(c echo.Context) error {
+	status, err := h.admin.PauseConsumer()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+func (h *Handler) resumeConsumer(c echo.Context) error {
+	status, err := h.admin.ResumeConsumer()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+func (h *Handler) seekConsumer(c echo.Context) error {
+	var req admin.SeekRequest
+	if err := c.Bind(&req); err != nil {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	result, err := h.admin.SeekConsumer(&req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) getLogLevel(c echo.Context) error {
+	level, err := h.admin.LogLevel()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, level)
+}
+
+func (h *Handler) setLogLevel(c echo.Context) error {
+	var req admin.LogLevel
+	if err := c.Bind(&req); err != nil {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	level, err := h.admin.SetLogLevel(&req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, level)
+}
@@ -0,0 +1,251 @@
+package handler_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/admin"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/warmup"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAdmin struct {
+	mock.Mock
+}
+
+func (m *MockAdmin) CacheStats() cache.Stats {
+	args := m.Called()
+	return args.Get(0).(cache.Stats)
+}
+
+func (m *MockAdmin) EvictOrder(orderUID string) error {
+	args := m.Called(orderUID)
+	return args.Error(0)
+}
+
+func (m *MockAdmin) ClearCache() {
+	m.Called()
+}
+
+func (m *MockAdmin) WarmUpCache(limit int) (warmup.Progress, error) {
+	args := m.Called(limit)
+	return args.Get(0).(warmup.Progress), args.Error(1)
+}
+
+func (m *MockAdmin) WarmUpProgress() warmup.Progress {
+	args := m.Called()
+	return args.Get(0).(warmup.Progress)
+}
+
+func (m *MockAdmin) ConsumerStatus() (*admin.ConsumerStatus, error) {
+	args := m.Called()
+	status, _ := args.Get(0).(*admin.ConsumerStatus)
+	return status, args.Error(1)
+}
+
+func (m *MockAdmin) PauseConsumer() (*admin.ConsumerStatus, error) {
+	args := m.Called()
+	status, _ := args.Get(0).(*admin.ConsumerStatus)
+	return status, args.Error(1)
+}
+
+func (m *MockAdmin) ResumeConsumer() (*admin.ConsumerStatus, error) {
+	args := m.Called()
+	status, _ := args.Get(0).(*admin.ConsumerStatus)
+	return status, args.Error(1)
+}
+
+func (m *MockAdmin) SeekConsumer(req *admin.SeekRequest) (*admin.SeekResult, error) {
+	args := m.Called(req)
+	result, _ := args.Get(0).(*admin.SeekResult)
+	return result, args.Error(1)
+}
+
+func (m *MockAdmin) LogLevel() (*admin.LogLevel, error) {
+	args := m.Called()
+	level, _ := args.Get(0).(*admin.LogLevel)
+	return level, args.Error(1)
+}
+
+func (m *MockAdmin) SetLogLevel(req *admin.LogLevel) (*admin.LogLevel, error) {
+	args := m.Called(req)
+	level, _ := args.Get(0).(*admin.LogLevel)
+	return level, args.Error(1)
+}
+
+const testAdminToken = "secret"
+
+// newAdminRequest builds an admin API request carrying testAdminToken.
+func newAdminRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+testAdminToken)
+	return req
+}
+
+func TestHandler_Admin_RequiresToken(t *testing.T) {
+	mockAdmin := new(MockAdmin)
+	mockAdmin.On("CacheStats").Return(cache.Stats{Orders: 3, Hits: 5})
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{},
+		handler.WithAdmin(mockAdmin),
+		handler.WithAdminToken(testAdminToken),
+	)
+
+	for _, auth := range []string{"", "Bearer wrong", "secret"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/cache", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, auth)
+	}
+	mockAdmin.AssertNotCalled(t, "CacheStats")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/cache", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"orders":3`)
+	assert.Contains(t, rec.Body.String(), `"hits":5`)
+}
+
+func TestHandler_Admin_RejectsWithoutConfiguredToken(t *testing.T) {
+	mockAdmin := new(MockAdmin)
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAdmin(mockAdmin))
+
+	for _, auth := range []string{"", "Bearer ", "Bearer secret"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/cache", nil)
+		if auth != "" {
+			req.Header.Set(echo.HeaderAuthorization, auth)
+		}
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, auth)
+	}
+	mockAdmin.AssertNotCalled(t, "CacheStats")
+}
+
+func TestHandler_Admin_EvictCachedOrder(t *testing.T) {
+	mockAdmin := new(MockAdmin)
+	mockAdmin.On("EvictOrder", "order-1").Return(nil)
+	mockAdmin.On("EvictOrder", "order-2").Return(srvcerrors.ErrNotFound)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAdmin(mockAdmin), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodDelete, "/api/admin/cache/order-1", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = newAdminRequest(http.MethodDelete, "/api/admin/cache/order-2", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Order not found in cache")
+}
+
+func TestHandler_Admin_WarmUpCache(t *testing.T) {
+	mockAdmin := new(MockAdmin)
+	mockAdmin.On("WarmUpCache", 500).Return(warmup.Progress{State: warmup.StateRunning, Limit: 500}, nil)
+	mockAdmin.On("WarmUpProgress").Return(warmup.Progress{State: warmup.StateRunning, Limit: 500, Loaded: 200})
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAdmin(mockAdmin), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPost, "/api/admin/cache/warmup?limit=500", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"state":"running"`)
+
+	req = newAdminRequest(http.MethodGet, "/api/admin/cache/warmup", nil)
+	rec = httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"loaded":200`)
+	mockAdmin.AssertExpectations(t)
+}
+
+func TestHandler_Admin_PauseConsumer(t *testing.T) {
+	mockAdmin := new(MockAdmin)
+	mockAdmin.On("PauseConsumer").Return(&admin.ConsumerStatus{Paused: true}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAdmin(mockAdmin), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPost, "/api/admin/consumer/pause", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"paused":true`)
+}
+
+func TestHandler_Admin_SeekConsumer(t *testing.T) {
+	mockAdmin := new(MockAdmin)
+	mockAdmin.On("SeekConsumer", mock.MatchedBy(func(req *admin.SeekRequest) bool {
+		return req.Partition == 2 && req.Offset != nil && *req.Offset == 100 && req.Timestamp == nil
+	})).Return(&admin.SeekResult{Partition: 2, Offset: 100}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAdmin(mockAdmin), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPost, "/api/admin/consumer/seek", strings.NewReader(`{"partition":2,"offset":100}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"offset":100`)
+	mockAdmin.AssertExpectations(t)
+}
+
+func TestHandler_Admin_SetLogLevel(t *testing.T) {
+	mockAdmin := new(MockAdmin)
+	mockAdmin.On("SetLogLevel", &admin.LogLevel{Level: "debug"}).Return(&admin.LogLevel{Level: "debug"}, nil)
+	mockAdmin.On("SetLogLevel", &admin.LogLevel{Level: "loud"}).Return(nil, srvcerrors.ErrInvalidInput)
+	mockAdmin.On("LogLevel").Return(&admin.LogLevel{Level: "debug"}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAdmin(mockAdmin), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"level":"debug"`)
+
+	req = newAdminRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(`{"level":"loud"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = newAdminRequest(http.MethodGet, "/api/admin/log-level", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"level":"debug"`)
+	mockAdmin.AssertExpectations(t)
+}
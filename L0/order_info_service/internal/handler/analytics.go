@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/labstack/echo/v4"
+)
+
+const analyticsDateLayout = "2006-01-02"
+
+func (h *Handler) getRevenue(c echo.Context) error {
+	q, err := parseAnalyticsQuery(c)
+	if err != nil {
+		return err
+	}
+
+	points, err := h.analytics.Revenue(c.Request().Context(), q)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, points)
+}
+
+func (h *Handler) getOrderCounts(c echo.Context) error {
+	q, err := parseAnalyticsQuery(c)
+	if err != nil {
+		return err
+	}
+
+	groupBy := c.QueryParam("group_by")
+	if groupBy == "" {
+		groupBy = model.GroupByDeliveryService
+	}
+
+	points, err := h.analytics.OrderCounts(c.Request().Context(), groupBy, q)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, points)
+}
+
+func (h *Handler) getTopBrands(c echo.Context) error {
+	q, err := parseAnalyticsQuery(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.analytics.TopBrands(c.Request().Context(), q)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+func (h *Handler) getTopProducts(c echo.Context) error {
+	q, err := parseAnalyticsQuery(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.analytics.TopProducts(c.Request().Context(), q)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+func (h *Handler) getBasketSize(c echo.Context) error {
+	q, err := parseAnalyticsQuery(c)
+	if err != nil {
+		return err
+	}
+
+	points, err := h.analytics.BasketSize(c.Request().Context(), q)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, points)
+}
+
+func parseAnalyticsQuery(c echo.Context) (model.AnalyticsQuery, error) {
+	var q model.AnalyticsQuery
+	var err error
+
+	if q.From, err = parseAnalyticsTime(c.QueryParam("from")); err != nil {
+		return q, srvcerrors.ErrInvalidInput
+	}
+	if q.To, err = parseAnalyticsTime(c.QueryParam("to")); err != nil {
+		return q, srvcerrors.ErrInvalidInput
+	}
+
+	q.Bucket = c.QueryParam("bucket")
+	q.Currency = c.QueryParam("currency")
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if q.Limit, err = strconv.Atoi(limitStr); err != nil || q.Limit <= 0 {
+			return q, srvcerrors.ErrInvalidInput
+		}
+	}
+
+	return q, nil
+}
+
+func parseAnalyticsTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(analyticsDateLayout, value)
+}
@@ -0,0 +1,121 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAnalytics struct {
+	mock.Mock
+}
+
+func (m *MockAnalytics) Revenue(ctx context.Context, q model.AnalyticsQuery) ([]*model.RevenuePoint, error) {
+	args := m.Called(ctx, q)
+	points, _ := args.Get(0).([]*model.RevenuePoint)
+	return points, args.Error(1)
+}
+
+func (m *MockAnalytics) OrderCounts(ctx context.Context, groupBy string, q model.AnalyticsQuery) ([]*model.OrderCountPoint, error) {
+	args := m.Called(ctx, groupBy, q)
+	points, _ := args.Get(0).([]*model.OrderCountPoint)
+	return points, args.Error(1)
+}
+
+func (m *MockAnalytics) TopBrands(ctx context.Context, q model.AnalyticsQuery) ([]*model.BrandStat, error) {
+	args := m.Called(ctx, q)
+	stats, _ := args.Get(0).([]*model.BrandStat)
+	return stats, args.Error(1)
+}
+
+func (m *MockAnalytics) TopProducts(ctx context.Context, q model.AnalyticsQuery) ([]*model.ProductStat, error) {
+	args := m.Called(ctx, q)
+	stats, _ := args.Get(0).([]*model.ProductStat)
+	return stats, args.Error(1)
+}
+
+func (m *MockAnalytics) BasketSize(ctx context.Context, q model.AnalyticsQuery) ([]*model.BasketPoint, error) {
+	args := m.Called(ctx, q)
+	points, _ := args.Get(0).([]*model.BasketPoint)
+	return points, args.Error(1)
+}
+
+func TestHandler_GetRevenue(t *testing.T) {
+	mockAnalytics := new(MockAnalytics)
+	mockAnalytics.On("Revenue", mock.Anything, mock.MatchedBy(func(q model.AnalyticsQuery) bool {
+		return q.Bucket == model.BucketWeek &&
+			q.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+			q.To.Equal(time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC))
+	})).Return([]*model.RevenuePoint{{Currency: "USD", Revenue: 1817, Orders: 1}}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAnalytics(mockAnalytics))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/revenue?from=2024-01-01&to=2024-02-01T12:00:00Z&bucket=week", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"revenue":1817`)
+	mockAnalytics.AssertExpectations(t)
+}
+
+func TestHandler_GetOrderCounts_DefaultGroupBy(t *testing.T) {
+	mockAnalytics := new(MockAnalytics)
+	mockAnalytics.On("OrderCounts", mock.Anything, model.GroupByDeliveryService, mock.Anything).
+		Return([]*model.OrderCountPoint{{Key: "meest", Orders: 3}}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAnalytics(mockAnalytics))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/orders", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"key":"meest"`)
+	mockAnalytics.AssertExpectations(t)
+}
+
+func TestHandler_Analytics_InvalidParams(t *testing.T) {
+	mockAnalytics := new(MockAnalytics)
+	mockAnalytics.On("TopBrands", mock.Anything, mock.Anything).Return(nil, srvcerrors.ErrInvalidInput)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithAnalytics(mockAnalytics))
+
+	for _, target := range []string{
+		"/api/analytics/top-products?from=yesterday",
+		"/api/analytics/basket?limit=-1",
+		"/api/analytics/top-brands?bucket=year",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+	mockAnalytics.AssertNotCalled(t, "TopProducts")
+	mockAnalytics.AssertNotCalled(t, "BasketSize")
+}
+
+func TestHandler_Analytics_Disabled(t *testing.T) {
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/revenue", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
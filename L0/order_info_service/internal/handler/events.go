@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	eventsHeartbeatInterval = 15 * time.Second
+	wsWriteTimeout          = 10 * time.Second
+	wsPongTimeout           = 2 * eventsHeartbeatInterval
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+func (h *Handler) streamOrderEvents(c echo.Context) error {
+	orderID := c.Param("order_uid")
+	if strings.TrimSpace(orderID) == "" {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	sub := h.hub.Subscribe(orderID)
+	defer sub.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprint(res, ": subscribed\n\n"); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case order, ok := <-sub.Updates():
+			if !ok {
+				return nil
+			}
+
+			data, err := json.Marshal(order)
+			if err != nil {
+				h.logger.Error("handler: failed to marshal order event",
+					zap.String("order_uid", orderID),
+					zap.Error(err))
+				continue
+			}
+
+			if _, err := fmt.Fprintf(res, "event: order\ndata: %s\n\n", data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+func (h *Handler) websocketOrderEvents(c echo.Context) error {
+	orderID := c.Param("order_uid")
+	if strings.TrimSpace(orderID) == "" {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		h.logger.Warn("handler: websocket upgrade failed",
+			zap.String("order_uid", orderID),
+			zap.Error(err))
+		return nil
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(orderID)
+	defer sub.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return nil
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return nil
+			}
+		case order, ok := <-sub.Updates():
+			if !ok {
+				return nil
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(order); err != nil {
+				h.logger.Warn("handler: failed to write websocket event",
+					zap.String("order_uid", orderID),
+					zap.Error(err))
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/exchange"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) listExchangeRates(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.exchange.Rates())
+}
+
+func (h *Handler) setExchangeRates(c echo.Context) error {
+	format := exchange.FormatJSON
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		format = exchange.FormatCSV
+	}
+
+	rates, err := exchange.ParseRates(c.Request().Body, format)
+	if err != nil {
+		return err
+	}
+
+	stored, err := h.exchange.SetRates(c.Request().Context(), rates)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, stored)
+}
+
+func (h *Handler) convertItems(c echo.Context, orderID string, items []*model.Item, currency string) ([]*model.Item, error) {
+	if h.exchange == nil {
+		return nil, srvcerrors.ErrInvalidInput
+	}
+
+	order, err := h.ctrl.GetOrderByUID(c.Request().Context(), orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.exchange.ConvertItems(items, order.Payment.Currency, currency)
+}
@@ -0,0 +1,148 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockExchange struct {
+	mock.Mock
+}
+
+func (m *MockExchange) Rates() []*model.ExchangeRate {
+	rates, _ := m.Called().Get(0).([]*model.ExchangeRate)
+	return rates
+}
+
+func (m *MockExchange) SetRates(ctx context.Context, rates []*model.ExchangeRate) ([]*model.ExchangeRate, error) {
+	args := m.Called(ctx, rates)
+	stored, _ := args.Get(0).([]*model.ExchangeRate)
+	return stored, args.Error(1)
+}
+
+func (m *MockExchange) Convert(amount float64, from, to string) (float64, error) {
+	args := m.Called(amount, from, to)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockExchange) ConvertOrder(order *model.Order, to string) (*model.Order, error) {
+	args := m.Called(order, to)
+	converted, _ := args.Get(0).(*model.Order)
+	return converted, args.Error(1)
+}
+
+func (m *MockExchange) ConvertItems(items []*model.Item, from, to string) ([]*model.Item, error) {
+	args := m.Called(items, from, to)
+	converted, _ := args.Get(0).([]*model.Item)
+	return converted, args.Error(1)
+}
+
+func TestHandler_GetOrder_WithCurrency(t *testing.T) {
+	mockCtrl := new(MockController)
+	order := generateTestOrder("ORDER-001")
+	order.Payment = model.Payment{Currency: "RUB", Amount: 9000}
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(order, nil)
+
+	mockExchange := new(MockExchange)
+	mockExchange.On("ConvertOrder", order, "USD").Return(&model.Order{
+		OrderUID: "ORDER-001",
+		Payment:  model.Payment{Currency: "USD", Amount: 100},
+	}, nil)
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{}, handler.WithExchange(mockExchange))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001?currency=USD", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"currency":"USD"`)
+	assert.Contains(t, rec.Body.String(), `"amount":100`)
+	mockExchange.AssertExpectations(t)
+}
+
+func TestHandler_GetOrderItems_WithCurrency(t *testing.T) {
+	mockCtrl := new(MockController)
+	order := generateTestOrder("ORDER-001")
+	order.Payment = model.Payment{Currency: "RUB"}
+	items := []*model.Item{{ID: 1, Price: 900}}
+	mockCtrl.On("GetItemsByOrderUID", mock.Anything, "ORDER-001", 0, 10).Return(items, nil)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(order, nil)
+
+	mockExchange := new(MockExchange)
+	mockExchange.On("ConvertItems", items, "RUB", "USD").Return([]*model.Item{{ID: 1, Price: 10}}, nil)
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{}, handler.WithExchange(mockExchange))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001/items?currency=USD", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"price":10`)
+	mockExchange.AssertExpectations(t)
+}
+
+func TestHandler_GetOrder_UnknownCurrency(t *testing.T) {
+	mockCtrl := new(MockController)
+	order := generateTestOrder("ORDER-001")
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(order, nil)
+
+	mockExchange := new(MockExchange)
+	mockExchange.On("ConvertOrder", order, "EUR").Return(nil, srvcerrors.ErrInvalidInput)
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{}, handler.WithExchange(mockExchange))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001?currency=EUR", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandler_SetExchangeRates_CSV(t *testing.T) {
+	mockExchange := new(MockExchange)
+	mockExchange.On("SetRates", mock.Anything, mock.MatchedBy(func(rates []*model.ExchangeRate) bool {
+		return len(rates) == 2 && rates[0].Currency == "USD" && rates[0].Rate == 90
+	})).Return([]*model.ExchangeRate{{Currency: "USD", Rate: 90}, {Currency: "RUB", Rate: 1}}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithExchange(mockExchange), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPut, "/api/admin/exchange-rates", strings.NewReader("currency,rate\nUSD,90\nRUB,1\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"currency":"USD"`)
+	mockExchange.AssertExpectations(t)
+}
+
+func TestHandler_SetExchangeRates_InvalidJSON(t *testing.T) {
+	mockExchange := new(MockExchange)
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithExchange(mockExchange), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPut, "/api/admin/exchange-rates", strings.NewReader(`not json`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockExchange.AssertNotCalled(t, "SetRates")
+}
@@ -1,49 +1,192 @@
 package handler
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/admin"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/analytics"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/exchange"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/graph"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/pubsub"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/quarantine"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/search"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/webhook"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+const tracerName = "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+
+const (
+	DefaultMaxItemPage = 100
+	DefaultWarmUpLimit = 100
+)
+
+var DefaultCORSOrigins = []string{"http://localhost:8000"}
+
 type Handler struct {
-	ctrl   controller.ControllerProvider
-	logger logger.Logger
-	e      *echo.Echo
+	ctrl       controller.ControllerProvider
+	logger     logger.Logger
+	e          *echo.Echo
+	hub        *pubsub.Hub
+	webhooks   webhook.WebhookProvider
+	analytics  analytics.AnalyticsProvider
+	search     search.SearchProvider
+	exchange   exchange.ExchangeProvider
+	quarantine quarantine.QuarantineProvider
+	admin      admin.AdminProvider
+	adminToken string
+
+	corsOrigins []string
+	maxItemPage atomic.Int64
+	warmUpLimit int
+	rateLimiter *rateLimiter
+}
+
+type Option func(*Handler)
+
+func WithOrderEvents(hub *pubsub.Hub) Option {
+	return func(h *Handler) {
+		h.hub = hub
+	}
+}
+
+func WithWebhooks(webhooks webhook.WebhookProvider) Option {
+	return func(h *Handler) {
+		h.webhooks = webhooks
+	}
 }
 
-func NewHandler(ctrl controller.ControllerProvider, logger logger.Logger) *Handler {
+func WithAnalytics(analytics analytics.AnalyticsProvider) Option {
+	return func(h *Handler) {
+		h.analytics = analytics
+	}
+}
+
+func WithSearch(search search.SearchProvider) Option {
+	return func(h *Handler) {
+		h.search = search
+	}
+}
+
+func WithExchange(exchange exchange.ExchangeProvider) Option {
+	return func(h *Handler) {
+		h.exchange = exchange
+	}
+}
+
+func WithQuarantine(quarantine quarantine.QuarantineProvider) Option {
+	return func(h *Handler) {
+		h.quarantine = quarantine
+	}
+}
+
+func WithAdmin(admin admin.AdminProvider) Option {
+	return func(h *Handler) {
+		h.admin = admin
+	}
+}
+
+func WithAdminToken(token string) Option {
+	return func(h *Handler) {
+		h.adminToken = token
+	}
+}
+
+// WithCORSOrigins replaces DefaultCORSOrigins.
+func WithCORSOrigins(origins ...string) Option {
+	return func(h *Handler) {
+		h.corsOrigins = origins
+	}
+}
+
+func WithMaxItemPage(limit int) Option {
+	return func(h *Handler) {
+		h.SetMaxItemPage(limit)
+	}
+}
+
+// WithWarmUpLimit sets the limit of an admin warmup request without one.
+// WithRateLimit limits each client IP to rps requests per second with bursts
+// of up to burst requests. A zero rps disables the limit.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(h *Handler) {
+		h.SetRateLimit(rps, burst)
+	}
+}
+
+func WithWarmUpLimit(limit int) Option {
+	return func(h *Handler) {
+		h.warmUpLimit = limit
+	}
+}
+
+func NewHandler(ctrl controller.ControllerProvider, logger logger.Logger, opts ...Option) *Handler {
 	e := echo.New()
-	
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-        AllowOrigins: []string{"http://localhost:8000"},
-        AllowMethods: []string{echo.GET, echo.OPTIONS},
-        AllowCredentials: true,
-    }))
-	
-	e.Use(ZapLogger(logger))
-	e.HTTPErrorHandler = ErrorHandler(logger)
 
 	h := &Handler{
-		ctrl:   ctrl,
-		logger: logger,
-		e:      e,
+		ctrl:        ctrl,
+		logger:      logger,
+		e:           e,
+		corsOrigins: DefaultCORSOrigins,
+		warmUpLimit: DefaultWarmUpLimit,
+		rateLimiter: newRateLimiter(),
+	}
+	h.maxItemPage.Store(DefaultMaxItemPage)
+
+	for _, opt := range opts {
+		opt(h)
 	}
 
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:     h.corsOrigins,
+		AllowMethods:     []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
+		AllowHeaders:     []string{echo.HeaderAccept, echo.HeaderContentType, echo.HeaderAuthorization, echo.HeaderXRequestID},
+		AllowCredentials: true,
+		ExposeHeaders:    []string{echo.HeaderXRequestID},
+	}))
+
+	e.Use(Tracing())
+	e.Use(RequestID())
+	e.Use(ZapLogger(logger))
+	e.Use(RateLimit(h.rateLimiter))
+	e.HTTPErrorHandler = ErrorHandler(logger)
+
 	h.setupRoutes()
 
 	return h
 }
 
+// SetMaxItemPage changes the largest item page a client can request. It is
+// safe to call while the handler is serving.
+func (h *Handler) SetMaxItemPage(limit int) {
+	h.maxItemPage.Store(int64(limit))
+}
+
+// SetRateLimit changes the per-client rate limit. It is safe to call while the
+// handler is serving.
+func (h *Handler) SetRateLimit(rps float64, burst int) {
+	h.rateLimiter.set(rps, burst)
+}
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.e.ServeHTTP(w, r)
 }
@@ -54,6 +197,72 @@ func (h *Handler) setupRoutes() {
 	orders := api.Group("/orders")
 	orders.GET("/:order_uid", h.getOrder)
 	orders.GET("/:order_uid/items", h.getOrderItems)
+	if h.hub != nil {
+		orders.GET("/:order_uid/events", h.streamOrderEvents)
+		orders.GET("/:order_uid/ws", h.websocketOrderEvents)
+	}
+
+	if h.search != nil {
+		api.GET("/items/search", h.searchItems)
+	}
+
+	gql := echo.WrapHandler(graph.NewHandler(h.ctrl, h.logger))
+	api.GET("/graphql", gql)
+	api.POST("/graphql", gql)
+
+	if h.analytics != nil {
+		stats := api.Group("/analytics")
+		stats.GET("/revenue", h.getRevenue)
+		stats.GET("/orders", h.getOrderCounts)
+		stats.GET("/top-brands", h.getTopBrands)
+		stats.GET("/top-products", h.getTopProducts)
+		stats.GET("/basket", h.getBasketSize)
+	}
+
+	admin := api.Group("/admin", h.adminAuth)
+
+	if h.admin != nil {
+		cached := admin.Group("/cache")
+		cached.GET("", h.getCacheStats)
+		cached.DELETE("", h.clearCache)
+		cached.DELETE("/:order_uid", h.evictCachedOrder)
+		cached.GET("/warmup", h.getWarmUpProgress)
+		cached.POST("/warmup", h.warmUpCache)
+
+		consumer := admin.Group("/consumer")
+		consumer.GET("", h.getConsumerStatus)
+		consumer.POST("/pause", h.pauseConsumer)
+		consumer.POST("/resume", h.resumeConsumer)
+		consumer.POST("/seek", h.seekConsumer)
+
+		admin.GET("/log-level", h.getLogLevel)
+		admin.PUT("/log-level", h.setLogLevel)
+	}
+
+	if h.webhooks != nil {
+		webhooks := admin.Group("/webhooks")
+		webhooks.POST("", h.createWebhook)
+		webhooks.GET("", h.listWebhooks)
+		webhooks.GET("/:id", h.getWebhook)
+		webhooks.PUT("/:id", h.updateWebhook)
+		webhooks.DELETE("/:id", h.deleteWebhook)
+		webhooks.GET("/:id/deliveries", h.listWebhookDeliveries)
+	}
+
+	if h.exchange != nil {
+		rates := admin.Group("/exchange-rates")
+		rates.GET("", h.listExchangeRates)
+		rates.PUT("", h.setExchangeRates)
+	}
+
+	if h.quarantine != nil {
+		quarantined := admin.Group("/quarantine")
+		quarantined.GET("", h.listQuarantinedOrders)
+		quarantined.GET("/:id", h.getQuarantinedOrder)
+		quarantined.PUT("/:id/payload", h.updateQuarantinedPayload)
+		quarantined.POST("/:id/resubmit", h.resubmitQuarantinedOrder)
+		quarantined.DELETE("/:id", h.discardQuarantinedOrder)
+	}
 }
 
 func (h *Handler) getOrder(c echo.Context) error {
@@ -63,11 +272,22 @@ func (h *Handler) getOrder(c echo.Context) error {
 		return srvcerrors.ErrInvalidInput
 	}
 
+	trackStale(c)
 	order, err := h.ctrl.GetOrderByUID(c.Request().Context(), orderID)
 	if err != nil {
 		return err
 	}
 
+	if currency := c.QueryParam("currency"); currency != "" {
+		if h.exchange == nil {
+			return srvcerrors.ErrInvalidInput
+		}
+		if order, err = h.exchange.ConvertOrder(order, currency); err != nil {
+			return err
+		}
+	}
+
+	setStaleWarning(c)
 	return c.JSON(http.StatusOK, order)
 }
 
@@ -85,8 +305,8 @@ func (h *Handler) getOrderItems(c echo.Context) error {
 		if err != nil || limit <= 0 {
 			return srvcerrors.ErrInvalidInput
 		}
-		if limit > 100 {
-			limit = 100
+		if maxLimit := int(h.maxItemPage.Load()); limit > maxLimit {
+			limit = maxLimit
 		}
 	}
 
@@ -100,20 +320,43 @@ func (h *Handler) getOrderItems(c echo.Context) error {
 		}
 	}
 
+	trackStale(c)
 	items, err := h.ctrl.GetItemsByOrderUID(c.Request().Context(), orderID, lastID, limit)
 	if err != nil {
 		return err
 	}
 
+	if currency := c.QueryParam("currency"); currency != "" {
+		if items, err = h.convertItems(c, orderID, items, currency); err != nil {
+			return err
+		}
+	}
+
+	setStaleWarning(c)
 	return c.JSON(http.StatusOK, items)
 }
 
+// staleWarning is the RFC 7234 warning attached to responses served from the
+// cache while the database is unavailable.
+const staleWarning = `110 - "Response is Stale"`
+
+func trackStale(c echo.Context) {
+	c.SetRequest(c.Request().WithContext(controller.WithStaleTracking(c.Request().Context())))
+}
+
+func setStaleWarning(c echo.Context) {
+	if controller.IsStale(c.Request().Context()) {
+		c.Response().Header().Set("Warning", staleWarning)
+	}
+}
+
 func ZapLogger(logger logger.Logger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			start := time.Now()
+			log := requestLogger(c, logger)
 
-			logger.Debug("handler: incoming request",
+			log.Debug("handler: incoming request",
 				zap.String("method", c.Request().Method),
 				zap.String("path", c.Request().URL.Path),
 				zap.String("order_uid", c.Param("order_uid")))
@@ -122,14 +365,14 @@ func ZapLogger(logger logger.Logger) echo.MiddlewareFunc {
 
 			duration := time.Since(start)
 			if err != nil {
-				logger.Debug("handler: request failed",
+				log.Debug("handler: request failed",
 					zap.String("method", c.Request().Method),
 					zap.String("path", c.Request().URL.Path),
 					zap.Duration("duration", duration),
 					zap.String("order_uid", c.Param("order_uid")),
 					zap.Error(err))
 			} else {
-				logger.Debug("handler: request completed",
+				log.Debug("handler: request completed",
 					zap.String("method", c.Request().Method),
 					zap.String("path", c.Request().URL.Path),
 					zap.Duration("duration", duration),
@@ -140,6 +383,95 @@ func ZapLogger(logger logger.Logger) echo.MiddlewareFunc {
 	}
 }
 
+// RequestID tags the request with the caller's X-Request-ID, or a generated one
+// if it is missing or malformed, and echoes it in the response. Logs written
+// through logger.FromContext carry it as request_id.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+			ctx := logger.WithFields(c.Request().Context(), zap.String("request_id", id))
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", id))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+const maxRequestIDLength = 128
+
+// validRequestID only accepts ids that are safe to copy into logs and headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func requestLogger(c echo.Context, l logger.Logger) logger.Logger {
+	return logger.FromContext(c.Request().Context(), l)
+}
+
+// Tracing starts a server span per request, continuing the trace of the caller
+// if the request carries W3C trace context headers. Errors are rendered inside
+// the span so that the recorded status code is the one sent to the client.
+func Tracing() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			if route == "" {
+				route = req.URL.Path
+			}
+			ctx, span := tracing.Tracer(tracerName).Start(ctx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(req.URL.Path),
+				))
+			defer span.End()
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+				if err != nil {
+					span.RecordError(err)
+				}
+			}
+			return nil
+		}
+	}
+}
+
 func ErrorHandler(logger logger.Logger) echo.HTTPErrorHandler {
 	return func(err error, c echo.Context) {
 		status := http.StatusInternalServerError
@@ -157,6 +489,12 @@ func ErrorHandler(logger logger.Logger) echo.HTTPErrorHandler {
 		} else if errors.Is(err, srvcerrors.ErrKafka) {
 			status = http.StatusInternalServerError
 			message = "Kafka service error"
+		} else if errors.Is(err, srvcerrors.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+			message = "Service temporarily unavailable"
+		} else if errors.Is(err, srvcerrors.ErrConflict) {
+			status = http.StatusConflict
+			message = "Resource state conflict"
 		} else {
 			if he, ok := err.(*echo.HTTPError); ok {
 				status = he.Code
@@ -164,15 +502,16 @@ func ErrorHandler(logger logger.Logger) echo.HTTPErrorHandler {
 			}
 		}
 
+		log := requestLogger(c, logger)
 		if status >= 500 {
-			logger.Error("handler: request failed",
+			log.Error("handler: request failed",
 				zap.String("method", c.Request().Method),
 				zap.String("path", c.Request().URL.Path),
 				zap.Int("status", status),
 				zap.String("order_uid", c.Param("order_uid")),
 				zap.Error(err))
 		} else {
-			logger.Warn("handler: client error",
+			log.Warn("handler: client error",
 				zap.String("method", c.Request().Method),
 				zap.String("path", c.Request().URL.Path),
 				zap.Int("status", status),
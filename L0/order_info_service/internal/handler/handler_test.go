@@ -1,18 +1,34 @@
 package handler_test
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/circuitbreaker"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/controller"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/pubsub"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type MockController struct {
@@ -27,6 +43,14 @@ func (m *MockController) GetOrderByUID(ctx context.Context, orderID string) (*mo
 	return nil, args.Error(1)
 }
 
+func (m *MockController) GetOrdersByUIDs(ctx context.Context, orderIDs []string) (map[string]*model.Order, error) {
+	args := m.Called(ctx, orderIDs)
+	if orders, ok := args.Get(0).(map[string]*model.Order); ok || args.Get(0) == nil {
+		return orders, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockController) GetItemsByOrderUID(ctx context.Context, orderID string, lastID, limit int) ([]*model.Item, error) {
 	args := m.Called(ctx, orderID, lastID, limit)
 	if items, ok := args.Get(0).([]*model.Item); ok || args.Get(0) == nil {
@@ -36,12 +60,13 @@ func (m *MockController) GetItemsByOrderUID(ctx context.Context, orderID string,
 	return nil, args.Error(1)
 }
 
-type MockLogger struct{}
-
-func (l *MockLogger) Info(msg string, fields ...logger.Field)  {}
-func (l *MockLogger) Error(msg string, fields ...logger.Field) {}
-func (l *MockLogger) Debug(msg string, fields ...logger.Field) {}
-func (l *MockLogger) Warn(msg string, fields ...logger.Field)  {}
+func (m *MockController) GetItemsByOrderUIDs(ctx context.Context, orderIDs []string, lastID, limit int) (map[string][]*model.Item, error) {
+	args := m.Called(ctx, orderIDs, lastID, limit)
+	if items, ok := args.Get(0).(map[string][]*model.Item); ok || args.Get(0) == nil {
+		return items, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
 
 func generateTestOrder(uid string) *model.Order {
 	return &model.Order{
@@ -56,7 +81,7 @@ func TestHandler_GetOrder_Success(t *testing.T) {
 	order := generateTestOrder("ORDER-001")
 	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(order, nil)
 
-	h := handler.NewHandler(mockCtrl, &MockLogger{})
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{})
 
 	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001", nil)
 	rec := httptest.NewRecorder()
@@ -71,7 +96,7 @@ func TestHandler_GetOrder_Success(t *testing.T) {
 
 func TestHandler_GetOrder_InvalidID(t *testing.T) {
 	mockCtrl := new(MockController)
-	h := handler.NewHandler(mockCtrl, &MockLogger{})
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{})
 
 	req := httptest.NewRequest(http.MethodGet, "/api/orders/%20", nil)
 	rec := httptest.NewRecorder()
@@ -94,7 +119,7 @@ func TestHandler_GetOrderItems_Success(t *testing.T) {
 	}
 	mockCtrl.On("GetItemsByOrderUID", mock.Anything, "ORDER-001", 0, 10).Return(items, nil)
 
-	h := handler.NewHandler(mockCtrl, &MockLogger{})
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{})
 
 	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001/items?limit=10", nil)
 	rec := httptest.NewRecorder()
@@ -107,12 +132,68 @@ func TestHandler_GetOrderItems_Success(t *testing.T) {
 	mockCtrl.AssertExpectations(t)
 }
 
+func TestHandler_GetOrderItems_MaxItemPage(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetItemsByOrderUID", mock.Anything, "ORDER-001", 0, 20).Return([]*model.Item{}, nil).Once()
+	mockCtrl.On("GetItemsByOrderUID", mock.Anything, "ORDER-001", 0, 5).Return([]*model.Item{}, nil).Once()
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{}, handler.WithMaxItemPage(20))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001/items?limit=500", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	h.SetMaxItemPage(5)
+	req = httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001/items?limit=500", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	mockCtrl.AssertExpectations(t)
+}
+
+func TestHandler_CORSOrigins(t *testing.T) {
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithCORSOrigins("https://orders.example.com"))
+
+	for origin, allowed := range map[string]string{
+		"https://orders.example.com": "https://orders.example.com",
+		"http://localhost:8000":      "",
+	} {
+		req := httptest.NewRequest(http.MethodOptions, "/api/orders/ORDER-001", nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, allowed, rec.Header().Get(echo.HeaderAccessControlAllowOrigin), origin)
+	}
+}
+
+func TestHandler_CORSPreflightForAdmin(t *testing.T) {
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{})
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/admin/log-level", nil)
+	req.Header.Set(echo.HeaderOrigin, "http://localhost:8000")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPut)
+	req.Header.Set(echo.HeaderAccessControlRequestHeaders, "Authorization, Content-Type")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlAllowMethods), http.MethodPut)
+	assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlAllowMethods), http.MethodDelete)
+	assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlAllowHeaders), echo.HeaderAuthorization)
+}
+
 func TestHandler_GetOrderItems_NotFound(t *testing.T) {
 	mockCtrl := new(MockController)
 
 	mockCtrl.On("GetItemsByOrderUID", mock.Anything, "ORDER-001", 0, 10).Return(nil, srvcerrors.ErrNotFound)
 
-	h := handler.NewHandler(mockCtrl, &MockLogger{})
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{})
 
 	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001/items", nil)
 	rec := httptest.NewRecorder()
@@ -125,3 +206,273 @@ func TestHandler_GetOrderItems_NotFound(t *testing.T) {
 
 	mockCtrl.AssertExpectations(t)
 }
+
+type unavailableRepository struct {
+	repository.RepositoryProvider
+}
+
+func (unavailableRepository) GetOrderByUID(context.Context, string) (*model.Order, error) {
+	return nil, circuitbreaker.ErrOpen
+}
+
+func (unavailableRepository) GetItemsByOrderUID(context.Context, string, int, int) ([]*model.Item, error) {
+	return nil, circuitbreaker.ErrOpen
+}
+
+type trippedBreaker struct{}
+
+func (trippedBreaker) Tripped() bool { return true }
+
+func TestHandler_StaleResponses(t *testing.T) {
+	c := cache.NewLocalCache()
+	cached := generateTestOrder("ORDER-001")
+	cached.Items = []*model.Item{{ChrtID: 1}, {ChrtID: 2}}
+	c.SetOrder(cached)
+	evicted := generateTestOrder("ORDER-002")
+	c.SetOrder(evicted)
+	c.Delete("ORDER-002")
+
+	ctrl := controller.NewController(unavailableRepository{}, c, &loggertest.MockLogger{}, controller.WithBreaker(trippedBreaker{}))
+	h := handler.NewHandler(ctrl, &loggertest.MockLogger{})
+
+	for _, path := range []string{"/api/orders/ORDER-001", "/api/orders/ORDER-001/items", "/api/orders/ORDER-002"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, `110 - "Response is Stale"`, rec.Header().Get("Warning"), path)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-003", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Empty(t, rec.Header().Get("Warning"))
+	assert.Contains(t, rec.Body.String(), `"message":"Service temporarily unavailable"`)
+}
+
+func TestHandler_GetOrder_NotStale(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(generateTestOrder("ORDER-001"), nil)
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Warning"))
+}
+
+func TestHandler_TracingContinuesIncomingTrace(t *testing.T) {
+	provider, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterMemory, SampleRatio: 1})
+	require.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(nil, srvcerrors.ErrNotFound)
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	spans := provider.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /api/orders/:order_uid", spans[0].Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Contains(t, spans[0].Attributes, attribute.Int("http.response.status_code", http.StatusNotFound))
+}
+
+func TestHandler_RequestID(t *testing.T) {
+	mockCtrl := new(MockController)
+	hasRequestID := func(id string) interface{} {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			fields := logger.FieldsFromContext(ctx)
+			return len(fields) == 1 && fields[0].Key == "request_id" && (id == "" || fields[0].String == id)
+		})
+	}
+	mockCtrl.On("GetOrderByUID", hasRequestID("client-id-1"), "ORDER-001").Return(generateTestOrder("ORDER-001"), nil).Once()
+	mockCtrl.On("GetOrderByUID", hasRequestID(""), "ORDER-001").Return(generateTestOrder("ORDER-001"), nil)
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001", nil)
+	req.Header.Set("X-Request-ID", "client-id-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "client-id-1", rec.Header().Get("X-Request-ID"))
+
+	for _, incoming := range []string{"", "bad id\nwith newline", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001", nil)
+		if incoming != "" {
+			req.Header.Set("X-Request-ID", incoming)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Regexp(t, "^[0-9a-f]{32}$", rec.Header().Get("X-Request-ID"))
+	}
+	mockCtrl.AssertExpectations(t)
+}
+
+func TestHandler_RequestID_OnErrors(t *testing.T) {
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/unknown", nil)
+	req.Header.Set("X-Request-ID", "client-id-2")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "client-id-2", rec.Header().Get("X-Request-ID"))
+}
+
+func TestHandler_GraphQL_Order(t *testing.T) {
+	mockCtrl := new(MockController)
+
+	order := generateTestOrder("ORDER-001")
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(order, nil)
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{})
+
+	body := `{"query":"{ order(uid: \"ORDER-001\") { orderUid trackNumber } }"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":{"order":{"orderUid":"ORDER-001","trackNumber":"TRK-ORDER-001"}}}`, rec.Body.String())
+
+	mockCtrl.AssertExpectations(t)
+}
+
+func TestHandler_OrderEvents_SSE(t *testing.T) {
+	hub := pubsub.NewHub(pubsub.DefaultBufferSize)
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithOrderEvents(hub))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/orders/ORDER-001/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool {
+		return hub.SubscribersCount("ORDER-001") == 1
+	}, time.Second, 5*time.Millisecond)
+
+	hub.Publish(generateTestOrder("ORDER-001"))
+
+	reader := bufio.NewReader(resp.Body)
+	var event, data string
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+		}
+	}
+
+	assert.Equal(t, "order", event)
+	assert.Contains(t, data, `"order_uid":"ORDER-001"`)
+}
+
+func TestHandler_OrderEvents_WebSocket(t *testing.T) {
+	hub := pubsub.NewHub(pubsub.DefaultBufferSize)
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithOrderEvents(hub))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/orders/ORDER-001/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		return hub.SubscribersCount("ORDER-001") == 1
+	}, time.Second, 5*time.Millisecond)
+
+	hub.Publish(generateTestOrder("ORDER-001"))
+
+	var got model.Order
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	require.NoError(t, conn.ReadJSON(&got))
+	assert.Equal(t, "ORDER-001", got.OrderUID)
+
+	conn.Close()
+	require.Eventually(t, func() bool {
+		return hub.SubscribersCount("ORDER-001") == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestHandler_OrderEvents_EndOnHubClose(t *testing.T) {
+	hub := pubsub.NewHub(pubsub.DefaultBufferSize)
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithOrderEvents(hub))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/orders/ORDER-001/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/orders/ORDER-001/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		return hub.SubscribersCount("ORDER-001") == 2
+	}, time.Second, 5*time.Millisecond)
+
+	hub.Close()
+
+	_, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, _, err = conn.ReadMessage()
+	var netErr net.Error
+	assert.False(t, errors.As(err, &netErr) && netErr.Timeout(), "websocket was not closed")
+}
+
+func TestHandler_OrderEvents_Disabled(t *testing.T) {
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001/events", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
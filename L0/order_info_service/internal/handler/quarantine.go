@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/labstack/echo/v4"
+)
+
+const maxQuarantinePayloadSize = 1 << 20
+
+func (h *Handler) listQuarantinedOrders(c echo.Context) error {
+	limit := 0
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return srvcerrors.ErrInvalidInput
+		}
+	}
+
+	var lastID int64
+	if lastIDStr := c.QueryParam("last_id"); lastIDStr != "" {
+		var err error
+		lastID, err = strconv.ParseInt(lastIDStr, 10, 64)
+		if err != nil || lastID < 0 {
+			return srvcerrors.ErrInvalidInput
+		}
+	}
+
+	orders, err := h.quarantine.List(c.Request().Context(), c.QueryParam("status"), lastID, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, orders)
+}
+
+func (h *Handler) getQuarantinedOrder(c echo.Context) error {
+	id, err := quarantineID(c)
+	if err != nil {
+		return err
+	}
+
+	q, err := h.quarantine.Get(c.Request().Context(), id)
+	if err != nil {
+		return quarantineNotFound(err)
+	}
+
+	return c.JSON(http.StatusOK, q)
+}
+
+func (h *Handler) updateQuarantinedPayload(c echo.Context) error {
+	id, err := quarantineID(c)
+	if err != nil {
+		return err
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(c.Request().Body, maxQuarantinePayloadSize+1))
+	if err != nil || len(payload) > maxQuarantinePayloadSize {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	q, err := h.quarantine.UpdatePayload(c.Request().Context(), id, payload)
+	if err != nil {
+		return quarantineNotFound(err)
+	}
+
+	return c.JSON(http.StatusOK, q)
+}
+
+func (h *Handler) resubmitQuarantinedOrder(c echo.Context) error {
+	id, err := quarantineID(c)
+	if err != nil {
+		return err
+	}
+
+	q, err := h.quarantine.Resubmit(c.Request().Context(), id)
+	if rejection, ok := ingest.AsRejection(err); ok {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+			"status":  http.StatusUnprocessableEntity,
+			"message": "Order payload is still invalid",
+			"stage":   rejection.Stage,
+			"errors":  rejection.Errors,
+		})
+	}
+	if err != nil {
+		return quarantineNotFound(err)
+	}
+
+	return c.JSON(http.StatusOK, q)
+}
+
+func (h *Handler) discardQuarantinedOrder(c echo.Context) error {
+	id, err := quarantineID(c)
+	if err != nil {
+		return err
+	}
+
+	q, err := h.quarantine.Discard(c.Request().Context(), id)
+	if err != nil {
+		return quarantineNotFound(err)
+	}
+
+	return c.JSON(http.StatusOK, q)
+}
+
+func quarantineID(c echo.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, srvcerrors.ErrInvalidInput
+	}
+	return id, nil
+}
+
+func quarantineNotFound(err error) error {
+	if errors.Is(err, srvcerrors.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Quarantined order not found")
+	}
+	return err
+}
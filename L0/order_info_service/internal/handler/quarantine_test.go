@@ -0,0 +1,188 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockQuarantine struct {
+	mock.Mock
+}
+
+func (m *MockQuarantine) List(ctx context.Context, status string, lastID int64, limit int) ([]*model.QuarantinedOrder, error) {
+	args := m.Called(ctx, status, lastID, limit)
+	orders, _ := args.Get(0).([]*model.QuarantinedOrder)
+	return orders, args.Error(1)
+}
+
+func (m *MockQuarantine) Get(ctx context.Context, id int64) (*model.QuarantinedOrder, error) {
+	args := m.Called(ctx, id)
+	q, _ := args.Get(0).(*model.QuarantinedOrder)
+	return q, args.Error(1)
+}
+
+func (m *MockQuarantine) UpdatePayload(ctx context.Context, id int64, payload []byte) (*model.QuarantinedOrder, error) {
+	args := m.Called(ctx, id, payload)
+	q, _ := args.Get(0).(*model.QuarantinedOrder)
+	return q, args.Error(1)
+}
+
+func (m *MockQuarantine) Resubmit(ctx context.Context, id int64) (*model.QuarantinedOrder, error) {
+	args := m.Called(ctx, id)
+	q, _ := args.Get(0).(*model.QuarantinedOrder)
+	return q, args.Error(1)
+}
+
+func (m *MockQuarantine) Discard(ctx context.Context, id int64) (*model.QuarantinedOrder, error) {
+	args := m.Called(ctx, id)
+	q, _ := args.Get(0).(*model.QuarantinedOrder)
+	return q, args.Error(1)
+}
+
+func TestHandler_ListQuarantinedOrders(t *testing.T) {
+	mockQuarantine := new(MockQuarantine)
+	mockQuarantine.On("List", mock.Anything, model.QuarantineStatusPending, int64(3), 10).Return([]*model.QuarantinedOrder{
+		{ID: 4, OrderUID: "b563feb7b2b84b6test", Stage: ingest.StageValidation, Status: model.QuarantineStatusPending},
+	}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithQuarantine(mockQuarantine), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodGet, "/api/admin/quarantine?status=quarantined&last_id=3&limit=10", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"order_uid":"b563feb7b2b84b6test"`)
+	mockQuarantine.AssertExpectations(t)
+}
+
+func TestHandler_GetQuarantinedOrder_NotFound(t *testing.T) {
+	mockQuarantine := new(MockQuarantine)
+	mockQuarantine.On("Get", mock.Anything, int64(7)).Return(nil, srvcerrors.ErrNotFound)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithQuarantine(mockQuarantine), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodGet, "/api/admin/quarantine/7", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Quarantined order not found")
+}
+
+func TestHandler_GetQuarantinedOrder_Payload(t *testing.T) {
+	mockQuarantine := new(MockQuarantine)
+	mockQuarantine.On("Get", mock.Anything, int64(1)).
+		Return(&model.QuarantinedOrder{ID: 1, Payload: []byte(`{"order_uid":`), Stage: ingest.StageDecode}, nil)
+	mockQuarantine.On("Get", mock.Anything, int64(2)).
+		Return(&model.QuarantinedOrder{ID: 2, Payload: []byte(`{"order_uid":"b563feb7b2b84b6test"}`), Stage: ingest.StageValidation}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithQuarantine(mockQuarantine), handler.WithAdminToken(testAdminToken))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newAdminRequest(http.MethodGet, "/api/admin/quarantine/1", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"payload":"{\"order_uid\":"`)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, newAdminRequest(http.MethodGet, "/api/admin/quarantine/2", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"payload":{"order_uid":"b563feb7b2b84b6test"}`)
+}
+
+func TestHandler_UpdateQuarantinedPayload(t *testing.T) {
+	payload := `{"order_uid":"b563feb7b2b84b6test"}`
+	mockQuarantine := new(MockQuarantine)
+	mockQuarantine.On("UpdatePayload", mock.Anything, int64(2), []byte(payload)).
+		Return(&model.QuarantinedOrder{ID: 2, OrderUID: "b563feb7b2b84b6test"}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithQuarantine(mockQuarantine), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPut, "/api/admin/quarantine/2/payload", strings.NewReader(payload))
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockQuarantine.AssertExpectations(t)
+}
+
+func TestHandler_ResubmitQuarantinedOrder_Rejected(t *testing.T) {
+	mockQuarantine := new(MockQuarantine)
+	mockQuarantine.On("Resubmit", mock.Anything, int64(5)).Return(nil, &ingest.RejectionError{
+		Stage:  ingest.StageRules,
+		Errors: []string{"payment_amount: mismatch"},
+	})
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithQuarantine(mockQuarantine), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPost, "/api/admin/quarantine/5/resubmit", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"stage":"rules"`)
+	assert.Contains(t, rec.Body.String(), "payment_amount: mismatch")
+}
+
+func TestHandler_ResubmitQuarantinedOrder_Conflict(t *testing.T) {
+	mockQuarantine := new(MockQuarantine)
+	mockQuarantine.On("Resubmit", mock.Anything, int64(5)).Return(nil, srvcerrors.ErrConflict)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithQuarantine(mockQuarantine), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodPost, "/api/admin/quarantine/5/resubmit", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestHandler_DiscardQuarantinedOrder(t *testing.T) {
+	mockQuarantine := new(MockQuarantine)
+	mockQuarantine.On("Discard", mock.Anything, int64(5)).
+		Return(&model.QuarantinedOrder{ID: 5, Status: model.QuarantineStatusDiscarded}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithQuarantine(mockQuarantine), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodDelete, "/api/admin/quarantine/5", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"discarded"`)
+}
+
+func TestHandler_Quarantine_InvalidID(t *testing.T) {
+	mockQuarantine := new(MockQuarantine)
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithQuarantine(mockQuarantine), handler.WithAdminToken(testAdminToken))
+
+	for _, target := range []string{"/api/admin/quarantine/abc", "/api/admin/quarantine/0"} {
+		req := newAdminRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+	mockQuarantine.AssertNotCalled(t, "Get")
+}
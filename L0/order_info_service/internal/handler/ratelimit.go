@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// clientIdleTTL is how long a client's limiter is kept after its last request.
+const clientIdleTTL = 3 * time.Minute
+
+type rateClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter limits requests per client IP. A zero rate disables it. The
+// rate can be changed while the handler is serving.
+type rateLimiter struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	clients   map[string]*rateClient
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		clients: make(map[string]*rateClient),
+		now:     time.Now,
+	}
+}
+
+func (l *rateLimiter) set(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = rate.Limit(rps)
+	l.burst = burst
+	if rps <= 0 {
+		l.clients = make(map[string]*rateClient)
+		return
+	}
+	for _, client := range l.clients {
+		client.limiter.SetLimit(l.limit)
+		client.limiter.SetBurst(l.burst)
+	}
+}
+
+func (l *rateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 {
+		return true
+	}
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > clientIdleTTL {
+		for k, client := range l.clients {
+			if now.Sub(client.lastSeen) > clientIdleTTL {
+				delete(l.clients, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	client, ok := l.clients[key]
+	if !ok {
+		client = &rateClient{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[key] = client
+	}
+	client.lastSeen = now
+	return client.limiter.AllowN(now, 1)
+}
+
+// RateLimit rejects requests over the client's rate with 429 Too Many Requests.
+func RateLimit(l *rateLimiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !l.allow(c.RealIP()) {
+				return echo.NewHTTPError(http.StatusTooManyRequests, "Too many requests")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_RateLimit(t *testing.T) {
+	mockCtrl := new(MockController)
+	mockCtrl.On("GetOrderByUID", mock.Anything, "ORDER-001").Return(generateTestOrder("ORDER-001"), nil)
+
+	h := handler.NewHandler(mockCtrl, &loggertest.MockLogger{}, handler.WithRateLimit(0.001, 2))
+
+	get := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/orders/ORDER-001", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, get("10.0.0.1:1000"))
+	require.Equal(t, http.StatusOK, get("10.0.0.1:1001"))
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.1:1002"))
+	assert.Equal(t, http.StatusOK, get("10.0.0.2:1000"), "clients are limited separately")
+
+	h.SetRateLimit(0, 2)
+	assert.Equal(t, http.StatusOK, get("10.0.0.1:1003"), "a zero rate disables the limit")
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) searchItems(c echo.Context) error {
+	limit := 0
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return srvcerrors.ErrInvalidInput
+		}
+	}
+
+	offset := 0
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		var err error
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return srvcerrors.ErrInvalidInput
+		}
+	}
+
+	result, err := h.search.SearchItems(c.Request().Context(), c.QueryParam("q"), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	for _, hit := range result.Hits {
+		hit.OrderURL = "/api/orders/" + url.PathEscape(hit.OrderUID)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,69 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSearch struct {
+	mock.Mock
+}
+
+func (m *MockSearch) SearchItems(ctx context.Context, query string, limit, offset int) (*model.ItemSearchResult, error) {
+	args := m.Called(ctx, query, limit, offset)
+	result, _ := args.Get(0).(*model.ItemSearchResult)
+	return result, args.Error(1)
+}
+
+func TestHandler_SearchItems(t *testing.T) {
+	mockSearch := new(MockSearch)
+	mockSearch.On("SearchItems", mock.Anything, "vivienne", 5, 10).Return(&model.ItemSearchResult{
+		Query: "vivienne",
+		Hits: []*model.ItemSearchHit{{
+			OrderUID: "b563feb7b2b84b6test",
+			Rank:     0.5,
+			Item:     &model.Item{ID: 1, OrderUID: "b563feb7b2b84b6test", Brand: "Vivienne Sabo"},
+		}},
+		Offset:     10,
+		NextOffset: 11,
+	}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithSearch(mockSearch))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/items/search?q=vivienne&limit=5&offset=10", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"order_url":"/api/orders/b563feb7b2b84b6test"`)
+	assert.Contains(t, rec.Body.String(), `"next_offset":11`)
+	mockSearch.AssertExpectations(t)
+}
+
+func TestHandler_SearchItems_InvalidParams(t *testing.T) {
+	mockSearch := new(MockSearch)
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithSearch(mockSearch))
+
+	for _, target := range []string{
+		"/api/items/search?q=brand&limit=abc",
+		"/api/items/search?q=brand&offset=-1",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+	mockSearch.AssertNotCalled(t, "SearchItems")
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+func (r *webhookRequest) toSubscription(id int64) *model.WebhookSubscription {
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+	return &model.WebhookSubscription{
+		ID:     id,
+		URL:    r.URL,
+		Secret: r.Secret,
+		Events: r.Events,
+		Active: active,
+	}
+}
+
+func (h *Handler) createWebhook(c echo.Context) error {
+	var req webhookRequest
+	if err := c.Bind(&req); err != nil {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	sub, err := h.webhooks.CreateSubscription(c.Request().Context(), req.toSubscription(0))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, sub)
+}
+
+func (h *Handler) listWebhooks(c echo.Context) error {
+	subs, err := h.webhooks.ListSubscriptions(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, subs)
+}
+
+func (h *Handler) getWebhook(c echo.Context) error {
+	id, err := webhookID(c)
+	if err != nil {
+		return err
+	}
+
+	sub, err := h.webhooks.GetSubscription(c.Request().Context(), id)
+	if err != nil {
+		return webhookNotFound(err)
+	}
+
+	return c.JSON(http.StatusOK, sub)
+}
+
+func (h *Handler) updateWebhook(c echo.Context) error {
+	id, err := webhookID(c)
+	if err != nil {
+		return err
+	}
+
+	var req webhookRequest
+	if err := c.Bind(&req); err != nil {
+		return srvcerrors.ErrInvalidInput
+	}
+
+	sub, err := h.webhooks.UpdateSubscription(c.Request().Context(), req.toSubscription(id))
+	if err != nil {
+		return webhookNotFound(err)
+	}
+
+	return c.JSON(http.StatusOK, sub)
+}
+
+func (h *Handler) deleteWebhook(c echo.Context) error {
+	id, err := webhookID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.webhooks.DeleteSubscription(c.Request().Context(), id); err != nil {
+		return webhookNotFound(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) listWebhookDeliveries(c echo.Context) error {
+	id, err := webhookID(c)
+	if err != nil {
+		return err
+	}
+
+	limit := defaultDeliveriesLimit
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return srvcerrors.ErrInvalidInput
+		}
+		limit = min(limit, maxDeliveriesLimit)
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(c.Request().Context(), id, limit)
+	if err != nil {
+		return webhookNotFound(err)
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+func webhookID(c echo.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, srvcerrors.ErrInvalidInput
+	}
+	return id, nil
+}
+
+func webhookNotFound(err error) error {
+	if errors.Is(err, srvcerrors.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Webhook subscription not found")
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/handler"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWebhooks struct {
+	mock.Mock
+}
+
+func (m *MockWebhooks) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if created, ok := args.Get(0).(*model.WebhookSubscription); ok || args.Get(0) == nil {
+		return created, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWebhooks) UpdateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if updated, ok := args.Get(0).(*model.WebhookSubscription); ok || args.Get(0) == nil {
+		return updated, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWebhooks) DeleteSubscription(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockWebhooks) GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if sub, ok := args.Get(0).(*model.WebhookSubscription); ok || args.Get(0) == nil {
+		return sub, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWebhooks) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if subs, ok := args.Get(0).([]*model.WebhookSubscription); ok || args.Get(0) == nil {
+		return subs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWebhooks) ListDeliveries(ctx context.Context, id int64, limit int) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, id, limit)
+	if deliveries, ok := args.Get(0).([]*model.WebhookDelivery); ok || args.Get(0) == nil {
+		return deliveries, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestHandler_CreateWebhook(t *testing.T) {
+	mockWebhooks := new(MockWebhooks)
+	mockWebhooks.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(sub *model.WebhookSubscription) bool {
+		return sub.URL == "http://partner.local/hook" && sub.Active && len(sub.Events) == 1
+	})).Return(&model.WebhookSubscription{
+		ID:     1,
+		URL:    "http://partner.local/hook",
+		Secret: "s3cr3t",
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	}, nil)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithWebhooks(mockWebhooks), handler.WithAdminToken(testAdminToken))
+
+	body := `{"url":"http://partner.local/hook","events":["order.created"]}`
+	req := newAdminRequest(http.MethodPost, "/api/admin/webhooks", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"secret":"s3cr3t"`)
+	mockWebhooks.AssertExpectations(t)
+}
+
+func TestHandler_GetWebhook_NotFound(t *testing.T) {
+	mockWebhooks := new(MockWebhooks)
+	mockWebhooks.On("GetSubscription", mock.Anything, int64(42)).Return(nil, srvcerrors.ErrNotFound)
+
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithWebhooks(mockWebhooks), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodGet, "/api/admin/webhooks/42", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"Webhook subscription not found"`)
+}
+
+func TestHandler_ListWebhookDeliveries_InvalidID(t *testing.T) {
+	mockWebhooks := new(MockWebhooks)
+	h := handler.NewHandler(new(MockController), &loggertest.MockLogger{}, handler.WithWebhooks(mockWebhooks), handler.WithAdminToken(testAdminToken))
+
+	req := newAdminRequest(http.MethodGet, "/api/admin/webhooks/abc/deliveries", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	mockWebhooks.AssertNotCalled(t, "ListDeliveries")
+}
@@ -0,0 +1,87 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/rules"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	StageDecode     = "decode"
+	StageValidation = "validation"
+	StageRules      = "rules"
+)
+
+type RejectionError struct {
+	Stage  string
+	Errors []string
+}
+
+func (e *RejectionError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Stage, strings.Join(e.Errors, "; "))
+}
+
+func (e *RejectionError) Unwrap() error {
+	return srvcerrors.ErrInvalidInput
+}
+
+func AsRejection(err error) (*RejectionError, bool) {
+	var rejection *RejectionError
+	ok := errors.As(err, &rejection)
+	return rejection, ok
+}
+
+type Decoder struct {
+	validator *validator.Validate
+	rules     *rules.Engine
+}
+
+func NewDecoder(engine *rules.Engine) *Decoder {
+	return &Decoder{
+		validator: validator.New(),
+		rules:     engine,
+	}
+}
+
+func (d *Decoder) Decode(payload []byte) (*model.Order, *rules.Result, error) {
+	var order model.Order
+	if err := json.Unmarshal(payload, &order); err != nil {
+		return nil, nil, &RejectionError{Stage: StageDecode, Errors: []string{err.Error()}}
+	}
+
+	if err := d.validator.Struct(&order); err != nil {
+		var ve validator.ValidationErrors
+		if !errors.As(err, &ve) {
+			return nil, nil, &RejectionError{Stage: StageValidation, Errors: []string{err.Error()}}
+		}
+		parts := make([]string, 0, len(ve))
+		for _, e := range ve {
+			parts = append(parts, fmt.Sprintf("%s: %s", e.Field(), e.Tag()))
+		}
+		return &order, nil, &RejectionError{Stage: StageValidation, Errors: parts}
+	}
+
+	order.Flags = nil
+	if d.rules == nil {
+		return &order, &rules.Result{}, nil
+	}
+
+	result := d.rules.Evaluate(&order)
+	if result.Rejected() {
+		rejected := result.ByAction(rules.ActionReject)
+		parts := make([]string, 0, len(rejected))
+		for _, v := range rejected {
+			parts = append(parts, fmt.Sprintf("%s: %s", v.Rule, v.Message))
+		}
+		return &order, result, &RejectionError{Stage: StageRules, Errors: parts}
+	}
+
+	order.Flags = result.Flags()
+	return &order, result, nil
+}
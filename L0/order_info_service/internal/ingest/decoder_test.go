@@ -0,0 +1,146 @@
+package ingest_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/rules"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validOrder() *model.Order {
+	return &model.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: model.Delivery{
+			OrderUID: "b563feb7b2b84b6test",
+			Name:     "Test Testov",
+			Phone:    "+9720000000",
+			Zip:      "2639809",
+			City:     "Kiryat Mozkin",
+			Address:  "Ploshad Mira 15",
+			Region:   "Kraiot",
+			Email:    "test@gmail.com",
+		},
+		Payment: model.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDT:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []*model.Item{{
+			OrderUID:    "b563feb7b2b84b6test",
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			RID:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+	}
+}
+
+func encode(t *testing.T, order *model.Order) []byte {
+	payload, err := json.Marshal(order)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestDecoder_ValidOrder(t *testing.T) {
+	order := validOrder()
+	order.Flags = []*model.OrderFlag{{Rule: "forged"}}
+
+	decoded, result, err := ingest.NewDecoder(rules.NewEngine(nil)).Decode(encode(t, order))
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, order.OrderUID, decoded.OrderUID)
+	assert.Nil(t, decoded.Flags)
+}
+
+func TestDecoder_Rejections(t *testing.T) {
+	invalid := validOrder()
+	invalid.Locale = "de"
+
+	mismatch := validOrder()
+	mismatch.Payment.Amount = 1
+
+	tests := []struct {
+		name    string
+		payload []byte
+		stage   string
+	}{
+		{"malformed json", []byte(`{"order_uid":`), ingest.StageDecode},
+		{"failed tags", encode(t, invalid), ingest.StageValidation},
+		{"rejected by rules", encode(t, mismatch), ingest.StageRules},
+	}
+
+	decoder := ingest.NewDecoder(rules.NewEngine(map[string]rules.Action{
+		rules.RulePaymentAmount: rules.ActionReject,
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decoder.Decode(tt.payload)
+
+			require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+			rejection, ok := ingest.AsRejection(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.stage, rejection.Stage)
+			assert.NotEmpty(t, rejection.Errors)
+		})
+	}
+}
+
+func TestDecoder_RulesRejectionListsRejectedRules(t *testing.T) {
+	order := validOrder()
+	order.Payment.Amount = 1
+	order.Items[0].TotalPrice = 453
+
+	decoder := ingest.NewDecoder(rules.NewEngine(map[string]rules.Action{
+		rules.RulePaymentAmount:  rules.ActionReject,
+		rules.RuleItemTotalPrice: rules.ActionWarn,
+	}))
+
+	_, result, err := decoder.Decode(encode(t, order))
+
+	rejection, ok := ingest.AsRejection(err)
+	require.True(t, ok)
+	require.Len(t, rejection.Errors, 1)
+	assert.Contains(t, rejection.Errors[0], rules.RulePaymentAmount+": payment amount 1")
+	assert.ErrorContains(t, err, "rules failed: "+rules.RulePaymentAmount)
+	assert.Len(t, result.ByAction(rules.ActionWarn), 1)
+}
+
+func TestDecoder_FlagsFromRules(t *testing.T) {
+	order := validOrder()
+	order.Payment.Amount = 1
+
+	decoded, _, err := ingest.NewDecoder(rules.NewEngine(nil)).Decode(encode(t, order))
+
+	require.NoError(t, err)
+	require.Len(t, decoded.Flags, 1)
+	assert.Equal(t, rules.RulePaymentAmount, decoded.Flags[0].Rule)
+}
@@ -0,0 +1,46 @@
+package invalidation
+
+import (
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// Listener delivers notifications for the channels it listens on. A nil
+// notification means the connection was re-established and notifications
+// sent in between may have been lost.
+type Listener interface {
+	Listen(string) error
+	Notifications() <-chan *pq.Notification
+	Ping() error
+	Close() error
+}
+
+type pqListener struct {
+	*pq.Listener
+}
+
+// NewPQListener returns a Listener backed by a dedicated Postgres connection
+// that reconnects on its own with a backoff between minReconnect and
+// maxReconnect.
+func NewPQListener(dsn string, minReconnect, maxReconnect time.Duration, logger logger.Logger) Listener {
+	listener := pq.NewListener(dsn, minReconnect, maxReconnect, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnected:
+			logger.Info("invalidation: listener connected")
+		case pq.ListenerEventDisconnected:
+			logger.Warn("invalidation: listener disconnected", zap.Error(err))
+		case pq.ListenerEventReconnected:
+			logger.Info("invalidation: listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			logger.Error("invalidation: listener connection attempt failed", zap.Error(err))
+		}
+	})
+	return &pqListener{Listener: listener}
+}
+
+func (l *pqListener) Notifications() <-chan *pq.Notification {
+	return l.Notify
+}
@@ -0,0 +1,175 @@
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/warmup"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+)
+
+const (
+	ModeEvict   = "evict"
+	ModeRefresh = "refresh"
+)
+
+const (
+	DefaultPingInterval = 90 * time.Second
+	DefaultRewarmLimit  = 100
+)
+
+type Config struct {
+	Channel      string
+	Origin       string
+	Mode         string
+	RewarmLimit  int
+	PingInterval time.Duration
+}
+
+type Service struct {
+	listener Listener
+	repo     repository.RepositoryProvider
+	cache    cache.Cache
+	warmer   warmup.WarmupProvider
+	logger   logger.Logger
+	cfg      Config
+	wg       sync.WaitGroup
+}
+
+func NewService(listener Listener, repo repository.RepositoryProvider, cache cache.Cache, warmer warmup.WarmupProvider, logger logger.Logger, cfg Config) (*Service, error) {
+	if cfg.Channel == "" {
+		return nil, fmt.Errorf("%w: invalidation channel is empty", srvcerrors.ErrInvalidInput)
+	}
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = ModeRefresh
+	case ModeEvict, ModeRefresh:
+	default:
+		return nil, fmt.Errorf("%w: unknown invalidation mode %q", srvcerrors.ErrInvalidInput, cfg.Mode)
+	}
+	if cfg.RewarmLimit <= 0 {
+		cfg.RewarmLimit = DefaultRewarmLimit
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = DefaultPingInterval
+	}
+
+	return &Service{
+		listener: listener,
+		repo:     repo,
+		cache:    cache,
+		warmer:   warmer,
+		logger:   logger,
+		cfg:      cfg,
+	}, nil
+}
+
+func (s *Service) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Service) run(ctx context.Context) {
+	defer s.listener.Close()
+
+	listening := make(chan error, 1)
+	go func() {
+		listening <- s.listener.Listen(s.cfg.Channel)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return
+	case err := <-listening:
+		if err != nil {
+			s.logger.Error("invalidation: failed to listen", zap.String("channel", s.cfg.Channel), zap.Error(err))
+			return
+		}
+	}
+	s.logger.Info("invalidation: listening for order changes",
+		zap.String("channel", s.cfg.Channel),
+		zap.String("mode", s.cfg.Mode),
+		zap.String("origin", s.cfg.Origin))
+
+	ticker := time.NewTicker(s.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-s.listener.Notifications():
+			if !ok {
+				return
+			}
+			if n == nil {
+				s.resync()
+				continue
+			}
+			s.handle(ctx, n.Extra)
+		case <-ticker.C:
+			if err := s.listener.Ping(); err != nil {
+				s.logger.Warn("invalidation: listener ping failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *Service) handle(ctx context.Context, payload string) {
+	var change model.OrderChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil || change.OrderUID == "" {
+		s.logger.Warn("invalidation: malformed notification", zap.String("payload", payload), zap.Error(err))
+		return
+	}
+	if s.cfg.Origin != "" && change.Origin == s.cfg.Origin {
+		return
+	}
+
+	if !s.cache.Delete(change.OrderUID) {
+		return
+	}
+	if s.cfg.Mode == ModeEvict {
+		s.logger.Debug("invalidation: order evicted", zap.String("order_uid", change.OrderUID))
+		return
+	}
+
+	orders, err := s.repo.GetOrdersByUIDs(ctx, []string{change.OrderUID})
+	if err != nil {
+		s.logger.Error("invalidation: failed to refresh order, left evicted",
+			zap.String("order_uid", change.OrderUID),
+			zap.Error(err))
+		return
+	}
+	// A newer version cached meanwhile by this instance must not be overwritten.
+	if order, ok := orders[change.OrderUID]; ok {
+		s.cache.SetOrderIfAbsent(order)
+	}
+	s.logger.Debug("invalidation: order refreshed", zap.String("order_uid", change.OrderUID))
+}
+
+// resync drops the whole cache after a reconnect, since any notification sent
+// while the listener was disconnected is lost, and warms it up again.
+func (s *Service) resync() {
+	s.logger.Warn("invalidation: notifications may have been missed, clearing cache",
+		zap.Int("rewarm_limit", s.cfg.RewarmLimit))
+
+	s.cache.Clear()
+	if _, err := s.warmer.Trigger(s.cfg.RewarmLimit); err != nil {
+		s.logger.Warn("invalidation: failed to trigger cache rewarm", zap.Error(err))
+	}
+}
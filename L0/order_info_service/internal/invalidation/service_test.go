@@ -0,0 +1,249 @@
+package invalidation_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/invalidation"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/warmup"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testChannel = "order_changes"
+	testOrigin  = "instance-a"
+)
+
+type fakeListener struct {
+	notifications chan *pq.Notification
+	listenErr     error
+	mu            sync.Mutex
+	channel       string
+	closed        bool
+}
+
+func newFakeListener() *fakeListener {
+	return &fakeListener{notifications: make(chan *pq.Notification)}
+}
+
+func (f *fakeListener) Listen(channel string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.channel = channel
+	return f.listenErr
+}
+
+func (f *fakeListener) Notifications() <-chan *pq.Notification {
+	return f.notifications
+}
+
+func (f *fakeListener) Ping() error {
+	return nil
+}
+
+func (f *fakeListener) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeListener) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+func (f *fakeListener) send(payload string) {
+	f.notifications <- &pq.Notification{Channel: testChannel, Extra: payload}
+}
+
+// flush returns once every notification sent before it has been handled.
+func (f *fakeListener) flush() {
+	f.send(`{"order_uid":"flush","origin":"instance-b"}`)
+}
+
+type fakeRepository struct {
+	repository.RepositoryProvider
+	orders map[string]*model.Order
+	err    error
+}
+
+func (f *fakeRepository) GetOrdersByUIDs(_ context.Context, orderUIDs []string) (map[string]*model.Order, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	orders := make(map[string]*model.Order)
+	for _, orderUID := range orderUIDs {
+		if order, ok := f.orders[orderUID]; ok {
+			orders[orderUID] = order
+		}
+	}
+	return orders, nil
+}
+
+type fakeWarmer struct {
+	mu     sync.Mutex
+	limits []int
+}
+
+func (f *fakeWarmer) Trigger(limit int) (warmup.Progress, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.limits = append(f.limits, limit)
+	return warmup.Progress{State: warmup.StateRunning, Limit: limit}, nil
+}
+
+func (f *fakeWarmer) Progress() warmup.Progress {
+	return warmup.Progress{}
+}
+
+func (f *fakeWarmer) triggered() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.limits...)
+}
+
+func newOrder(uid, track string) *model.Order {
+	return &model.Order{OrderUID: uid, TrackNumber: track}
+}
+
+func startService(t *testing.T, listener *fakeListener, repo repository.RepositoryProvider, c cache.Cache, warmer warmup.WarmupProvider, mode string) {
+	t.Helper()
+	svc, err := invalidation.NewService(listener, repo, c, warmer, &loggertest.MockLogger{}, invalidation.Config{
+		Channel:     testChannel,
+		Origin:      testOrigin,
+		Mode:        mode,
+		RewarmLimit: 50,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		svc.Wait()
+		require.True(t, listener.isClosed())
+	})
+}
+
+func requireTrack(t *testing.T, c cache.Cache, uid, track string) {
+	t.Helper()
+	got, err := c.GetOrderByUID(uid)
+	require.NoError(t, err)
+	require.Equal(t, track, got.TrackNumber)
+}
+
+func TestRefreshMode(t *testing.T) {
+	listener := newFakeListener()
+	c := cache.NewLocalCache()
+	c.SetOrder(newOrder("order-1", "stale"))
+	c.SetOrder(newOrder("order-2", "stale"))
+	c.SetOrder(newOrder("order-3", "stale"))
+	repo := &fakeRepository{orders: map[string]*model.Order{
+		"order-1": newOrder("order-1", "fresh"),
+		"order-2": newOrder("order-2", "fresh"),
+		"order-4": newOrder("order-4", "fresh"),
+	}}
+	startService(t, listener, repo, c, &fakeWarmer{}, invalidation.ModeRefresh)
+
+	listener.send(`{"order_uid":"order-1","origin":"instance-b"}`)
+	listener.send(`{"order_uid":"order-2","origin":"instance-a"}`)
+	listener.send(`{"order_uid":"order-3","origin":"instance-b"}`)
+	listener.send(`{"order_uid":"order-4","origin":"instance-b"}`)
+	listener.send(`not json`)
+	listener.send(`{"order_uid":"order-2","origin":"instance-b"}`)
+	listener.flush()
+
+	requireTrack(t, c, "order-1", "fresh")
+	requireTrack(t, c, "order-2", "fresh")
+	_, err := c.GetOrderByUID("order-3")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+	_, err = c.GetOrderByUID("order-4")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+}
+
+func TestRefreshMode_RepositoryError(t *testing.T) {
+	listener := newFakeListener()
+	c := cache.NewLocalCache()
+	c.SetOrder(newOrder("order-1", "stale"))
+	startService(t, listener, &fakeRepository{err: errors.New("db down")}, c, &fakeWarmer{}, invalidation.ModeRefresh)
+
+	listener.send(`{"order_uid":"order-1","origin":"instance-b"}`)
+	listener.flush()
+
+	_, err := c.GetOrderByUID("order-1")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+}
+
+func TestEvictMode(t *testing.T) {
+	listener := newFakeListener()
+	c := cache.NewLocalCache()
+	c.SetOrder(newOrder("order-1", "stale"))
+	c.SetOrder(newOrder("order-2", "own"))
+	repo := &fakeRepository{orders: map[string]*model.Order{"order-1": newOrder("order-1", "fresh")}}
+	startService(t, listener, repo, c, &fakeWarmer{}, invalidation.ModeEvict)
+
+	listener.send(`{"order_uid":"order-1","origin":"instance-b"}`)
+	listener.send(`{"order_uid":"order-2","origin":"instance-a"}`)
+	listener.flush()
+
+	_, err := c.GetOrderByUID("order-1")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+	requireTrack(t, c, "order-2", "own")
+}
+
+func TestReconnectClearsAndRewarms(t *testing.T) {
+	listener := newFakeListener()
+	c := cache.NewLocalCache()
+	c.SetOrder(newOrder("order-1", "cached"))
+	warmer := &fakeWarmer{}
+	startService(t, listener, &fakeRepository{}, c, warmer, invalidation.ModeRefresh)
+
+	listener.notifications <- nil
+	listener.flush()
+
+	require.Equal(t, 0, c.Stats().Orders)
+	require.Equal(t, []int{50}, warmer.triggered())
+}
+
+func TestListenError(t *testing.T) {
+	listener := newFakeListener()
+	listener.listenErr = errors.New("syntax error")
+	svc, err := invalidation.NewService(listener, &fakeRepository{}, cache.NewLocalCache(), &fakeWarmer{}, &loggertest.MockLogger{},
+		invalidation.Config{Channel: testChannel})
+	require.NoError(t, err)
+
+	svc.Start(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("service did not stop after listen error")
+	}
+	require.True(t, listener.isClosed())
+}
+
+func TestNewService_InvalidConfig(t *testing.T) {
+	_, err := invalidation.NewService(newFakeListener(), &fakeRepository{}, cache.NewLocalCache(), &fakeWarmer{}, &loggertest.MockLogger{},
+		invalidation.Config{})
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+
+	_, err = invalidation.NewService(newFakeListener(), &fakeRepository{}, cache.NewLocalCache(), &fakeWarmer{}, &loggertest.MockLogger{},
+		invalidation.Config{Channel: testChannel, Mode: "drop"})
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+}
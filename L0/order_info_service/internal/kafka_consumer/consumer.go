@@ -2,80 +2,98 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/config"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/rules"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
-	"github.com/go-playground/validator/v10"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+const tracerName = "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/kafka_consumer"
+
+type Quarantiner interface {
+	Quarantine(context.Context, *model.QuarantinedOrder) error
+}
+
 type KafkaConsumerInterface interface {
 	Consume(ctx context.Context, handler func(context.Context, *model.Order) error) error
 	Close() error
 }
 
-type KafkaConfig struct {
-	BootstrapServers  string        `env:"KAFKA_BOOTSTRAP_SERVERS" env-required:"true"`
-	GroupID           string        `env:"KAFKA_GROUP_ID" env-required:"true"`
-	Topic             string        `env:"KAFKA_TOPIC" env-required:"true"`
-	AutoOffset        string        `env:"KAFKA_AUTO_OFFSET" default:"earliest"`
-	SessionTimeout    time.Duration `env:"KAFKA_SESSION_TIMEOUT" default:"10s"`
-	HeartbeatInterval time.Duration `env:"KAFKA_HEARTBEAT_INTERVAL" default:"3s"`
-	MaxPollInterval   time.Duration `env:"KAFKA_MAX_POLL_INTERVAL" default:"5m"`
-	MaxRetries        int           `env:"KAFKA_MAX_RETRIES" default:"3"`
-	CleanupInterval   time.Duration `env:"KAFKA_CLEANUP_INTERVAL" default:"5m"`
-	MaxAge            time.Duration `env:"KAFKA_MAX_AGE" default:"30m"`
-}
-
 type KafkaConsumer struct {
 	consumer       *kafka.Consumer
 	topic          string
 	logger         logger.Logger
-	config         KafkaConfig
-	validator      *validator.Validate
+	config         config.Kafka
+	decoder        *ingest.Decoder
+	quarantine     Quarantiner
 	processed      map[string]time.Time
 	processedMutex sync.RWMutex
 	cleanupTicker  *time.Ticker
 	cleanupDone    chan struct{}
+	paused         atomic.Bool
+}
+
+type Option func(*KafkaConsumer)
+
+func WithDecoder(decoder *ingest.Decoder) Option {
+	return func(k *KafkaConsumer) {
+		k.decoder = decoder
+	}
 }
 
-func NewKafkaConsumer(config KafkaConfig, logger logger.Logger) (*KafkaConsumer, error) {
+func WithQuarantine(quarantine Quarantiner) Option {
+	return func(k *KafkaConsumer) {
+		k.quarantine = quarantine
+	}
+}
+
+func NewKafkaConsumer(cfg config.Kafka, logger logger.Logger, opts ...Option) (*KafkaConsumer, error) {
 	c, err := kafka.NewConsumer(&kafka.ConfigMap{
-		"bootstrap.servers":     config.BootstrapServers,
-		"group.id":              config.GroupID,
-		"auto.offset.reset":     config.AutoOffset,
+		"bootstrap.servers":     cfg.BootstrapServers,
+		"group.id":              cfg.GroupID,
+		"auto.offset.reset":     cfg.AutoOffset,
 		"enable.auto.commit":    false,
-		"session.timeout.ms":    int(config.SessionTimeout.Milliseconds()),
-		"heartbeat.interval.ms": int(config.HeartbeatInterval.Milliseconds()),
-		"max.poll.interval.ms":  int(config.MaxPollInterval.Milliseconds()),
+		"session.timeout.ms":    int(cfg.SessionTimeout.Milliseconds()),
+		"heartbeat.interval.ms": int(cfg.HeartbeatInterval.Milliseconds()),
+		"max.poll.interval.ms":  int(cfg.MaxPollInterval.Milliseconds()),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", srvcerrors.ErrKafka, err)
 	}
 
-	if err := c.SubscribeTopics([]string{config.Topic}, nil); err != nil {
-		_ = c.Close()
-		return nil, fmt.Errorf("%w: %v", srvcerrors.ErrKafka, err)
-	}
-
 	kc := &KafkaConsumer{
 		consumer:    c,
-		topic:       config.Topic,
+		topic:       cfg.Topic,
 		logger:      logger,
-		config:      config,
-		validator:   validator.New(),
+		config:      cfg,
+		decoder:     ingest.NewDecoder(nil),
 		processed:   make(map[string]time.Time),
 		cleanupDone: make(chan struct{}),
 	}
 
+	for _, opt := range opts {
+		opt(kc)
+	}
+
+	if err := c.SubscribeTopics([]string{cfg.Topic}, kc.rebalance); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("%w: %v", srvcerrors.ErrKafka, err)
+	}
+
 	kc.cleanupTicker = time.NewTicker(kc.config.CleanupInterval)
 	go kc.startCleanupRoutine()
 
@@ -138,8 +156,12 @@ func (k *KafkaConsumer) Consume(ctx context.Context, handler func(context.Contex
 				continue
 			}
 
-			if err := k.processMessageWithRetry(ctx, msg, handler); err != nil {
-				k.logger.Error("failed to process message after all retries",
+			msgCtx, span := k.startMessageSpan(ctx, msg)
+			msgCtx = logger.WithFields(msgCtx, zap.String("correlation_id", correlationID(msg)))
+			err = k.processMessageWithRetry(msgCtx, msg, handler)
+			tracing.End(span, err)
+			if err != nil {
+				logger.FromContext(msgCtx, k.logger).Error("failed to process message after all retries",
 					zap.String("topic", k.topic),
 					zap.String("key", string(msg.Key)),
 					zap.Int32("partition", msg.TopicPartition.Partition),
@@ -151,6 +173,7 @@ func (k *KafkaConsumer) Consume(ctx context.Context, handler func(context.Contex
 }
 
 func (k *KafkaConsumer) processMessageWithRetry(ctx context.Context, msg *kafka.Message, handler func(context.Context, *model.Order) error) error {
+	log := logger.FromContext(ctx, k.logger)
 	processedKey := fmt.Sprintf("%s_%d_%d", string(msg.Key), msg.TopicPartition.Partition, msg.TopicPartition.Offset)
 
 	k.processedMutex.RLock()
@@ -158,72 +181,75 @@ func (k *KafkaConsumer) processMessageWithRetry(ctx context.Context, msg *kafka.
 	k.processedMutex.RUnlock()
 
 	if exists {
-		k.logger.Debug("skipping already processed message",
+		log.Debug("skipping already processed message",
 			zap.String("processed_key", processedKey),
 			zap.String("key", string(msg.Key)),
 			zap.Int32("partition", msg.TopicPartition.Partition),
 			zap.Int64("offset", int64(msg.TopicPartition.Offset)))
 
 		if _, err := k.consumer.CommitMessage(msg); err != nil {
-			k.logger.Error("failed to commit duplicate message",
+			log.Error("failed to commit duplicate message",
 				zap.String("processed_key", processedKey),
 				zap.Error(fmt.Errorf("%w: %v", srvcerrors.ErrKafka, err)))
 		}
 		return nil
 	}
 
-	var ord model.Order
-	if err := json.Unmarshal(msg.Value, &ord); err != nil {
-		k.logger.Error("failed to unmarshal kafka message into model.Order",
+	ord, result, err := k.decoder.Decode(msg.Value)
+	if err != nil {
+		log.Warn("order rejected",
 			zap.String("topic", k.topic),
 			zap.String("key", string(msg.Key)),
 			zap.Int32("partition", msg.TopicPartition.Partition),
 			zap.Int64("offset", int64(msg.TopicPartition.Offset)),
 			zap.Error(err))
 
-		if _, cerr := k.consumer.CommitMessage(msg); cerr != nil {
-			k.logger.Error("failed to commit offset after unmarshal error",
-				zap.Error(fmt.Errorf("%w: %v", srvcerrors.ErrKafka, cerr)))
-			return fmt.Errorf("%w: failed to commit after unmarshal: %v", srvcerrors.ErrKafka, cerr)
+		if qerr := k.quarantineWithRetry(ctx, msg, err); qerr != nil {
+			if ctx.Err() != nil {
+				// Shutting down: leave the offset uncommitted so the order is
+				// quarantined after the restart.
+				return qerr
+			}
+			log.Error("failed to quarantine rejected order after all retries, committing offset and dropping it",
+				zap.String("key", string(msg.Key)),
+				zap.Int32("partition", msg.TopicPartition.Partition),
+				zap.Int64("offset", int64(msg.TopicPartition.Offset)),
+				zap.Error(qerr))
 		}
-		k.logger.Warn("skipped invalid json message after commit", zap.String("key", string(msg.Key)))
-		return nil
-	}
-
-	if verr := k.validateOrder(&ord); verr != nil {
-		k.logger.Warn("order validation failed",
-			zap.String("topic", k.topic),
-			zap.String("key", string(msg.Key)),
-			zap.Int32("partition", msg.TopicPartition.Partition),
-			zap.Int64("offset", int64(msg.TopicPartition.Offset)),
-			zap.Error(verr))
 
 		if _, cerr := k.consumer.CommitMessage(msg); cerr != nil {
-			k.logger.Error("failed to commit offset after validation error",
+			log.Error("failed to commit offset after rejecting order",
 				zap.Error(fmt.Errorf("%w: %v", srvcerrors.ErrKafka, cerr)))
-			return fmt.Errorf("%w: failed to commit after validation error: %v", srvcerrors.ErrKafka, cerr)
+			return fmt.Errorf("%w: failed to commit after rejecting order: %v", srvcerrors.ErrKafka, cerr)
 		}
-		k.logger.Info("committed offset and skipped invalid order", zap.String("key", string(msg.Key)))
+		log.Info("committed offset and skipped rejected order", zap.String("key", string(msg.Key)))
 		return nil
 	}
 
+	for _, v := range result.ByAction(rules.ActionWarn) {
+		log.Warn("order business rule violated",
+			zap.String("order_uid", ord.OrderUID),
+			zap.String("rule", v.Rule),
+			zap.String("message", v.Message))
+	}
+
 	var lastErr error
 	for i := 0; i <= k.config.MaxRetries; i++ {
 		if i > 0 {
 			backoff := time.Duration(1<<uint(i)) * time.Second
-			k.logger.Debug("retrying message after error",
+			log.Debug("retrying message after error",
 				zap.String("key", string(msg.Key)),
 				zap.Int("attempt", i),
 				zap.Duration("backoff", backoff))
 			time.Sleep(backoff)
 		}
 
-		handlerCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		handlerCtx, cancel := context.WithTimeout(ctx, k.config.HandlerTimeout)
 		defer cancel()
 
-		if err := handler(handlerCtx, &ord); err != nil {
+		if err := handler(handlerCtx, ord); err != nil {
 			lastErr = err
-			k.logger.Warn("handler error",
+			log.Warn("handler error",
 				zap.String("key", string(msg.Key)),
 				zap.Int("attempt", i),
 				zap.Int("max_retries", k.config.MaxRetries),
@@ -233,11 +259,11 @@ func (k *KafkaConsumer) processMessageWithRetry(ctx context.Context, msg *kafka.
 				continue
 			}
 
-			k.logger.Error("permanent handler error, committing offset and skipping message",
+			log.Error("permanent handler error, committing offset and skipping message",
 				zap.String("key", string(msg.Key)),
 				zap.Error(err))
 			if _, cerr := k.consumer.CommitMessage(msg); cerr != nil {
-				k.logger.Error("failed to commit offset after permanent handler error",
+				log.Error("failed to commit offset after permanent handler error",
 					zap.Error(fmt.Errorf("%w: %v", srvcerrors.ErrKafka, cerr)))
 				return fmt.Errorf("%w: failed to commit after permanent handler error: %v", srvcerrors.ErrKafka, cerr)
 			}
@@ -250,7 +276,7 @@ func (k *KafkaConsumer) processMessageWithRetry(ctx context.Context, msg *kafka.
 		}
 
 		if _, cerr := k.consumer.CommitMessage(msg); cerr != nil {
-			k.logger.Error("failed to commit message offset",
+			log.Error("failed to commit message offset",
 				zap.String("key", string(msg.Key)),
 				zap.Error(fmt.Errorf("%w: %v", srvcerrors.ErrKafka, cerr)))
 			return fmt.Errorf("%w: %v", srvcerrors.ErrKafka, cerr)
@@ -260,7 +286,7 @@ func (k *KafkaConsumer) processMessageWithRetry(ctx context.Context, msg *kafka.
 		k.processed[processedKey] = time.Now()
 		k.processedMutex.Unlock()
 
-		k.logger.Info("message successfully processed",
+		log.Info("message successfully processed",
 			zap.String("key", string(msg.Key)),
 			zap.String("topic", k.topic),
 			zap.Int32("partition", msg.TopicPartition.Partition),
@@ -268,12 +294,12 @@ func (k *KafkaConsumer) processMessageWithRetry(ctx context.Context, msg *kafka.
 		return nil
 	}
 
-	k.logger.Error("all retries exhausted, committing offset and skipping message",
+	log.Error("all retries exhausted, committing offset and skipping message",
 		zap.String("key", string(msg.Key)),
 		zap.Error(lastErr))
 
 	if _, cerr := k.consumer.CommitMessage(msg); cerr != nil {
-		k.logger.Error("failed to commit offset after exhausting retries",
+		log.Error("failed to commit offset after exhausting retries",
 			zap.Error(fmt.Errorf("%w: %v", srvcerrors.ErrKafka, cerr)))
 		return fmt.Errorf("%w: failed to commit after retries: %v", srvcerrors.ErrKafka, cerr)
 	}
@@ -285,6 +311,40 @@ func (k *KafkaConsumer) processMessageWithRetry(ctx context.Context, msg *kafka.
 	return lastErr
 }
 
+// startMessageSpan continues the trace the producer put into the message
+// headers, so the upsert and later reads of the order share one trace.
+func (k *KafkaConsumer) startMessageSpan(ctx context.Context, msg *kafka.Message) (context.Context, trace.Span) {
+	ctx = tracing.ExtractKafkaHeaders(ctx, msg)
+	return tracing.Tracer(tracerName).Start(ctx, k.topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(k.topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(int(msg.TopicPartition.Partition))),
+			semconv.MessagingKafkaMessageOffset(int(msg.TopicPartition.Offset)),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+		))
+}
+
+// CorrelationIDHeader lets producers choose the id logged for a message.
+const CorrelationIDHeader = "correlation_id"
+
+// correlationID identifies a message in logs: the producer supplied id if
+// there is one, otherwise its position in the topic.
+func correlationID(msg *kafka.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == CorrelationIDHeader && len(h.Value) > 0 {
+			return string(h.Value)
+		}
+	}
+	topic := ""
+	if msg.TopicPartition.Topic != nil {
+		topic = *msg.TopicPartition.Topic
+	}
+	return fmt.Sprintf("%s-%d-%d", topic, msg.TopicPartition.Partition, msg.TopicPartition.Offset)
+}
+
 func isTemporaryError(err error) bool {
 	return errors.Is(err, srvcerrors.ErrDatabase)
 }
@@ -297,6 +357,187 @@ func isTimeoutError(err error) bool {
 	return false
 }
 
+// quarantineWithRetry retries quarantineMessage with the same backoff as the
+// handler, so a short database outage does not drop rejected orders while a
+// message that can never be stored does not block the partition.
+func (k *KafkaConsumer) quarantineWithRetry(ctx context.Context, msg *kafka.Message, err error) error {
+	var qerr error
+	for i := 0; i <= k.config.MaxRetries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return qerr
+			case <-time.After(time.Duration(1<<uint(i)) * time.Second):
+			}
+		}
+		if qerr = k.quarantineMessage(ctx, msg, err); qerr == nil {
+			return nil
+		}
+	}
+	return qerr
+}
+
+// quarantineMessage stores a rejected order for manual review. It returns an
+// error only if the order should have been stored and was not.
+func (k *KafkaConsumer) quarantineMessage(ctx context.Context, msg *kafka.Message, err error) error {
+	if k.quarantine == nil {
+		return nil
+	}
+
+	rejection, ok := ingest.AsRejection(err)
+	if !ok {
+		return nil
+	}
+
+	q := &model.QuarantinedOrder{
+		Payload:   msg.Value,
+		Stage:     rejection.Stage,
+		Errors:    rejection.Errors,
+		Topic:     k.topic,
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+		Key:       string(msg.Key),
+	}
+	if !msg.Timestamp.IsZero() {
+		q.KafkaTimestamp = &msg.Timestamp
+	}
+
+	// The store is not cancelled with ctx so a shutdown does not cut it short,
+	// but it is bounded so a hung database cannot hang the shutdown either.
+	qctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), k.config.HandlerTimeout)
+	defer cancel()
+
+	if qerr := k.quarantine.Quarantine(qctx, q); qerr != nil {
+		logger.FromContext(ctx, k.logger).Warn("failed to quarantine rejected order",
+			zap.String("key", string(msg.Key)),
+			zap.Int32("partition", msg.TopicPartition.Partition),
+			zap.Int64("offset", int64(msg.TopicPartition.Offset)),
+			zap.Error(qerr))
+		return qerr
+	}
+	return nil
+}
+
+func (k *KafkaConsumer) rebalance(c *kafka.Consumer, event kafka.Event) error {
+	assigned, ok := event.(kafka.AssignedPartitions)
+	if !ok || !k.paused.Load() {
+		return nil
+	}
+
+	if err := c.Assign(assigned.Partitions); err != nil {
+		return err
+	}
+	k.logger.Info("keeping newly assigned partitions paused",
+		zap.Int("partitions", len(assigned.Partitions)))
+	return c.Pause(assigned.Partitions)
+}
+
+func (k *KafkaConsumer) Pause() error {
+	k.paused.Store(true)
+
+	partitions, err := k.consumer.Assignment()
+	if err != nil {
+		return fmt.Errorf("%w: failed to get assignment: %v", srvcerrors.ErrKafka, err)
+	}
+	if err := k.consumer.Pause(partitions); err != nil {
+		return fmt.Errorf("%w: failed to pause partitions: %v", srvcerrors.ErrKafka, err)
+	}
+
+	k.logger.Info("kafka consumer paused",
+		zap.String("topic", k.topic),
+		zap.Int("partitions", len(partitions)))
+	return nil
+}
+
+func (k *KafkaConsumer) Resume() error {
+	k.paused.Store(false)
+
+	partitions, err := k.consumer.Assignment()
+	if err != nil {
+		return fmt.Errorf("%w: failed to get assignment: %v", srvcerrors.ErrKafka, err)
+	}
+	if err := k.consumer.Resume(partitions); err != nil {
+		return fmt.Errorf("%w: failed to resume partitions: %v", srvcerrors.ErrKafka, err)
+	}
+
+	k.logger.Info("kafka consumer resumed",
+		zap.String("topic", k.topic),
+		zap.Int("partitions", len(partitions)))
+	return nil
+}
+
+func (k *KafkaConsumer) Paused() bool {
+	return k.paused.Load()
+}
+
+func (k *KafkaConsumer) Seek(partition int32, offset int64) error {
+	if offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", srvcerrors.ErrInvalidInput)
+	}
+	return k.seek(partition, kafka.Offset(offset))
+}
+
+func (k *KafkaConsumer) SeekToTime(partition int32, ts time.Time) (int64, error) {
+	if err := k.checkAssigned(partition); err != nil {
+		return 0, err
+	}
+
+	offsets, err := k.consumer.OffsetsForTimes([]kafka.TopicPartition{{
+		Topic:     &k.topic,
+		Partition: partition,
+		Offset:    kafka.Offset(ts.UnixMilli()),
+	}}, 5000)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to look up offset for timestamp: %v", srvcerrors.ErrKafka, err)
+	}
+	if len(offsets) != 1 || offsets[0].Error != nil {
+		return 0, fmt.Errorf("%w: failed to look up offset for timestamp: %v", srvcerrors.ErrKafka, offsets)
+	}
+
+	offset := offsets[0].Offset
+	if offset < 0 {
+		offset = kafka.OffsetEnd
+	}
+	if err := k.seek(partition, offset); err != nil {
+		return 0, err
+	}
+	return int64(offset), nil
+}
+
+func (k *KafkaConsumer) seek(partition int32, offset kafka.Offset) error {
+	if err := k.checkAssigned(partition); err != nil {
+		return err
+	}
+
+	tp := kafka.TopicPartition{Topic: &k.topic, Partition: partition, Offset: offset}
+	if err := k.consumer.Seek(tp, 0); err != nil {
+		return fmt.Errorf("%w: failed to seek partition %d: %v", srvcerrors.ErrKafka, partition, err)
+	}
+
+	k.processedMutex.Lock()
+	k.processed = make(map[string]time.Time)
+	k.processedMutex.Unlock()
+
+	k.logger.Info("kafka consumer seeked",
+		zap.String("topic", k.topic),
+		zap.Int32("partition", partition),
+		zap.String("offset", offset.String()))
+	return nil
+}
+
+func (k *KafkaConsumer) checkAssigned(partition int32) error {
+	partitions, err := k.consumer.Assignment()
+	if err != nil {
+		return fmt.Errorf("%w: failed to get assignment: %v", srvcerrors.ErrKafka, err)
+	}
+	for _, tp := range partitions {
+		if tp.Partition == partition {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: partition %d is not assigned to this consumer", srvcerrors.ErrInvalidInput, partition)
+}
+
 func (k *KafkaConsumer) Close() error {
 	k.logger.Info("closing kafka consumer")
 
@@ -313,20 +554,3 @@ func (k *KafkaConsumer) Close() error {
 
 	return nil
 }
-
-func (k *KafkaConsumer) validateOrder(o *model.Order) error {
-	if o == nil {
-		return fmt.Errorf("order is nil")
-	}
-	if err := k.validator.Struct(o); err != nil {
-		if ve, ok := err.(validator.ValidationErrors); ok {
-			parts := make([]string, 0, len(ve))
-			for _, e := range ve {
-				parts = append(parts, fmt.Sprintf("%s: %s", e.Field(), e.Tag()))
-			}
-			return fmt.Errorf("validation failed: %s", strings.Join(parts, "; "))
-		}
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,33 @@
+package logger
+
+import "context"
+
+type fieldsKey struct{}
+
+// WithFields returns a context carrying fields that FromContext adds to the
+// logger, such as a request or correlation id.
+func WithFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	existing := FieldsFromContext(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+func FieldsFromContext(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]Field)
+	return fields
+}
+
+// FromContext returns l with the fields stored in ctx, or l itself if there
+// are none.
+func FromContext(ctx context.Context, l Logger) Logger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
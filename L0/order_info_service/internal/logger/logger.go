@@ -1,6 +1,11 @@
 package logger
 
 import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -10,76 +15,136 @@ type Logger interface {
 	Error(msg string, fields ...Field)
 	Debug(msg string, fields ...Field)
 	Warn(msg string, fields ...Field)
+	With(fields ...Field) Logger
+}
+
+// LevelController reads and changes the minimum level of a running logger.
+type LevelController interface {
+	Level() string
+	SetLevel(level string) error
 }
 
 type Field = zapcore.Field
 
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// DefaultRedactKeys are the customer contact fields of an order.
+var DefaultRedactKeys = []string{"address", "region", "email", "phone"}
+
+type Config struct {
+	Level  string
+	Format string
+	// RedactKeys are field names whose values are masked, matched
+	// case-insensitively at any depth of a logged value. Nil means
+	// DefaultRedactKeys.
+	RedactKeys []string
+	// RedactPatterns are regular expressions whose matches are masked in
+	// messages and in string values at any depth.
+	RedactPatterns []string
+}
+
 type ZapLogger struct {
 	logger *zap.Logger
+	level  zap.AtomicLevel
 }
 
-func NewLogger() (Logger, error) {
-	config := zap.NewProductionConfig()
-	
+func NewLogger(cfg Config) (*ZapLogger, error) {
+	return build(cfg, zapcore.Lock(os.Stderr))
+}
+
+func build(cfg Config, out zapcore.WriteSyncer) (*ZapLogger, error) {
+	level := zap.NewAtomicLevel()
+	if cfg.Level != "" {
+		if err := setLevel(level, cfg.Level); err != nil {
+			return nil, err
+		}
+	}
+
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.TimeKey = "timestamp"
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.EncoderConfig = encoderConfig
-	
-	logger, err := config.Build()
+
+	var encoder zapcore.Encoder
+	switch cfg.Format {
+	case "", FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case FormatConsole:
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("%w: unknown log format %q", srvcerrors.ErrInvalidInput, cfg.Format)
+	}
+
+	keys := cfg.RedactKeys
+	if keys == nil {
+		keys = DefaultRedactKeys
+	}
+	r, err := newRedactor(keys, cfg.RedactPatterns)
 	if err != nil {
 		return nil, err
 	}
-	
-	return &ZapLogger{logger: logger}, nil
+
+	var core zapcore.Core = &redactingCore{Core: zapcore.NewCore(encoder, out, level), redactor: r}
+	core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+
+	return &ZapLogger{
+		logger: zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zapcore.ErrorLevel)),
+		level:  level,
+	}, nil
 }
 
-func (l *ZapLogger) log(level zapcore.Level, msg string, fields ...Field) {
-	processedFields := make([]Field, 0, len(fields))
-	for _, field := range fields {
-		if isSensitiveField(field) {
-			field.String = "*****"
-			field.Interface = nil
-		}
-		processedFields = append(processedFields, field)
-	}
-	
-	switch level {
-		case zapcore.InfoLevel:
-			l.logger.Info(msg, processedFields...)
-		case zapcore.ErrorLevel:
-			l.logger.Error(msg, processedFields...)
-		case zapcore.DebugLevel:
-			l.logger.Debug(msg, processedFields...)
-		case zapcore.WarnLevel:
-			l.logger.Warn(msg, processedFields...)
-	}
+// With returns a logger that adds fields to every entry it writes.
+func (l *ZapLogger) With(fields ...Field) Logger {
+	return &ZapLogger{logger: l.logger.With(fields...), level: l.level}
 }
 
 func (l *ZapLogger) Info(msg string, fields ...Field) {
-	l.log(zapcore.InfoLevel, msg, fields...)
+	l.logger.Info(msg, fields...)
 }
 
 func (l *ZapLogger) Error(msg string, fields ...Field) {
-	l.log(zapcore.InfoLevel, msg, fields...)
+	l.logger.Error(msg, fields...)
 }
 
 func (l *ZapLogger) Debug(msg string, fields ...Field) {
-	l.log(zapcore.InfoLevel, msg, fields...)
+	l.logger.Debug(msg, fields...)
 }
 
 func (l *ZapLogger) Warn(msg string, fields ...Field) {
-	l.log(zapcore.InfoLevel, msg, fields...)
+	l.logger.Warn(msg, fields...)
+}
+
+func (l *ZapLogger) Level() string {
+	return l.level.Level().String()
+}
+
+// SetLevel changes the level of this logger and of every logger derived from
+// it with With.
+func (l *ZapLogger) SetLevel(level string) error {
+	return setLevel(l.level, level)
+}
+
+func (l *ZapLogger) Sync() error {
+	return l.logger.Sync()
+}
+
+// ParseLevel accepts debug, info, warn and error.
+func ParseLevel(level string) (zapcore.Level, error) {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil || parsed < zapcore.DebugLevel || parsed > zapcore.ErrorLevel {
+		return 0, fmt.Errorf("%w: unknown log level %q", srvcerrors.ErrInvalidInput, level)
+	}
+	return parsed, nil
 }
 
-func isSensitiveField(field Field) bool {
-	sensitiveFileds := map[string]struct{}{
-		"address":{},
-		"region":{},
-		"email":{},
-		"phone":{},
+func setLevel(atomic zap.AtomicLevel, level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
 	}
-	
-	_, exists := sensitiveFileds[field.String]
-	return exists
-}
\ No newline at end of file
+	atomic.SetLevel(parsed)
+	return nil
+}
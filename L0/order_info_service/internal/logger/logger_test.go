@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newObservedLogger() (*ZapLogger, *observer.ObservedLogs) {
+	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	core, logs := observer.New(level)
+	r, _ := newRedactor(DefaultRedactKeys, nil)
+	return &ZapLogger{logger: zap.New(&redactingCore{Core: core, redactor: r}), level: level}, logs
+}
+
+func TestWith(t *testing.T) {
+	l, logs := newObservedLogger()
+
+	child := l.With(zap.String("request_id", "req-1"))
+	child.Info("first", zap.Int("n", 1))
+	l.Info("second")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	require.Equal(t, map[string]interface{}{"request_id": "req-1", "n": int64(1)}, entries[0].ContextMap())
+	require.Empty(t, entries[1].ContextMap())
+}
+
+func TestFromContext(t *testing.T) {
+	l, logs := newObservedLogger()
+
+	require.Same(t, Logger(l), FromContext(context.Background(), l))
+
+	ctx := WithFields(context.Background(), zap.String("request_id", "req-1"))
+	ctx = WithFields(ctx, zap.String("correlation_id", "orders-0-42"))
+	require.Len(t, FieldsFromContext(ctx), 2)
+	require.Equal(t, ctx, WithFields(ctx))
+
+	FromContext(ctx, l).Warn("message")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	require.Equal(t, map[string]interface{}{
+		"request_id":     "req-1",
+		"correlation_id": "orders-0-42",
+	}, entries[0].ContextMap())
+}
+
+func TestLevels(t *testing.T) {
+	l, logs := newObservedLogger()
+
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 4)
+	require.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	require.Equal(t, zapcore.InfoLevel, entries[1].Level)
+	require.Equal(t, zapcore.WarnLevel, entries[2].Level)
+	require.Equal(t, zapcore.ErrorLevel, entries[3].Level)
+}
+
+func TestSetLevel(t *testing.T) {
+	l, logs := newObservedLogger()
+	child := l.With(zap.String("request_id", "req-1"))
+
+	require.NoError(t, l.SetLevel("warn"))
+	require.Equal(t, "warn", l.Level())
+
+	child.Info("dropped")
+	child.Warn("kept")
+	require.Len(t, logs.AllUntimed(), 1)
+
+	require.ErrorIs(t, l.SetLevel("loud"), srvcerrors.ErrInvalidInput)
+	require.ErrorIs(t, l.SetLevel("fatal"), srvcerrors.ErrInvalidInput)
+	require.Equal(t, "warn", l.Level())
+}
+
+func TestBuild(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := build(Config{Level: "debug", Format: FormatConsole}, zapcore.AddSync(&buf))
+	require.NoError(t, err)
+
+	l.Debug("console entry", zap.String("email", "user@example.com"))
+	require.Contains(t, buf.String(), "DEBUG")
+	require.Contains(t, buf.String(), "console entry")
+	require.Contains(t, buf.String(), `{"email": "*****"}`)
+
+	buf.Reset()
+	l, err = build(Config{}, zapcore.AddSync(&buf))
+	require.NoError(t, err)
+	l.Debug("dropped")
+	l.Info("json entry")
+	require.NotContains(t, buf.String(), "dropped")
+	require.Contains(t, buf.String(), `"msg":"json entry"`)
+	require.Contains(t, buf.String(), `"timestamp":`)
+
+	_, err = build(Config{Format: "xml"}, zapcore.AddSync(&buf))
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	_, err = build(Config{Level: "loud"}, zapcore.AddSync(&buf))
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	_, err = build(Config{RedactPatterns: []string{"("}}, zapcore.AddSync(&buf))
+	require.Error(t, err)
+}
@@ -0,0 +1,13 @@
+// Package loggertest provides a logger.Logger for tests.
+package loggertest
+
+import "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+
+// MockLogger discards everything logged to it.
+type MockLogger struct{}
+
+func (l *MockLogger) Info(msg string, fields ...logger.Field)   {}
+func (l *MockLogger) Error(msg string, fields ...logger.Field)  {}
+func (l *MockLogger) Debug(msg string, fields ...logger.Field)  {}
+func (l *MockLogger) Warn(msg string, fields ...logger.Field)   {}
+func (l *MockLogger) With(fields ...logger.Field) logger.Logger { return l }
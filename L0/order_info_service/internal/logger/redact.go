@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redactedValue = "*****"
+
+type redactor struct {
+	keys     map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+func newRedactor(keys, patterns []string) (*redactor, error) {
+	r := &redactor{keys: make(map[string]struct{}, len(keys))}
+	for _, key := range keys {
+		r.keys[strings.ToLower(key)] = struct{}{}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+func (r *redactor) enabled() bool {
+	return len(r.keys) > 0 || len(r.patterns) > 0
+}
+
+func (r *redactor) sensitiveKey(key string) bool {
+	_, ok := r.keys[strings.ToLower(key)]
+	return ok
+}
+
+func (r *redactor) redactString(s string) (string, bool) {
+	changed := false
+	for _, re := range r.patterns {
+		if re.MatchString(s) {
+			s = re.ReplaceAllLiteralString(s, redactedValue)
+			changed = true
+		}
+	}
+	return s, changed
+}
+
+func (r *redactor) fields(fields []Field) []Field {
+	if !r.enabled() || len(fields) == 0 {
+		return fields
+	}
+	redacted := make([]Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = r.field(field)
+	}
+	return redacted
+}
+
+func (r *redactor) field(f Field) Field {
+	if r.sensitiveKey(f.Key) {
+		return zap.String(f.Key, redactedValue)
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		if s, changed := r.redactString(f.String); changed {
+			f.String = s
+		}
+	case zapcore.ErrorType, zapcore.StringerType:
+		if len(r.patterns) == 0 {
+			return f
+		}
+		var s string
+		if err, ok := f.Interface.(error); ok {
+			s = err.Error()
+		} else if stringer, ok := f.Interface.(fmt.Stringer); ok {
+			s = stringer.String()
+		}
+		if s, changed := r.redactString(s); changed {
+			return zap.String(f.Key, s)
+		}
+	case zapcore.ReflectType, zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		if v, ok := toGeneric(f); ok {
+			if v, changed := r.walk(v); changed {
+				return zap.Any(f.Key, v)
+			}
+		}
+	}
+	return f
+}
+
+// toGeneric turns a structured field value into maps, slices and scalars,
+// keyed the way the value would be encoded in the log.
+func toGeneric(f Field) (interface{}, bool) {
+	if f.Type == zapcore.ReflectType {
+		data, err := json.Marshal(f.Interface)
+		if err != nil {
+			return nil, false
+		}
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, false
+		}
+		return v, true
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	v, ok := enc.Fields[f.Key]
+	return v, ok
+}
+
+func (r *redactor) walk(v interface{}) (interface{}, bool) {
+	changed := false
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, nested := range val {
+			if r.sensitiveKey(k) {
+				val[k] = redactedValue
+				changed = true
+				continue
+			}
+			if nested, ok := r.walk(nested); ok {
+				val[k] = nested
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i, nested := range val {
+			if nested, ok := r.walk(nested); ok {
+				val[i] = nested
+				changed = true
+			}
+		}
+	case string:
+		return r.redactString(val)
+	}
+	return v, changed
+}
+
+// redactingCore masks sensitive data before it reaches the encoder, for fields
+// passed at the call site as well as those attached with With.
+type redactingCore struct {
+	zapcore.Core
+	redactor *redactor
+}
+
+func (c *redactingCore) With(fields []Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactor.fields(fields)), redactor: c.redactor}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []Field) error {
+	ent.Message, _ = c.redactor.redactString(ent.Message)
+	return c.Core.Write(ent, c.redactor.fields(fields))
+}
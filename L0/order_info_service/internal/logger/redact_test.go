@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type contact struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+	Notes string `json:"notes"`
+}
+
+type order struct {
+	OrderUID string    `json:"order_uid"`
+	Delivery contact   `json:"delivery"`
+	Contacts []contact `json:"contacts"`
+}
+
+type marshaledContact struct {
+	email string
+}
+
+func (c marshaledContact) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("Email", c.email)
+	enc.AddString("kind", "customer")
+	return nil
+}
+
+func newRedactingLogger(t *testing.T, keys, patterns []string) (*ZapLogger, *observer.ObservedLogs) {
+	t.Helper()
+	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	core, logs := observer.New(level)
+	r, err := newRedactor(keys, patterns)
+	require.NoError(t, err)
+	return &ZapLogger{logger: zap.New(&redactingCore{Core: core, redactor: r}), level: level}, logs
+}
+
+func TestRedact_Keys(t *testing.T) {
+	l, logs := newRedactingLogger(t, DefaultRedactKeys, nil)
+
+	l.With(zap.String("Phone", "+79990000000")).Info("order",
+		zap.String("email", "user@example.com"),
+		zap.String("city", "Moscow"))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	require.Equal(t, map[string]interface{}{
+		"Phone": redactedValue,
+		"email": redactedValue,
+		"city":  "Moscow",
+	}, entries[0].ContextMap())
+}
+
+func TestRedact_Patterns(t *testing.T) {
+	l, logs := newRedactingLogger(t, nil, []string{`[\w.]+@[\w.]+`, `\+7\d{10}`})
+
+	l.Error("failed to notify user@example.com",
+		zap.String("note", "call +79990000000"),
+		zap.Error(errors.New("smtp: bad address user@example.com")),
+		zap.Int("attempt", 2))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	require.Equal(t, "failed to notify *****", entries[0].Message)
+	require.Equal(t, map[string]interface{}{
+		"note":    "call *****",
+		"error":   "smtp: bad address *****",
+		"attempt": int64(2),
+	}, entries[0].ContextMap())
+}
+
+func TestRedact_Nested(t *testing.T) {
+	l, logs := newRedactingLogger(t, DefaultRedactKeys, []string{`secret-\d+`})
+
+	o := order{
+		OrderUID: "uid-1",
+		Delivery: contact{Name: "Test", Phone: "+79990000000", Notes: "code secret-42"},
+		Contacts: []contact{{Name: "Alt", Phone: "+79991111111"}},
+	}
+	l.Info("order", zap.Any("order", o), zap.Object("customer", marshaledContact{email: "user@example.com"}))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	require.Equal(t, map[string]interface{}{
+		"order_uid": "uid-1",
+		"delivery":  map[string]interface{}{"name": "Test", "phone": redactedValue, "notes": "code *****"},
+		"contacts":  []interface{}{map[string]interface{}{"name": "Alt", "phone": redactedValue, "notes": ""}},
+	}, fields["order"])
+	require.Equal(t, map[string]interface{}{"Email": redactedValue, "kind": "customer"}, fields["customer"])
+	require.Equal(t, "+79990000000", o.Delivery.Phone)
+}
+
+func TestRedact_Untouched(t *testing.T) {
+	l, logs := newRedactingLogger(t, DefaultRedactKeys, nil)
+	c := struct {
+		OrderUID string `json:"order_uid"`
+	}{OrderUID: "uid-1"}
+
+	l.Info("order", zap.Any("order", &c))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	require.Same(t, &c, entries[0].Context[0].Interface)
+}
@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	HeaderEventID     = "event_id"
+	HeaderEventType   = "event_type"
+	HeaderContentType = "content-type"
+
+	contentTypeJSON = "application/json"
+	flushTimeoutMs  = 5000
+)
+
+type producer interface {
+	Produce(*kafka.Message, chan kafka.Event) error
+	Flush(int) int
+	Close()
+}
+
+// KafkaPublisher publishes outbox messages keyed by order uid, so that all
+// events of one order land in the same partition in the order they were
+// stored.
+type KafkaPublisher struct {
+	producer producer
+	topic    string
+}
+
+func NewKafkaPublisher(bootstrapServers, topic string) (*KafkaPublisher, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("%w: outbox topic is empty", srvcerrors.ErrInvalidInput)
+	}
+
+	p, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers":  bootstrapServers,
+		"enable.idempotence": true,
+		"acks":               "all",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", srvcerrors.ErrKafka, err)
+	}
+	return &KafkaPublisher{producer: p, topic: topic}, nil
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, msgs []*model.OutboxMessage) (int, error) {
+	deliveries := make(chan kafka.Event, len(msgs))
+	ok := make([]bool, len(msgs))
+
+	produced := 0
+	var produceErr error
+	for i, msg := range msgs {
+		err := p.producer.Produce(&kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &p.topic, Partition: kafka.PartitionAny},
+			Key:            []byte(msg.OrderUID),
+			Value:          msg.Payload,
+			Headers: []kafka.Header{
+				{Key: HeaderEventID, Value: []byte(msg.EventID)},
+				{Key: HeaderEventType, Value: []byte(msg.EventType)},
+				{Key: HeaderContentType, Value: []byte(contentTypeJSON)},
+			},
+			Opaque: i,
+		}, deliveries)
+		if err != nil {
+			produceErr = err
+			break
+		}
+		produced++
+	}
+
+	var deliveryErr error
+	for received := 0; received < produced; {
+		select {
+		case <-ctx.Done():
+			return deliveredPrefix(ok), ctx.Err()
+		case e := <-deliveries:
+			m, isMsg := e.(*kafka.Message)
+			if !isMsg {
+				continue
+			}
+			received++
+			if m.TopicPartition.Error != nil {
+				deliveryErr = m.TopicPartition.Error
+				continue
+			}
+			if i, isIndex := m.Opaque.(int); isIndex {
+				ok[i] = true
+			}
+		}
+	}
+
+	delivered := deliveredPrefix(ok)
+	if produceErr != nil {
+		return delivered, produceErr
+	}
+	return delivered, deliveryErr
+}
+
+func (p *KafkaPublisher) Close() {
+	p.producer.Flush(flushTimeoutMs)
+	p.producer.Close()
+}
+
+func deliveredPrefix(ok []bool) int {
+	for i, delivered := range ok {
+		if !delivered {
+			return i
+		}
+	}
+	return len(ok)
+}
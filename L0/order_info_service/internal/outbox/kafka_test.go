@@ -0,0 +1,70 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProducer reports deliveries right away, failing the message with the
+// order uid in fail.
+type fakeProducer struct {
+	produced []*kafka.Message
+	fail     string
+}
+
+func (p *fakeProducer) Produce(msg *kafka.Message, deliveries chan kafka.Event) error {
+	p.produced = append(p.produced, msg)
+	delivered := *msg
+	if string(msg.Key) == p.fail {
+		delivered.TopicPartition.Error = errors.New("message timed out")
+	}
+	deliveries <- &delivered
+	return nil
+}
+
+func (p *fakeProducer) Flush(int) int { return 0 }
+func (p *fakeProducer) Close()        {}
+
+func outboxMessages() []*model.OutboxMessage {
+	return []*model.OutboxMessage{
+		{ID: 1, EventID: "e1", OrderUID: "order-1", EventType: model.EventOrderCreated, Payload: []byte(`{"n":1}`)},
+		{ID: 2, EventID: "e2", OrderUID: "order-2", EventType: model.EventOrderCreated, Payload: []byte(`{"n":2}`)},
+		{ID: 3, EventID: "e3", OrderUID: "order-1", EventType: model.EventOrderUpdated, Payload: []byte(`{"n":3}`)},
+	}
+}
+
+func TestKafkaPublisher_Publish(t *testing.T) {
+	fake := &fakeProducer{}
+	p := &KafkaPublisher{producer: fake, topic: "orders-stored"}
+
+	delivered, err := p.Publish(context.Background(), outboxMessages())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+	require.Len(t, fake.produced, 3)
+
+	msg := fake.produced[2]
+	assert.Equal(t, "orders-stored", *msg.TopicPartition.Topic)
+	assert.Equal(t, "order-1", string(msg.Key))
+	assert.JSONEq(t, `{"n":3}`, string(msg.Value))
+	assert.Equal(t, []kafka.Header{
+		{Key: HeaderEventID, Value: []byte("e3")},
+		{Key: HeaderEventType, Value: []byte(model.EventOrderUpdated)},
+		{Key: HeaderContentType, Value: []byte(contentTypeJSON)},
+	}, msg.Headers)
+}
+
+func TestKafkaPublisher_Publish_DeliveryFails(t *testing.T) {
+	p := &KafkaPublisher{producer: &fakeProducer{fail: "order-2"}, topic: "orders-stored"}
+
+	delivered, err := p.Publish(context.Background(), outboxMessages())
+
+	require.Error(t, err)
+	assert.Equal(t, 1, delivered, "only the prefix before the failed message counts")
+}
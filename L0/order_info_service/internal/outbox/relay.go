@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultBatchSize      = 100
+	DefaultPollInterval   = time.Second
+	DefaultPublishTimeout = 10 * time.Second
+)
+
+// Publisher publishes outbox messages in order and returns how many of them,
+// counted from the start, were delivered.
+type Publisher interface {
+	Publish(context.Context, []*model.OutboxMessage) (int, error)
+}
+
+type Config struct {
+	BatchSize      int
+	PollInterval   time.Duration
+	PublishTimeout time.Duration
+}
+
+// Relay moves order stored events from the outbox to the publisher. Messages
+// are removed from the outbox only once delivered, so each one is published
+// at least once, and in the order the orders were stored.
+type Relay struct {
+	repo      repository.OutboxRepositoryProvider
+	publisher Publisher
+	logger    logger.Logger
+	cfg       Config
+	wg        sync.WaitGroup
+}
+
+func NewRelay(repo repository.OutboxRepositoryProvider, publisher Publisher, logger logger.Logger, cfg Config) *Relay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.PublishTimeout <= 0 {
+		cfg.PublishTimeout = DefaultPublishTimeout
+	}
+
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+		cfg:       cfg,
+	}
+}
+
+func (r *Relay) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx)
+	}()
+}
+
+func (r *Relay) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Relay) run(ctx context.Context) {
+	r.logger.Info("outbox: relay started",
+		zap.Int("batch_size", r.cfg.BatchSize),
+		zap.Duration("poll_interval", r.cfg.PollInterval))
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Drain(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("outbox: failed to relay messages", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("outbox: relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain relays full batches until the outbox is empty, another relay holds
+// it or publishing fails, and returns the number of delivered messages.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		delivered, err := r.repo.RelayOutbox(ctx, r.cfg.BatchSize, r.publish)
+		total += delivered
+		if err != nil {
+			return total, err
+		}
+		if delivered < r.cfg.BatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		r.logger.Debug("outbox: messages relayed", zap.Int("count", total))
+	}
+	return total, nil
+}
+
+func (r *Relay) publish(ctx context.Context, msgs []*model.OutboxMessage) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.PublishTimeout)
+	defer cancel()
+
+	delivered, err := r.publisher.Publish(ctx, msgs)
+	if err != nil {
+		return delivered, fmt.Errorf("%w: published %d of %d outbox messages: %v",
+			srvcerrors.ErrKafka, delivered, len(msgs), err)
+	}
+	return delivered, nil
+}
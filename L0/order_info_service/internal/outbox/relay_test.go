@@ -0,0 +1,126 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/outbox"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOutbox mimics the repository: delivered messages are removed from the
+// head of the queue.
+type fakeOutbox struct {
+	mu    sync.Mutex
+	queue []*model.OutboxMessage
+}
+
+func (f *fakeOutbox) RelayOutbox(ctx context.Context, limit int, publish repository.PublishFunc) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	batch := f.queue[:min(limit, len(f.queue))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	delivered, err := publish(ctx, batch)
+	f.queue = f.queue[delivered:]
+	return delivered, err
+}
+
+func (f *fakeOutbox) len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.queue)
+}
+
+type fakePublisher struct {
+	mu        sync.Mutex
+	published []string
+	failAt    string
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msgs []*model.OutboxMessage) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, msg := range msgs {
+		if msg.EventID == p.failAt {
+			return i, errors.New("broker unavailable")
+		}
+		p.published = append(p.published, msg.EventID)
+	}
+	return len(msgs), nil
+}
+
+func (p *fakePublisher) events() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.published...)
+}
+
+func messages(ids ...string) []*model.OutboxMessage {
+	msgs := make([]*model.OutboxMessage, len(ids))
+	for i, id := range ids {
+		msgs[i] = &model.OutboxMessage{ID: int64(i + 1), EventID: id, OrderUID: "order-" + id}
+	}
+	return msgs
+}
+
+func TestRelay_Drain(t *testing.T) {
+	repo := &fakeOutbox{queue: messages("e1", "e2", "e3", "e4", "e5")}
+	publisher := &fakePublisher{}
+	relay := outbox.NewRelay(repo, publisher, &loggertest.MockLogger{}, outbox.Config{BatchSize: 2})
+
+	delivered, err := relay.Drain(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, delivered)
+	assert.Equal(t, []string{"e1", "e2", "e3", "e4", "e5"}, publisher.events())
+	assert.Zero(t, repo.len())
+}
+
+func TestRelay_Drain_PublishFails(t *testing.T) {
+	repo := &fakeOutbox{queue: messages("e1", "e2", "e3")}
+	publisher := &fakePublisher{failAt: "e2"}
+	relay := outbox.NewRelay(repo, publisher, &loggertest.MockLogger{}, outbox.Config{BatchSize: 10})
+
+	delivered, err := relay.Drain(context.Background())
+
+	require.ErrorIs(t, err, srvcerrors.ErrKafka)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 2, repo.len(), "undelivered messages stay in the outbox")
+
+	publisher.failAt = ""
+	delivered, err = relay.Drain(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, []string{"e1", "e2", "e3"}, publisher.events())
+}
+
+func TestRelay_Start(t *testing.T) {
+	repo := &fakeOutbox{queue: messages("e1", "e2")}
+	publisher := &fakePublisher{}
+	relay := outbox.NewRelay(repo, publisher, &loggertest.MockLogger{}, outbox.Config{PollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	relay.Start(ctx)
+
+	repo.mu.Lock()
+	repo.queue = append(repo.queue, messages("e3")...)
+	repo.mu.Unlock()
+
+	require.Eventually(t, func() bool { return len(publisher.events()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	relay.Wait()
+	assert.Zero(t, repo.len())
+}
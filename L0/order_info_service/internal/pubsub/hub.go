@@ -0,0 +1,128 @@
+package pubsub
+
+import (
+	"sync"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+)
+
+const DefaultBufferSize = 16
+
+type Hub struct {
+	mu         sync.RWMutex
+	subs       map[string]map[*Subscription]struct{}
+	bufferSize int
+	closed     bool
+}
+
+type Subscription struct {
+	orderUID string
+	ch       chan *model.Order
+	hub      *Hub
+	once     sync.Once
+}
+
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Hub{
+		subs:       make(map[string]map[*Subscription]struct{}),
+		bufferSize: bufferSize,
+	}
+}
+
+func (h *Hub) Subscribe(orderUID string) *Subscription {
+	sub := &Subscription{
+		orderUID: orderUID,
+		ch:       make(chan *model.Order, h.bufferSize),
+		hub:      h,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(sub.ch)
+		return sub
+	}
+	if h.subs[orderUID] == nil {
+		h.subs[orderUID] = make(map[*Subscription]struct{})
+	}
+	h.subs[orderUID][sub] = struct{}{}
+
+	return sub
+}
+
+// Publish never blocks: a subscriber that does not keep up loses its oldest
+// pending update, since only the latest version of an order matters.
+func (h *Hub) Publish(order *model.Order) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subs[order.OrderUID] {
+		for {
+			select {
+			case sub.ch <- order:
+			default:
+				select {
+				case <-sub.ch:
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
+
+func (h *Hub) SubscribersCount(orderUID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.subs[orderUID])
+}
+
+// Close ends every subscription, so streams waiting on Updates return and
+// let the server shut down. Subscriptions made afterwards start closed.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			close(sub.ch)
+		}
+	}
+	h.subs = make(map[string]map[*Subscription]struct{})
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs := h.subs[sub.orderUID]
+	if _, ok := subs[sub]; !ok {
+		// already closed by Close, or never registered on a closed hub
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subs, sub.orderUID)
+	}
+	close(sub.ch)
+}
+
+func (s *Subscription) Updates() <-chan *model.Order {
+	return s.ch
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.unsubscribe(s)
+	})
+}
@@ -0,0 +1,76 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_PublishToSubscribers(t *testing.T) {
+	hub := NewHub(4)
+
+	sub1 := hub.Subscribe("order-1")
+	defer sub1.Close()
+	sub2 := hub.Subscribe("order-1")
+	defer sub2.Close()
+	other := hub.Subscribe("order-2")
+	defer other.Close()
+
+	order := &model.Order{OrderUID: "order-1"}
+	hub.Publish(order)
+
+	assert.Same(t, order, <-sub1.Updates())
+	assert.Same(t, order, <-sub2.Updates())
+	assert.Empty(t, other.Updates())
+}
+
+func TestHub_SlowSubscriberKeepsLatest(t *testing.T) {
+	hub := NewHub(2)
+	sub := hub.Subscribe("order-1")
+	defer sub.Close()
+
+	for i := range 5 {
+		hub.Publish(&model.Order{OrderUID: "order-1", SmID: i})
+	}
+
+	require.Len(t, sub.Updates(), 2)
+	assert.Equal(t, 3, (<-sub.Updates()).SmID)
+	assert.Equal(t, 4, (<-sub.Updates()).SmID)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(1)
+	sub := hub.Subscribe("order-1")
+	require.Equal(t, 1, hub.SubscribersCount("order-1"))
+
+	sub.Close()
+	sub.Close()
+
+	assert.Equal(t, 0, hub.SubscribersCount("order-1"))
+	_, ok := <-sub.Updates()
+	assert.False(t, ok)
+
+	hub.Publish(&model.Order{OrderUID: "order-1"})
+}
+
+func TestHub_CloseEndsSubscriptions(t *testing.T) {
+	hub := NewHub(1)
+	sub := hub.Subscribe("order-1")
+
+	hub.Close()
+
+	_, ok := <-sub.Updates()
+	assert.False(t, ok)
+	assert.Equal(t, 0, hub.SubscribersCount("order-1"))
+	sub.Close()
+
+	late := hub.Subscribe("order-1")
+	_, ok = <-late.Updates()
+	assert.False(t, ok)
+	late.Close()
+
+	hub.Publish(&model.Order{OrderUID: "order-1"})
+	hub.Close()
+}
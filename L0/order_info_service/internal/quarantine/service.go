@@ -0,0 +1,223 @@
+package quarantine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+type Handler func(context.Context, *model.Order) error
+
+type QuarantineProvider interface {
+	List(context.Context, string, int64, int) ([]*model.QuarantinedOrder, error)
+	Get(context.Context, int64) (*model.QuarantinedOrder, error)
+	UpdatePayload(context.Context, int64, []byte) (*model.QuarantinedOrder, error)
+	Resubmit(context.Context, int64) (*model.QuarantinedOrder, error)
+	Discard(context.Context, int64) (*model.QuarantinedOrder, error)
+}
+
+type Service struct {
+	repo    repository.QuarantineRepositoryProvider
+	decoder *ingest.Decoder
+	handler Handler
+	logger  logger.Logger
+}
+
+func NewService(repo repository.QuarantineRepositoryProvider, decoder *ingest.Decoder, handler Handler, logger logger.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		decoder: decoder,
+		handler: handler,
+		logger:  logger,
+	}
+}
+
+func (s *Service) Quarantine(ctx context.Context, q *model.QuarantinedOrder) error {
+	if q.OrderUID == "" {
+		q.OrderUID = extractOrderUID(q.Payload)
+	}
+	// The payload is stored as bytes, but the other fields go into text
+	// columns, which reject NUL bytes and invalid UTF-8.
+	q.OrderUID = textSafe(q.OrderUID)
+	q.Key = textSafe(q.Key)
+	for i, e := range q.Errors {
+		q.Errors[i] = textSafe(e)
+	}
+
+	created, err := s.repo.CreateQuarantinedOrder(ctx, q)
+	if err != nil {
+		s.logger.Error("quarantine: failed to store rejected order",
+			zap.String("order_uid", q.OrderUID),
+			zap.String("stage", q.Stage),
+			zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("quarantine: order quarantined",
+		zap.Int64("id", created.ID),
+		zap.String("order_uid", created.OrderUID),
+		zap.String("stage", created.Stage))
+	return nil
+}
+
+func (s *Service) List(ctx context.Context, status string, lastID int64, limit int) ([]*model.QuarantinedOrder, error) {
+	switch status {
+	case "", model.QuarantineStatusPending, model.QuarantineStatusResubmitting,
+		model.QuarantineStatusResubmitted, model.QuarantineStatusDiscarded:
+	default:
+		return nil, fmt.Errorf("%w: unknown quarantine status %q", srvcerrors.ErrInvalidInput, status)
+	}
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	if limit < 1 || limit > MaxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", srvcerrors.ErrInvalidInput, MaxListLimit)
+	}
+
+	return s.repo.ListQuarantinedOrders(ctx, status, lastID, limit)
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (*model.QuarantinedOrder, error) {
+	return s.repo.GetQuarantinedOrder(ctx, id)
+}
+
+func (s *Service) UpdatePayload(ctx context.Context, id int64, payload []byte) (*model.QuarantinedOrder, error) {
+	if !json.Valid(payload) {
+		return nil, fmt.Errorf("%w: payload must be valid json", srvcerrors.ErrInvalidInput)
+	}
+
+	q, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	q.Payload = payload
+	q.OrderUID = extractOrderUID(payload)
+
+	_, _, err = s.decoder.Decode(payload)
+	if rejection, ok := ingest.AsRejection(err); ok {
+		q.Stage = rejection.Stage
+		q.Errors = rejection.Errors
+	} else {
+		q.Stage = ""
+		q.Errors = []string{}
+	}
+
+	updated, err := s.repo.UpdateQuarantinedPayload(ctx, q)
+	if errors.Is(err, srvcerrors.ErrNotFound) {
+		return nil, s.conflict(ctx, id)
+	}
+	return updated, err
+}
+
+// Resubmit claims the order by moving it to resubmitting before it is decoded
+// and handled, so concurrent resubmits of the same order cannot both reach the
+// handler. On failure the order is returned to pending.
+func (s *Service) Resubmit(ctx context.Context, id int64) (*model.QuarantinedOrder, error) {
+	q, err := s.transition(ctx, id, model.QuarantineStatusPending, model.QuarantineStatusResubmitting)
+	if err != nil {
+		return nil, err
+	}
+
+	order, _, err := s.decoder.Decode(q.Payload)
+	if err != nil {
+		if rejection, ok := ingest.AsRejection(err); ok {
+			q.Stage = rejection.Stage
+			q.Errors = rejection.Errors
+			if _, uerr := s.repo.UpdateQuarantinedPayload(ctx, q); uerr != nil {
+				s.logger.Warn("quarantine: failed to record resubmit errors",
+					zap.Int64("id", id),
+					zap.Error(uerr))
+			}
+		}
+		s.release(ctx, id)
+		return nil, err
+	}
+
+	if err := s.handler(ctx, order); err != nil {
+		s.logger.Error("quarantine: failed to resubmit order",
+			zap.Int64("id", id),
+			zap.String("order_uid", order.OrderUID),
+			zap.Error(err))
+		s.release(ctx, id)
+		return nil, err
+	}
+
+	s.logger.Info("quarantine: order resubmitted",
+		zap.Int64("id", id),
+		zap.String("order_uid", order.OrderUID))
+	// The order has been handled, so record that even if the caller is gone.
+	return s.repo.SetQuarantinedStatus(context.WithoutCancel(ctx), id, model.QuarantineStatusResubmitting, model.QuarantineStatusResubmitted)
+}
+
+func (s *Service) Discard(ctx context.Context, id int64) (*model.QuarantinedOrder, error) {
+	return s.transition(ctx, id, model.QuarantineStatusPending, model.QuarantineStatusDiscarded)
+}
+
+// transition moves the order from one status to another.
+func (s *Service) transition(ctx context.Context, id int64, from, to string) (*model.QuarantinedOrder, error) {
+	q, err := s.repo.SetQuarantinedStatus(ctx, id, from, to)
+	if !errors.Is(err, srvcerrors.ErrNotFound) {
+		return q, err
+	}
+	return nil, s.conflict(ctx, id)
+}
+
+// conflict explains why a conditional update matched no row: the order is
+// either gone or no longer in the status the caller expected.
+func (s *Service) conflict(ctx context.Context, id int64) error {
+	current, err := s.repo.GetQuarantinedOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: quarantined order %d is already %s", srvcerrors.ErrConflict, id, current.Status)
+}
+
+// release returns a claimed order to pending. It runs even if the request
+// was cancelled, otherwise the order would be stuck in resubmitting.
+func (s *Service) release(ctx context.Context, id int64) {
+	if _, err := s.repo.SetQuarantinedStatus(context.WithoutCancel(ctx), id, model.QuarantineStatusResubmitting, model.QuarantineStatusPending); err != nil {
+		s.logger.Error("quarantine: failed to release resubmitted order",
+			zap.Int64("id", id),
+			zap.Error(err))
+	}
+}
+
+func (s *Service) pending(ctx context.Context, id int64) (*model.QuarantinedOrder, error) {
+	q, err := s.repo.GetQuarantinedOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if q.Status != model.QuarantineStatusPending {
+		return nil, fmt.Errorf("%w: quarantined order %d is already %s", srvcerrors.ErrConflict, id, q.Status)
+	}
+	return q, nil
+}
+
+func textSafe(s string) string {
+	return strings.ToValidUTF8(strings.ReplaceAll(s, "\x00", ""), "\uFFFD")
+}
+
+func extractOrderUID(payload []byte) string {
+	var probe struct {
+		OrderUID string `json:"order_uid"`
+	}
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return ""
+	}
+	return probe.OrderUID
+}
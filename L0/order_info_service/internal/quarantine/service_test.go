@@ -0,0 +1,338 @@
+package quarantine_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/ingest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/quarantine"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	mu     sync.Mutex
+	orders map[int64]*model.QuarantinedOrder
+	nextID int64
+
+	// beforeUpdate runs at the start of UpdateQuarantinedPayload, before the
+	// status check, to simulate a concurrent transition.
+	beforeUpdate func()
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{orders: make(map[int64]*model.QuarantinedOrder)}
+}
+
+func (f *fakeRepository) CreateQuarantinedOrder(_ context.Context, q *model.QuarantinedOrder) (*model.QuarantinedOrder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	created := *q
+	created.ID = f.nextID
+	created.Status = model.QuarantineStatusPending
+	f.orders[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+func (f *fakeRepository) GetQuarantinedOrder(_ context.Context, id int64) (*model.QuarantinedOrder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q, ok := f.orders[id]
+	if !ok {
+		return nil, srvcerrors.ErrNotFound
+	}
+	copied := *q
+	return &copied, nil
+}
+
+func (f *fakeRepository) ListQuarantinedOrders(_ context.Context, status string, lastID int64, limit int) ([]*model.QuarantinedOrder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	orders := make([]*model.QuarantinedOrder, 0)
+	for id := lastID + 1; id <= f.nextID && len(orders) < limit; id++ {
+		if q, ok := f.orders[id]; ok && (status == "" || q.Status == status) {
+			orders = append(orders, q)
+		}
+	}
+	return orders, nil
+}
+
+func (f *fakeRepository) UpdateQuarantinedPayload(_ context.Context, q *model.QuarantinedOrder) (*model.QuarantinedOrder, error) {
+	if f.beforeUpdate != nil {
+		f.beforeUpdate()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if current, ok := f.orders[q.ID]; !ok || current.Status != q.Status {
+		return nil, srvcerrors.ErrNotFound
+	}
+	updated := *q
+	f.orders[q.ID] = &updated
+	return q, nil
+}
+
+func (f *fakeRepository) SetQuarantinedStatus(_ context.Context, id int64, from, status string) (*model.QuarantinedOrder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q, ok := f.orders[id]
+	if !ok || q.Status != from {
+		return nil, srvcerrors.ErrNotFound
+	}
+	q.Status = status
+	if status == model.QuarantineStatusResubmitted {
+		now := time.Now()
+		q.ResubmittedAt = &now
+	}
+	copied := *q
+	return &copied, nil
+}
+
+func validPayload(t *testing.T) []byte {
+	order := &model.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: model.Delivery{
+			OrderUID: "b563feb7b2b84b6test", Name: "Test Testov", Phone: "+9720000000", Zip: "2639809",
+			City: "Kiryat Mozkin", Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+		},
+		Payment: model.Payment{
+			Transaction: "b563feb7b2b84b6test", Currency: "USD", Provider: "wbpay", Amount: 1817,
+			PaymentDT: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+		},
+		Items: []*model.Item{{
+			OrderUID: "b563feb7b2b84b6test", ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453,
+			RID: "ab4219087a764ae0btest", Name: "Mascaras", Sale: 30, Size: "0", TotalPrice: 317,
+			NmID: 2389212, Brand: "Vivienne Sabo", Status: 202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+	}
+	payload, err := json.Marshal(order)
+	require.NoError(t, err)
+	return payload
+}
+
+func newService(repo *fakeRepository, handled *[]*model.Order, handlerErr error) *quarantine.Service {
+	return quarantine.NewService(repo, ingest.NewDecoder(nil), func(_ context.Context, order *model.Order) error {
+		if handlerErr != nil {
+			return handlerErr
+		}
+		*handled = append(*handled, order)
+		return nil
+	}, &loggertest.MockLogger{})
+}
+
+func TestService_QuarantineExtractsOrderUID(t *testing.T) {
+	repo := newFakeRepository()
+	svc := newService(repo, nil, nil)
+
+	err := svc.Quarantine(context.Background(), &model.QuarantinedOrder{
+		Payload: []byte(`{"order_uid":"abc123","locale":"de"}`),
+		Stage:   ingest.StageValidation,
+		Errors:  []string{"Locale: oneof"},
+		Topic:   "orders",
+		Offset:  42,
+	})
+
+	require.NoError(t, err)
+	stored, err := svc.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", stored.OrderUID)
+	assert.EqualValues(t, 42, stored.Offset)
+}
+
+func TestService_QuarantineBinaryMessage(t *testing.T) {
+	repo := newFakeRepository()
+	svc := newService(repo, nil, nil)
+	payload := []byte("{\"order_uid\":\"a\\u0000b\"}\x00\xff")
+
+	err := svc.Quarantine(context.Background(), &model.QuarantinedOrder{
+		Payload: payload,
+		Stage:   ingest.StageDecode,
+		Key:     "key\x00\xff",
+	})
+
+	require.NoError(t, err)
+	stored, err := svc.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, payload, []byte(stored.Payload), "the payload is kept byte for byte")
+	assert.Equal(t, "key\uFFFD", stored.Key)
+}
+
+func TestService_FixAndResubmit(t *testing.T) {
+	repo := newFakeRepository()
+	var handled []*model.Order
+	svc := newService(repo, &handled, nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Quarantine(ctx, &model.QuarantinedOrder{Payload: []byte(`{"order_uid":`), Stage: ingest.StageDecode}))
+
+	_, err := svc.Resubmit(ctx, 1)
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	assert.Empty(t, handled)
+
+	updated, err := svc.UpdatePayload(ctx, 1, validPayload(t))
+	require.NoError(t, err)
+	assert.Equal(t, "b563feb7b2b84b6test", updated.OrderUID)
+	assert.Empty(t, updated.Stage)
+	assert.Empty(t, updated.Errors)
+
+	resubmitted, err := svc.Resubmit(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.QuarantineStatusResubmitted, resubmitted.Status)
+	assert.NotNil(t, resubmitted.ResubmittedAt)
+	require.Len(t, handled, 1)
+
+	_, err = svc.Resubmit(ctx, 1)
+	require.ErrorIs(t, err, srvcerrors.ErrConflict)
+	assert.Len(t, handled, 1)
+}
+
+func TestService_UpdatePayloadConflictsWithResubmit(t *testing.T) {
+	repo := newFakeRepository()
+	svc := newService(repo, nil, nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Quarantine(ctx, &model.QuarantinedOrder{Payload: []byte(`{}`), Stage: ingest.StageValidation}))
+	repo.beforeUpdate = func() {
+		_, err := repo.SetQuarantinedStatus(ctx, 1, model.QuarantineStatusPending, model.QuarantineStatusResubmitting)
+		require.NoError(t, err)
+	}
+
+	_, err := svc.UpdatePayload(ctx, 1, validPayload(t))
+
+	require.ErrorIs(t, err, srvcerrors.ErrConflict)
+	stored, err := svc.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(stored.Payload))
+}
+
+func TestService_ConcurrentResubmitHandlesOnce(t *testing.T) {
+	repo := newFakeRepository()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	svc := quarantine.NewService(repo, ingest.NewDecoder(nil), func(context.Context, *model.Order) error {
+		calls.Add(1)
+		<-release
+		return nil
+	}, &loggertest.MockLogger{})
+	ctx := context.Background()
+
+	require.NoError(t, svc.Quarantine(ctx, &model.QuarantinedOrder{Payload: validPayload(t), Stage: ingest.StageRules}))
+
+	const workers = 8
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.Resubmit(ctx, 1)
+			errs <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	var succeeded, conflicts int
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, srvcerrors.ErrConflict):
+			conflicts++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, workers-1, conflicts)
+	assert.EqualValues(t, 1, calls.Load())
+
+	stored, err := svc.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.QuarantineStatusResubmitted, stored.Status)
+}
+
+func TestService_ResubmitHandlerError(t *testing.T) {
+	repo := newFakeRepository()
+	svc := newService(repo, nil, srvcerrors.ErrDatabase)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Quarantine(ctx, &model.QuarantinedOrder{Payload: validPayload(t), Stage: ingest.StageRules}))
+
+	_, err := svc.Resubmit(ctx, 1)
+
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	stored, _ := svc.Get(ctx, 1)
+	assert.Equal(t, model.QuarantineStatusPending, stored.Status)
+}
+
+func TestService_ResubmitCancelledReleasesClaim(t *testing.T) {
+	repo := newFakeRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := quarantine.NewService(repo, ingest.NewDecoder(nil), func(ctx context.Context, _ *model.Order) error {
+		cancel()
+		return ctx.Err()
+	}, &loggertest.MockLogger{})
+
+	require.NoError(t, svc.Quarantine(context.Background(), &model.QuarantinedOrder{Payload: validPayload(t), Stage: ingest.StageRules}))
+
+	_, err := svc.Resubmit(ctx, 1)
+
+	require.ErrorIs(t, err, context.Canceled)
+	stored, err := svc.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.QuarantineStatusPending, stored.Status)
+}
+
+func TestService_Discard(t *testing.T) {
+	repo := newFakeRepository()
+	svc := newService(repo, nil, nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Quarantine(ctx, &model.QuarantinedOrder{Payload: []byte(`{}`), Stage: ingest.StageValidation}))
+
+	discarded, err := svc.Discard(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.QuarantineStatusDiscarded, discarded.Status)
+
+	pending, err := svc.List(ctx, model.QuarantineStatusPending, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	_, err = svc.Discard(ctx, 2)
+	assert.True(t, errors.Is(err, srvcerrors.ErrNotFound))
+}
+
+func TestService_InvalidInput(t *testing.T) {
+	svc := newService(newFakeRepository(), nil, nil)
+	ctx := context.Background()
+
+	_, err := svc.UpdatePayload(ctx, 1, []byte(`not json`))
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+
+	_, err = svc.List(ctx, "archived", 0, 0)
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+
+	_, err = svc.List(ctx, "", 0, quarantine.MaxListLimit+1)
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+}
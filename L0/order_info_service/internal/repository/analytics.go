@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/dto"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+)
+
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+const (
+	revenueByCurrencyQuery = `SELECT date_trunc($1, o.date_created) AS bucket,
+			p.currency::text,
+			COALESCE(SUM(p.amount), 0)::bigint,
+			COUNT(*)
+		FROM orders o
+		JOIN payments p ON p.transaction = o.order_uid
+		WHERE o.date_created >= $2 AND o.date_created < $3
+		GROUP BY bucket, p.currency
+		ORDER BY bucket, p.currency`
+
+	orderCountsQueryTemplate = `SELECT date_trunc($1, o.date_created) AS bucket,
+			o.%[1]s::text,
+			COUNT(*)
+		FROM orders o
+		WHERE o.date_created >= $2 AND o.date_created < $3
+		GROUP BY bucket, o.%[1]s
+		ORDER BY bucket, COUNT(*) DESC, o.%[1]s`
+
+	topItemsQueryTemplate = `WITH grouped AS (
+			SELECT date_trunc($1, o.date_created) AS bucket,
+				i.%[1]s AS key,
+				COUNT(*) AS items,
+				COUNT(DISTINCT i.order_uid) AS orders
+			FROM items i
+			JOIN orders o ON o.order_uid = i.order_uid
+			WHERE o.date_created >= $2 AND o.date_created < $3
+			GROUP BY bucket, i.%[1]s
+		), ranked AS (
+			SELECT bucket, key, items, orders,
+				ROW_NUMBER() OVER (PARTITION BY bucket ORDER BY items DESC, orders DESC, key) AS rn
+			FROM grouped
+		)
+		SELECT bucket, key, items, orders
+		FROM ranked
+		WHERE rn <= $4
+		ORDER BY bucket, rn`
+
+	basketSizeQuery = `WITH per_order AS (
+			SELECT date_trunc($1, o.date_created) AS bucket,
+				p.currency,
+				p.amount,
+				(SELECT COUNT(*) FROM items i WHERE i.order_uid = o.order_uid) AS items
+			FROM orders o
+			JOIN payments p ON p.transaction = o.order_uid
+			WHERE o.date_created >= $2 AND o.date_created < $3
+		)
+		SELECT bucket,
+			currency::text,
+			COUNT(*),
+			AVG(items)::float8,
+			AVG(amount)::float8
+		FROM per_order
+		GROUP BY bucket, currency
+		ORDER BY bucket, currency`
+)
+
+var (
+	orderCountsQueries = map[string]string{
+		model.GroupByDeliveryService: fmt.Sprintf(orderCountsQueryTemplate, "delivery_service"),
+		model.GroupByLocale:          fmt.Sprintf(orderCountsQueryTemplate, "locale"),
+	}
+
+	topBrandsQuery   = fmt.Sprintf(topItemsQueryTemplate, "brand")
+	topProductsQuery = fmt.Sprintf(topItemsQueryTemplate, "nm_id")
+)
+
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+func (r *AnalyticsRepository) RevenueByCurrency(ctx context.Context, q model.AnalyticsQuery) ([]*model.RevenuePoint, error) {
+	points, err := queryRows(ctx, r.db, dto.ScanRevenuePointFromRow, revenueByCurrencyQuery, q.Bucket, q.From, q.To)
+	if err != nil {
+		return nil, wrapDBError("failed to aggregate revenue", "", err)
+	}
+	return points, nil
+}
+
+func (r *AnalyticsRepository) OrderCounts(ctx context.Context, groupBy string, q model.AnalyticsQuery) ([]*model.OrderCountPoint, error) {
+	query, ok := orderCountsQueries[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported group_by %q", srvcerrors.ErrInvalidInput, groupBy)
+	}
+
+	points, err := queryRows(ctx, r.db, dto.ScanOrderCountPointFromRow, query, q.Bucket, q.From, q.To)
+	if err != nil {
+		return nil, wrapDBError("failed to aggregate order counts by", groupBy, err)
+	}
+	return points, nil
+}
+
+func (r *AnalyticsRepository) TopBrands(ctx context.Context, q model.AnalyticsQuery) ([]*model.BrandStat, error) {
+	stats, err := queryRows(ctx, r.db, dto.ScanBrandStatFromRow, topBrandsQuery, q.Bucket, q.From, q.To, q.Limit)
+	if err != nil {
+		return nil, wrapDBError("failed to aggregate top brands", "", err)
+	}
+	return stats, nil
+}
+
+func (r *AnalyticsRepository) TopProducts(ctx context.Context, q model.AnalyticsQuery) ([]*model.ProductStat, error) {
+	stats, err := queryRows(ctx, r.db, dto.ScanProductStatFromRow, topProductsQuery, q.Bucket, q.From, q.To, q.Limit)
+	if err != nil {
+		return nil, wrapDBError("failed to aggregate top products", "", err)
+	}
+	return stats, nil
+}
+
+func (r *AnalyticsRepository) BasketSize(ctx context.Context, q model.AnalyticsQuery) ([]*model.BasketPoint, error) {
+	points, err := queryRows(ctx, r.db, dto.ScanBasketPointFromRow, basketSizeQuery, q.Bucket, q.From, q.To)
+	if err != nil {
+		return nil, wrapDBError("failed to aggregate basket size", "", err)
+	}
+	return points, nil
+}
+
+func queryRows[T any](ctx context.Context, db Querier, scan func(dto.RowScanner) (*T, error), query string, args ...interface{}) ([]*T, error) {
+	result := make([]*T, 0)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
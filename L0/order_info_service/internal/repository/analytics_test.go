@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func analyticsTestQuery() model.AnalyticsQuery {
+	return model.AnalyticsQuery{
+		From:   time.Now().Add(-24 * time.Hour),
+		To:     time.Now().Add(24 * time.Hour),
+		Bucket: model.BucketMonth,
+		Limit:  10,
+	}
+}
+
+func TestAnalytics_Aggregations(t *testing.T) {
+	clearTables(t)
+	ctx := context.Background()
+
+	_, _, err := NewOrderRepository(TestDB).UpsertOrder(ctx, generateTestOrder())
+	require.NoError(t, err)
+
+	repo := NewAnalyticsRepository(TestDB)
+	q := analyticsTestQuery()
+
+	revenue, err := repo.RevenueByCurrency(ctx, q)
+	require.NoError(t, err)
+	require.NotEmpty(t, revenue)
+	assert.Equal(t, "RUB", revenue[0].Currency)
+	assert.EqualValues(t, 5000, revenue[0].Revenue)
+	assert.EqualValues(t, 1, revenue[0].Orders)
+
+	counts, err := repo.OrderCounts(ctx, model.GroupByLocale, q)
+	require.NoError(t, err)
+	require.NotEmpty(t, counts)
+	assert.Equal(t, "en", counts[0].Key)
+
+	brands, err := repo.TopBrands(ctx, q)
+	require.NoError(t, err)
+	assert.Len(t, brands, 2)
+
+	products, err := repo.TopProducts(ctx, q)
+	require.NoError(t, err)
+	assert.Len(t, products, 2)
+
+	basket, err := repo.BasketSize(ctx, q)
+	require.NoError(t, err)
+	require.NotEmpty(t, basket)
+	assert.InDelta(t, 2, basket[0].AvgItems, 0.001)
+	assert.InDelta(t, 5000, basket[0].AvgAmount, 0.001)
+}
+
+func TestAnalytics_OrderCountsUnsupportedGroupBy(t *testing.T) {
+	repo := NewAnalyticsRepository(TestDB)
+
+	counts, err := repo.OrderCounts(context.Background(), "customer_id", analyticsTestQuery())
+
+	require.Nil(t, counts)
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+}
+
+func TestAnalytics_RevenueByCurrency_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewAnalyticsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(revenueByCurrencyQuery)).WillReturnError(srvcerrors.ErrDatabase)
+
+	points, err := repo.RevenueByCurrency(context.Background(), analyticsTestQuery())
+
+	require.Error(t, err)
+	require.Nil(t, points)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,17 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository/repositorytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderRepository_Conformance(t *testing.T) {
+	repositorytest.RunConformance(t, func(t *testing.T) repository.RepositoryProvider {
+		_, err := repository.TestDB.Exec("TRUNCATE TABLE items, payments, deliveries, orders RESTART IDENTITY CASCADE")
+		require.NoError(t, err)
+		return repository.NewOrderRepository(repository.TestDB)
+	})
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/dto"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+)
+
+type ExchangeRateRepository struct {
+	db *sql.DB
+}
+
+const (
+	listExchangeRatesQuery = `SELECT currency, rate, updated_at
+		FROM exchange_rates
+		ORDER BY currency`
+
+	upsertExchangeRateQuery = `INSERT INTO exchange_rates (currency, rate)
+		VALUES ($1, $2)
+		ON CONFLICT (currency) DO UPDATE
+			SET rate = EXCLUDED.rate, updated_at = now()
+		RETURNING currency, rate, updated_at`
+)
+
+func NewExchangeRateRepository(db *sql.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+func (r *ExchangeRateRepository) ListRates(ctx context.Context) ([]*model.ExchangeRate, error) {
+	rates, err := queryRows(ctx, r.db, dto.ScanExchangeRateFromRow, listExchangeRatesQuery)
+	if err != nil {
+		return nil, wrapDBError("failed to list exchange rates", "", err)
+	}
+	return rates, nil
+}
+
+func (r *ExchangeRateRepository) UpsertRates(ctx context.Context, rates []*model.ExchangeRate) (stored []*model.ExchangeRate, err error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, wrapDBError("failed to begin transaction", "", err)
+	}
+
+	defer func() {
+		err = finishTransaction(tx, err)
+	}()
+
+	stored = make([]*model.ExchangeRate, 0, len(rates))
+	for _, rate := range rates {
+		row := tx.QueryRowContext(ctx, upsertExchangeRateQuery, rate.Currency, rate.Rate)
+		upserted, err := dto.ScanExchangeRateFromRow(row)
+		if err != nil {
+			return nil, wrapDBError("failed to upsert exchange rate", rate.Currency, err)
+		}
+		stored = append(stored, upserted)
+	}
+
+	return stored, nil
+}
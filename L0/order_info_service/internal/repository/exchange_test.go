@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeRates_UpsertAndList(t *testing.T) {
+	_, err := TestDB.Exec("TRUNCATE TABLE exchange_rates")
+	require.NoError(t, err)
+
+	repo := NewExchangeRateRepository(TestDB)
+	ctx := context.Background()
+
+	_, err = repo.UpsertRates(ctx, []*model.ExchangeRate{
+		{Currency: "USD", Rate: 90.5},
+		{Currency: "RUB", Rate: 1},
+	})
+	require.NoError(t, err)
+
+	stored, err := repo.UpsertRates(ctx, []*model.ExchangeRate{{Currency: "USD", Rate: 91.25}})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, 91.25, stored[0].Rate)
+
+	rates, err := repo.ListRates(ctx)
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "RUB", rates[0].Currency)
+	assert.Equal(t, 91.25, rates[1].Rate)
+}
+
+func TestExchangeRates_UpsertRates_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewExchangeRateRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(upsertExchangeRateQuery)).WillReturnError(srvcerrors.ErrDatabase)
+	mock.ExpectRollback()
+
+	rates, err := repo.UpsertRates(context.Background(), []*model.ExchangeRate{{Currency: "USD", Rate: 90}})
+
+	require.Error(t, err)
+	require.Nil(t, rates)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,19 +3,71 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 )
 
 type RepositoryProvider interface {
-	UpsertOrder(context.Context, *model.Order) (*model.Order, error)
+	UpsertOrder(context.Context, *model.Order) (*model.Order, bool, error)
 	GetOrderByUID(context.Context, string) (*model.Order, error)
+	OrderExists(context.Context, string) (bool, error)
 	GetAllOrders(context.Context, int) ([]*model.Order, error)
+	GetRecentOrders(context.Context, *model.OrderCursor, int) ([]*model.Order, error)
+	GetOrdersByUIDs(context.Context, []string) (map[string]*model.Order, error)
 	GetItemsByOrderUID(context.Context, string, int, int) ([]*model.Item, error)
+	GetItemsByOrderUIDs(context.Context, []string, int, int) (map[string][]*model.Item, error)
+}
+
+type SnapshotRepositoryProvider interface {
+	LastUpsertAt(context.Context) (time.Time, error)
+	GetOrdersByUIDs(context.Context, []string) (map[string]*model.Order, error)
+}
+
+type ItemSearchRepositoryProvider interface {
+	SearchItems(context.Context, string, int, int) ([]*model.ItemSearchHit, error)
+}
+
+type WebhookRepositoryProvider interface {
+	CreateSubscription(context.Context, *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	UpdateSubscription(context.Context, *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	DeleteSubscription(context.Context, int64) error
+	GetSubscription(context.Context, int64) (*model.WebhookSubscription, error)
+	ListSubscriptions(context.Context) ([]*model.WebhookSubscription, error)
+	RecordDelivery(context.Context, *model.WebhookDelivery) (*model.WebhookDelivery, error)
+	ListDeliveries(context.Context, int64, int) ([]*model.WebhookDelivery, error)
+	EnqueueEvent(context.Context, *model.WebhookPendingDelivery) (int, error)
+	ClaimPendingDeliveries(context.Context, int, time.Duration) ([]*model.WebhookPendingDelivery, error)
+	ReschedulePendingDelivery(context.Context, int64, int, time.Duration) error
+	DeletePendingDelivery(context.Context, int64) error
+}
+
+type ExchangeRateRepositoryProvider interface {
+	ListRates(context.Context) ([]*model.ExchangeRate, error)
+	UpsertRates(context.Context, []*model.ExchangeRate) ([]*model.ExchangeRate, error)
+}
+
+type QuarantineRepositoryProvider interface {
+	CreateQuarantinedOrder(context.Context, *model.QuarantinedOrder) (*model.QuarantinedOrder, error)
+	GetQuarantinedOrder(context.Context, int64) (*model.QuarantinedOrder, error)
+	ListQuarantinedOrders(context.Context, string, int64, int) ([]*model.QuarantinedOrder, error)
+	UpdateQuarantinedPayload(context.Context, *model.QuarantinedOrder) (*model.QuarantinedOrder, error)
+	SetQuarantinedStatus(context.Context, int64, string, string) (*model.QuarantinedOrder, error)
+}
+
+type OutboxRepositoryProvider interface {
+	RelayOutbox(context.Context, int, PublishFunc) (int, error)
 }
 
 type Querier interface {
 	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
 	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
 	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
-}
\ No newline at end of file
+}
+type AnalyticsRepositoryProvider interface {
+	RevenueByCurrency(context.Context, model.AnalyticsQuery) ([]*model.RevenuePoint, error)
+	OrderCounts(context.Context, string, model.AnalyticsQuery) ([]*model.OrderCountPoint, error)
+	TopBrands(context.Context, model.AnalyticsQuery) ([]*model.BrandStat, error)
+	TopProducts(context.Context, model.AnalyticsQuery) ([]*model.ProductStat, error)
+	BasketSize(context.Context, model.AnalyticsQuery) ([]*model.BasketPoint, error)
+}
@@ -0,0 +1,247 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+)
+
+type storedOrder struct {
+	order     *model.Order
+	updatedAt time.Time
+}
+
+// OrderRepository keeps orders in process memory with the same observable
+// behaviour as the Postgres repository. It is meant for development and
+// demos: nothing survives a restart.
+type OrderRepository struct {
+	mu         sync.RWMutex
+	orders     map[string]*storedOrder
+	lastItemID int
+	now        func() time.Time
+}
+
+func NewOrderRepository() *OrderRepository {
+	return &OrderRepository{
+		orders: make(map[string]*storedOrder),
+		now:    time.Now,
+	}
+}
+
+// UpsertOrder builds the new order state aside and swaps it in under the
+// write lock, so readers see either the old order or the new one.
+func (r *OrderRepository) UpsertOrder(ctx context.Context, order *model.Order) (*model.Order, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, wrapError(srvcerrors.ErrDatabase, "failed to begin transaction", "", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.orders[order.OrderUID]
+
+	now := r.now()
+	stored := copyOrder(order, nil)
+	stored.Items = make([]*model.Item, len(order.Items))
+	for i, item := range order.Items {
+		r.lastItemID++
+		stored.Items[i] = copyItem(item, r.lastItemID)
+	}
+	stored.Flags = nil
+	for _, flag := range order.Flags {
+		stored.Flags = append(stored.Flags, &model.OrderFlag{Rule: flag.Rule, Message: flag.Message, CreatedAt: now})
+	}
+
+	r.orders[order.OrderUID] = &storedOrder{order: stored, updatedAt: now}
+	return copyOrder(stored, stored.Items), !exists, nil
+}
+
+func (r *OrderRepository) GetOrderByUID(ctx context.Context, orderUID string) (*model.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(srvcerrors.ErrDatabase, "failed to get order by id", orderUID, err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.orders[orderUID]
+	if !ok {
+		return nil, wrapError(srvcerrors.ErrNotFound, "failed to get order by id", orderUID, sql.ErrNoRows)
+	}
+	return copyOrder(stored.order, nil), nil
+}
+
+func (r *OrderRepository) OrderExists(ctx context.Context, orderUID string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, wrapError(srvcerrors.ErrDatabase, "failed to check existence of order", orderUID, err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.orders[orderUID]
+	return ok, nil
+}
+
+func (r *OrderRepository) LastUpsertAt(ctx context.Context) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, wrapError(srvcerrors.ErrDatabase, "failed to get last upsert time", "", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lastUpsert := time.Unix(0, 0).UTC()
+	for _, stored := range r.orders {
+		if stored.updatedAt.After(lastUpsert) {
+			lastUpsert = stored.updatedAt
+		}
+	}
+	return lastUpsert, nil
+}
+
+func (r *OrderRepository) GetAllOrders(ctx context.Context, limit int) ([]*model.Order, error) {
+	return r.GetRecentOrders(ctx, nil, limit)
+}
+
+func (r *OrderRepository) GetRecentOrders(ctx context.Context, before *model.OrderCursor, limit int) ([]*model.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(srvcerrors.ErrDatabase, "failed to get recent orders", "", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := make([]*model.Order, 0, len(r.orders))
+	for _, stored := range r.orders {
+		if before == nil || compareRecency(stored.order, before.DateCreated, before.OrderUID) < 0 {
+			candidates = append(candidates, stored.order)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b *model.Order) int {
+		return -compareRecency(a, b.DateCreated, b.OrderUID)
+	})
+
+	n := min(max(limit, 0), len(candidates))
+	orders := make([]*model.Order, 0, n)
+	for _, order := range candidates[:n] {
+		orders = append(orders, copyOrder(order, order.Items))
+	}
+	return orders, nil
+}
+
+func (r *OrderRepository) GetOrdersByUIDs(ctx context.Context, orderUIDs []string) (map[string]*model.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(srvcerrors.ErrDatabase, "failed to get orders by uids", "", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orders := make(map[string]*model.Order, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		if stored, ok := r.orders[orderUID]; ok {
+			orders[orderUID] = copyOrder(stored.order, stored.order.Items)
+		}
+	}
+	return orders, nil
+}
+
+func (r *OrderRepository) GetItemsByOrderUID(ctx context.Context, orderUID string, lastID, limit int) ([]*model.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(srvcerrors.ErrDatabase, "failed to get items of order", orderUID, err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.orders[orderUID]
+	if !ok {
+		return nil, nil
+	}
+	return itemsAfter(stored.order.Items, lastID, limit), nil
+}
+
+func (r *OrderRepository) GetItemsByOrderUIDs(ctx context.Context, orderUIDs []string, lastID, limit int) (map[string][]*model.Item, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(srvcerrors.ErrDatabase, "failed to get items of orders", "", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make(map[string][]*model.Item, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		stored, ok := r.orders[orderUID]
+		if !ok {
+			continue
+		}
+		if page := itemsAfter(stored.order.Items, lastID, limit); page != nil {
+			items[orderUID] = page
+		}
+	}
+	return items, nil
+}
+
+// itemsAfter returns up to limit copies of items with id greater than lastID.
+// Stored items are kept in id order, as they are assigned on insert.
+func itemsAfter(items []*model.Item, lastID, limit int) []*model.Item {
+	var page []*model.Item
+	for _, item := range items {
+		if len(page) >= limit {
+			break
+		}
+		if item.ID > lastID {
+			page = append(page, copyItem(item, item.ID))
+		}
+	}
+	return page
+}
+
+// compareRecency orders by (date_created, order_uid) the way the row
+// comparison in the Postgres cursor query does.
+func compareRecency(o *model.Order, dateCreated time.Time, orderUID string) int {
+	if c := o.DateCreated.Compare(dateCreated); c != 0 {
+		return c
+	}
+	return strings.Compare(o.OrderUID, orderUID)
+}
+
+// copyOrder returns a deep copy of o carrying copies of items. A nil items
+// slice leaves Items unset, as the single order lookup does in Postgres.
+func copyOrder(o *model.Order, items []*model.Item) *model.Order {
+	cp := *o
+	cp.Items = nil
+	if items != nil {
+		cp.Items = make([]*model.Item, len(items))
+		for i, item := range items {
+			cp.Items[i] = copyItem(item, item.ID)
+		}
+	}
+	cp.Flags = nil
+	for _, flag := range o.Flags {
+		f := *flag
+		cp.Flags = append(cp.Flags, &f)
+	}
+	return &cp
+}
+
+func copyItem(item *model.Item, id int) *model.Item {
+	cp := *item
+	cp.ID = id
+	return &cp
+}
+
+func wrapError(errType error, baseMsg string, id string, err error) error {
+	if id != "" {
+		return fmt.Errorf("%w: %s %s:\n[%v]\n", errType, baseMsg, id, err)
+	}
+	return fmt.Errorf("%w: %s:\n[%v]\n", errType, baseMsg, err)
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository/memory"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository/repositorytest"
+)
+
+func TestOrderRepository_Conformance(t *testing.T) {
+	repositorytest.RunConformance(t, func(t *testing.T) repository.RepositoryProvider {
+		return memory.NewOrderRepository()
+	})
+}
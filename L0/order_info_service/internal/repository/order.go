@@ -2,26 +2,64 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/dto"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/lib/pq"
 )
 
 type OrderRepository struct {
-	db *sql.DB
+	db            *sql.DB
+	replicas      *ReplicaSet
+	notifyChannel string
+	origin        string
+	outbox        bool
 }
 
+type OrderRepositoryOption func(*OrderRepository)
+
+// WithChangeNotifications makes UpsertOrder emit a NOTIFY on channel with the
+// order uid and origin, delivered to listeners when the transaction commits.
+func WithChangeNotifications(channel, origin string) OrderRepositoryOption {
+	return func(r *OrderRepository) {
+		r.notifyChannel = channel
+		r.origin = origin
+	}
+}
+
+// WithOutbox makes UpsertOrder write an order stored event to the outbox in
+// the same transaction, to be published by the outbox relay.
+func WithOutbox() OrderRepositoryOption {
+	return func(r *OrderRepository) {
+		r.outbox = true
+	}
+}
+
+const hydrationBatchSize = 500
+
 const (
-	insertIntoOrdersQuery = `INSERT INTO orders
+	itemColumns = `id, order_uid, chrt_id, track_number, price, rid,
+		name, sale, size, total_price, nm_id, brand, status`
+
+	upsertOrderQuery = `INSERT INTO orders
 			(order_uid, track_number, entry, locale, internal_signature,
     		customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
       	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+      	ON CONFLICT (order_uid) DO UPDATE
+      	SET track_number = EXCLUDED.track_number, entry = EXCLUDED.entry, locale = EXCLUDED.locale,
+      		internal_signature = EXCLUDED.internal_signature, customer_id = EXCLUDED.customer_id,
+      		delivery_service = EXCLUDED.delivery_service, shardkey = EXCLUDED.shardkey, sm_id = EXCLUDED.sm_id,
+      		date_created = EXCLUDED.date_created, oof_shard = EXCLUDED.oof_shard, updated_at = now()
        	RETURNING order_uid, track_number, entry, locale, internal_signature,
-      		customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard`
+      		customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, (xmax = 0) AS inserted`
 
 	insertIntoDeliveriesQuery = `INSERT INTO deliveries
 			(order_uid, name, phone, zip, city, address, region, email)
@@ -41,13 +79,6 @@ const (
       	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
        	RETURNING id`
 
-	updateOrderQuery = `UPDATE orders
-    	SET track_number = $1, entry = $2, locale = $3, internal_signature = $4,
-   			customer_id = $5, delivery_service = $6, shardkey = $7, sm_id = $8, date_created = $9, oof_shard = $10
-     	WHERE order_uid = $11
-      	RETURNING order_uid, track_number, entry, locale, internal_signature,
-     		customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard`
-
 	updateDeliveryQuery = `UPDATE deliveries
     	SET name = $1, phone = $2, zip = $3, city = $4, address = $5, region = $6, email = $7
      	WHERE order_uid = $8
@@ -60,66 +91,127 @@ const (
       	RETURNING transaction, request_id, currency, provider, amount,
        		payment_dt, bank, delivery_cost, goods_total, custom_fee`
 
-	updateItemQuery = `UPDATE items
-    	SET order_uid = $1, chrt_id = $2, track_number = $3, price = $4, rid = $5,
-       		name = $6, sale = $7, size = $8, total_price = $9, nm_id = $10, brand = $11, status = $12
-     	WHERE id = $13
-      	RETURNING order_uid, chrt_id, track_number, price, rid,
-         		name, sale, size, total_price, nm_id, brand, status`
-
 	deleteItemsQuery = `DELETE FROM items WHERE order_uid = $1`
 
-	getOrderByIDQuery = `SELECT * FROM orders
-		WHERE order_uid = $1`
-
-	getAllOrdersQuery = `SELECT * FROM orders
-		ORDER BY order_uid
+	deleteOrderFlagsQuery = `DELETE FROM order_flags WHERE order_uid = $1`
+
+	insertOrderFlagQuery = `INSERT INTO order_flags (order_uid, rule, message)
+		VALUES ($1, $2, $3)
+		RETURNING rule, message, created_at`
+
+	orderExistsQuery = `SELECT EXISTS (SELECT 1 FROM orders WHERE order_uid = $1)`
+
+	notifyOrderChangedQuery = `SELECT pg_notify($1, $2)`
+
+	insertOutboxQuery = `INSERT INTO order_outbox (event_id, order_uid, event_type, payload)
+		VALUES ($1, $2, $3, $4)`
+
+	lastUpsertAtQuery = `SELECT COALESCE(MAX(updated_at), 'epoch'::timestamptz) FROM orders`
+
+	hydratedOrderColumns = `o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
+			o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
+			d.order_uid, d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+			p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt, p.bank,
+			p.delivery_cost, p.goods_total, p.custom_fee,
+			f.flags`
+
+	hydratedOrdersFrom = `
+		FROM orders o
+		JOIN deliveries d ON d.order_uid = o.order_uid
+		JOIN payments p ON p.transaction = o.order_uid
+		LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object(
+				'rule', fl.rule, 'message', fl.message, 'created_at', fl.created_at
+			) ORDER BY fl.id) AS flags
+			FROM order_flags fl
+			WHERE fl.order_uid = o.order_uid
+		) f ON TRUE`
+
+	orderItemsJoin = `
+		LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object(
+				'id', it.id, 'order_uid', it.order_uid, 'chrt_id', it.chrt_id,
+				'track_number', it.track_number, 'price', it.price, 'rid', it.rid,
+				'name', it.name, 'sale', it.sale, 'size', it.size, 'total_price', it.total_price,
+				'nm_id', it.nm_id, 'brand', it.brand, 'status', it.status
+			) ORDER BY it.id) AS items
+			FROM items it
+			WHERE it.order_uid = o.order_uid
+		) i ON TRUE`
+
+	getOrderByIDQuery = `SELECT ` + hydratedOrderColumns + `, NULL::json AS items` + hydratedOrdersFrom + `
+		WHERE o.order_uid = $1`
+
+	fullOrdersQuery = `SELECT ` + hydratedOrderColumns + `, COALESCE(i.items, '[]'::json) AS items` +
+		hydratedOrdersFrom + orderItemsJoin
+
+	getOrdersByUIDsQuery = fullOrdersQuery + `
+		WHERE o.order_uid = ANY($1)`
+
+	getAllOrdersQuery = fullOrdersQuery + `
+		ORDER BY o.date_created DESC, o.order_uid DESC
 		LIMIT $1`
 
-	getDeliveryByOrderUIDQuery = `SELECT * FROM deliveries
-		WHERE order_uid = $1`
-
-	getPaymentByOrderUIDQuery = `SELECT * FROM payments
-		WHERE transaction = $1`
-
-	getAllItemsByOrderUIDQuery = `SELECT * FROM items
-		WHERE order_uid = $1
-		ORDER BY id
-		LIMIT $2`
+	getOrdersBeforeQuery = fullOrdersQuery + `
+		WHERE (o.date_created, o.order_uid) < ($1, $2)
+		ORDER BY o.date_created DESC, o.order_uid DESC
+		LIMIT $3`
 
-	getItemsByOrderUIDQuery = `SELECT * FROM items
+	getItemsByOrderUIDQuery = `SELECT ` + itemColumns + `
+		FROM items
 		WHERE order_uid = $1 AND id > $2
 		ORDER BY id
 		LIMIT $3`
+
+	getItemsByOrderUIDsQuery = `SELECT ` + itemColumns + `
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY order_uid ORDER BY id) AS rn
+			FROM items
+			WHERE order_uid = ANY($1) AND id > $2
+		) ranked
+		WHERE rn <= $3
+		ORDER BY order_uid, id`
 )
 
-func NewOrderRepository(db *sql.DB) *OrderRepository {
-	return &OrderRepository{db: db}
+// WithReplicas routes read-only lookups to healthy replicas of the set.
+func WithReplicas(replicas *ReplicaSet) OrderRepositoryOption {
+	return func(r *OrderRepository) {
+		r.replicas = replicas
+	}
+}
+
+func NewOrderRepository(db *sql.DB, opts ...OrderRepositoryOption) *OrderRepository {
+	r := &OrderRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-func (r *OrderRepository) UpsertOrder(ctx context.Context, order *model.Order) (newOrder *model.Order, err error) {
+// UpsertOrder stores the order and reports whether it was created. The order
+// row is upserted first: this locks it, so concurrent upserts of one order
+// are serialized and exactly one of them sees it as created.
+func (r *OrderRepository) UpsertOrder(ctx context.Context, order *model.Order) (newOrder *model.Order, created bool, err error) {
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
-		return nil, wrapDBError("failed to begin transaction", "", err)
+		return nil, false, wrapDBError("failed to begin transaction", "", err)
 	}
+	q := traced(tx)
 
 	defer func() {
-		err = r.finishTransaction(tx, err)
-	}()
-
-	if _, err := r.getOrderByOrderUID(ctx, tx, order.OrderUID); err == nil {
-		updatedOrder, err := r.orderFullUpdate(ctx, tx, order)
+		if err == nil {
+			err = r.writeOutbox(ctx, q, newOrder, created)
+		}
+		if err == nil {
+			err = r.notifyOrderChanged(ctx, q, order.OrderUID)
+		}
 		if err != nil {
-			return nil, wrapDBError("failed to update existing order", order.OrderUID, err)
+			newOrder, created = nil, false
 		}
-		return updatedOrder, nil
-	}
-
-	if err != nil && !errors.Is(err, srvcerrors.ErrNotFound) {
-		return nil, wrapDBError("failed to check existence of order", order.OrderUID, err)
-	}
+		err = finishTransaction(tx, err)
+	}()
 
-	row := tx.QueryRowContext(ctx, insertIntoOrdersQuery,
+	row := q.QueryRowContext(ctx, upsertOrderQuery,
 		order.OrderUID,
 		order.TrackNumber,
 		order.Entry,
@@ -132,43 +224,65 @@ func (r *OrderRepository) UpsertOrder(ctx context.Context, order *model.Order) (
 		order.DateCreated,
 		order.OofShard,
 	)
-	if newOrder, err = dto.ScanOrderFromRow(row); err != nil {
-		return nil, wrapDBError("failed to insert into orders while creating new order", "", err)
-	}
-
-	if _, err := tx.ExecContext(ctx, insertIntoDeliveriesQuery,
-		order.Delivery.OrderUID,
-		order.Delivery.Name,
-		order.Delivery.Phone,
-		order.Delivery.Zip,
-		order.Delivery.City,
-		order.Delivery.Address,
-		order.Delivery.Region,
-		order.Delivery.Email,
+	if newOrder, created, err = dto.ScanUpsertedOrderFromRow(row); err != nil {
+		return nil, false, wrapDBError("failed to upsert order", order.OrderUID, err)
+	}
+
+	if created {
+		err = r.insertOrderDetails(ctx, q, order, newOrder)
+	} else {
+		err = r.replaceOrderDetails(ctx, q, order, newOrder)
+	}
+	if err != nil {
+		return nil, false, wrapDBError("failed to store details of order", order.OrderUID, err)
+	}
+
+	if newOrder.Flags, err = r.replaceOrderFlags(ctx, q, order.OrderUID, order.Flags); err != nil {
+		return nil, false, wrapDBError("failed to store flags of order", order.OrderUID, err)
+	}
+
+	return newOrder, created, nil
+}
+
+func (r *OrderRepository) insertOrderDetails(ctx context.Context, q Querier, o *model.Order, newOrder *model.Order) (err error) {
+	if _, err := q.ExecContext(ctx, insertIntoDeliveriesQuery,
+		o.Delivery.OrderUID,
+		o.Delivery.Name,
+		o.Delivery.Phone,
+		o.Delivery.Zip,
+		o.Delivery.City,
+		o.Delivery.Address,
+		o.Delivery.Region,
+		o.Delivery.Email,
 	); err != nil {
-		return nil, wrapDBError("failed to insert into deliveries while creating order", "", err)
-	}
-	newOrder.Delivery = order.Delivery
-
-	if _, err := tx.ExecContext(ctx, insertIntoPaymentsQuery,
-		order.Payment.Transaction,
-		order.Payment.RequestID,
-		order.Payment.Currency,
-		order.Payment.Provider,
-		order.Payment.Amount,
-		order.Payment.PaymentDT,
-		order.Payment.Bank,
-		order.Payment.DeliveryCost,
-		order.Payment.GoodsTotal,
-		order.Payment.CustomFee,
+		return err
+	}
+	newOrder.Delivery = o.Delivery
+
+	if _, err := q.ExecContext(ctx, insertIntoPaymentsQuery,
+		o.Payment.Transaction,
+		o.Payment.RequestID,
+		o.Payment.Currency,
+		o.Payment.Provider,
+		o.Payment.Amount,
+		o.Payment.PaymentDT,
+		o.Payment.Bank,
+		o.Payment.DeliveryCost,
+		o.Payment.GoodsTotal,
+		o.Payment.CustomFee,
 	); err != nil {
-		return nil, wrapDBError("failed to insert into payments while creating order", "", err)
+		return err
 	}
-	newOrder.Payment = order.Payment
+	newOrder.Payment = o.Payment
+
+	newOrder.Items, err = r.insertItems(ctx, q, o.Items)
+	return err
+}
 
-	newOrder.Items = make([]*model.Item, len(order.Items))
-	for i, item := range order.Items {
-		row := tx.QueryRowContext(ctx, insertIntoItemsQuery,
+func (r *OrderRepository) insertItems(ctx context.Context, q Querier, items []*model.Item) ([]*model.Item, error) {
+	newItems := make([]*model.Item, len(items))
+	for i, item := range items {
+		row := q.QueryRowContext(ctx, insertIntoItemsQuery,
 			item.OrderUID,
 			item.ChrtID,
 			item.TrackNumber,
@@ -185,12 +299,63 @@ func (r *OrderRepository) UpsertOrder(ctx context.Context, order *model.Order) (
 
 		var newItemID int
 		if err := row.Scan(&newItemID); err != nil {
-			return nil, wrapDBError("failed to insert into items while creating order", "", err)
+			return nil, err
 		}
-		newOrder.Items[i] = copyItem(item, newItemID)
+		newItems[i] = copyItem(item, newItemID)
+	}
+	return newItems, nil
+}
+
+func (r *OrderRepository) writeOutbox(ctx context.Context, q Querier, order *model.Order, created bool) error {
+	if !r.outbox {
+		return nil
 	}
 
-	return newOrder, nil
+	eventID, err := newEventID()
+	if err != nil {
+		return wrapDBError("failed to generate outbox event id for order", order.OrderUID, err)
+	}
+	event := model.OrderStoredEvent{
+		EventID:  eventID,
+		Type:     model.EventOrderUpdated,
+		StoredAt: time.Now().UTC(),
+		Order:    order,
+	}
+	if created {
+		event.Type = model.EventOrderCreated
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return wrapDBError("failed to encode outbox event of order", order.OrderUID, err)
+	}
+	if _, err := q.ExecContext(ctx, insertOutboxQuery, event.EventID, order.OrderUID, event.Type, string(payload)); err != nil {
+		return wrapDBError("failed to write outbox event of order", order.OrderUID, err)
+	}
+	return nil
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (r *OrderRepository) notifyOrderChanged(ctx context.Context, q Querier, orderUID string) error {
+	if r.notifyChannel == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(model.OrderChange{OrderUID: orderUID, Origin: r.origin})
+	if err != nil {
+		return wrapDBError("failed to encode change notification of order", orderUID, err)
+	}
+	if _, err := q.ExecContext(ctx, notifyOrderChangedQuery, r.notifyChannel, string(payload)); err != nil {
+		return wrapDBError("failed to notify about change of order", orderUID, err)
+	}
+	return nil
 }
 
 func copyItem(item *model.Item, id int) *model.Item {
@@ -211,57 +376,26 @@ func copyItem(item *model.Item, id int) *model.Item {
 	}
 }
 
-func (r *OrderRepository) orderFullUpdate(ctx context.Context, q Querier, o *model.Order) (*model.Order, error) {
-	var newOrder *model.Order
-
-	if _, err := q.ExecContext(ctx,
-		deleteItemsQuery, o.OrderUID); err != nil {
-		return nil, err
-	}
-
-	newOrder, err := r.updateOrderFields(ctx, q, o)
-	if err != nil {
-		return nil, err
+func (r *OrderRepository) replaceOrderDetails(ctx context.Context, q Querier, o *model.Order, newOrder *model.Order) error {
+	if _, err := q.ExecContext(ctx, deleteItemsQuery, o.OrderUID); err != nil {
+		return err
 	}
 
 	newDelivery, err := r.updateDeliveryFields(ctx, q, &o.Delivery)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	newPayment, err := r.updatePaymentFields(ctx, q, &o.Payment)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	newOrder.Delivery = *newDelivery
 	newOrder.Payment = *newPayment
 
-	for _, item := range o.Items {
-		newItem, err := r.updateItemFields(ctx, q, item)
-		if err != nil {
-			return nil, err
-		}
-		newOrder.Items = append(newOrder.Items, newItem)
-	}
-	return newOrder, nil
-}
-
-func (r *OrderRepository) updateOrderFields(ctx context.Context, q Querier, o *model.Order) (*model.Order, error) {
-	row := q.QueryRowContext(ctx, updateOrderQuery,
-		o.TrackNumber,
-		o.Entry,
-		o.Locale,
-		o.InternalSignature,
-		o.CustomerID,
-		o.DeliveryService,
-		o.Shardkey,
-		o.SmID,
-		o.DateCreated,
-		o.OofShard,
-		o.OrderUID,
-	)
-	return dto.ScanOrderFromRow(row)
+	newOrder.Items, err = r.insertItems(ctx, q, o.Items)
+	return err
 }
 
 func (r *OrderRepository) updateDeliveryFields(ctx context.Context, q Querier, d *model.Delivery) (*model.Delivery, error) {
@@ -294,108 +428,78 @@ func (r *OrderRepository) updatePaymentFields(ctx context.Context, q Querier, p
 	return dto.ScanPaymentFromRow(row)
 }
 
-func (r *OrderRepository) updateItemFields(ctx context.Context, q Querier, i *model.Item) (*model.Item, error) {
-	row := q.QueryRowContext(ctx, updateItemQuery,
-		i.OrderUID,
-		i.ChrtID,
-		i.TrackNumber,
-		i.Price,
-		i.RID,
-		i.Name,
-		i.Sale,
-		i.Size,
-		i.TotalPrice,
-		i.NmID,
-		i.Brand,
-		i.Status,
-		i.ID,
-	)
-	return dto.ScanItemFromRow(row)
-}
-
-func (r *OrderRepository) GetOrderByUID(ctx context.Context, orderUID string) (order *model.Order, err error) {
-	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, wrapDBError("failed to begin transaction", "", err)
-	}
-
-	defer func() {
-		err = r.finishTransaction(tx, err)
-	}()
-
-	order, err = r.getOrderByOrderUID(ctx, tx, orderUID)
+func (r *OrderRepository) GetOrderByUID(ctx context.Context, orderUID string) (*model.Order, error) {
+	var order *model.Order
+	err := r.read(ctx, func(db *sql.DB) (err error) {
+		order, err = dto.ScanHydratedOrderFromRow(traced(db).QueryRowContext(ctx, getOrderByIDQuery, orderUID))
+		return err
+	})
 	if err != nil {
 		return nil, wrapDBError("failed to get order by id", orderUID, err)
 	}
+	return order, nil
+}
 
-	delivery, err := r.getDeliveryByOrderUID(ctx, tx, orderUID)
-	if err != nil {
-		return nil, wrapDBError("failed to get delivery by order_uid", orderUID, err)
+func (r *OrderRepository) OrderExists(ctx context.Context, orderUID string) (bool, error) {
+	var exists bool
+	if err := traced(r.db).QueryRowContext(ctx, orderExistsQuery, orderUID).Scan(&exists); err != nil {
+		return false, wrapDBError("failed to check existence of order", orderUID, err)
 	}
-
-	payment, err := r.getPaymentByOrderUID(ctx, tx, orderUID)
-	if err != nil {
-		return nil, wrapDBError("failed to get payment by order id", orderUID, err)
-	}
-
-	order.Delivery = *delivery
-	order.Payment = *payment
-
-	return order, nil
+	return exists, nil
 }
 
-func (r *OrderRepository) GetAllOrders(ctx context.Context, limit int) (orders []*model.Order, err error) {
-	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, wrapDBError("failed to begin transaction", "", err)
+func (r *OrderRepository) LastUpsertAt(ctx context.Context) (time.Time, error) {
+	var lastUpsert time.Time
+	if err := traced(r.db).QueryRowContext(ctx, lastUpsertAtQuery).Scan(&lastUpsert); err != nil {
+		return time.Time{}, wrapDBError("failed to get last upsert time", "", err)
 	}
+	return lastUpsert, nil
+}
 
-	defer func() {
-		err = r.finishTransaction(tx, err)
-	}()
+func (r *OrderRepository) GetAllOrders(ctx context.Context, limit int) ([]*model.Order, error) {
+	return r.GetRecentOrders(ctx, nil, limit)
+}
 
-	orders = make([]*model.Order, 0)
-	orders, err = r.getAllOrders(ctx, tx, limit)
+func (r *OrderRepository) GetRecentOrders(ctx context.Context, before *model.OrderCursor, limit int) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := r.read(ctx, func(db *sql.DB) (err error) {
+		if before == nil {
+			orders, err = queryRows(ctx, traced(db), dto.ScanHydratedOrderFromRow, getAllOrdersQuery, limit)
+		} else {
+			orders, err = queryRows(ctx, traced(db), dto.ScanHydratedOrderFromRow, getOrdersBeforeQuery, before.DateCreated, before.OrderUID, limit)
+		}
+		return err
+	})
 	if err != nil {
-		return nil, wrapDBError("failed to get all orders", "", err)
+		return nil, wrapDBError("failed to get recent orders", "", err)
 	}
+	return orders, nil
+}
 
-	for i, order := range orders {
-		delivery, err := r.getDeliveryByOrderUID(ctx, tx, order.OrderUID)
+// GetOrdersByUIDs always reads from the primary: it is used to refresh cache
+// entries right after a change and must not see a lagging replica.
+func (r *OrderRepository) GetOrdersByUIDs(ctx context.Context, orderUIDs []string) (map[string]*model.Order, error) {
+	orders := make(map[string]*model.Order, len(orderUIDs))
+	for start := 0; start < len(orderUIDs); start += hydrationBatchSize {
+		batch := orderUIDs[start:min(start+hydrationBatchSize, len(orderUIDs))]
+		fetched, err := queryRows(ctx, traced(r.db), dto.ScanHydratedOrderFromRow, getOrdersByUIDsQuery, pq.Array(batch))
 		if err != nil {
-			return nil, wrapDBError("failed to get delivery of order", order.OrderUID, err)
+			return nil, wrapDBError("failed to get orders by uids", "", err)
 		}
-
-		payment, err := r.getPaymentByOrderUID(ctx, tx, order.OrderUID)
-		if err != nil {
-			return nil, wrapDBError("failed to get payment of order", order.OrderUID, err)
+		for _, order := range fetched {
+			orders[order.OrderUID] = order
 		}
-
-		items, err := r.getAllItemsByOrderUID(ctx, tx, order.OrderUID, limit)
-		if err != nil {
-			return nil, wrapDBError("failed to get items for order", order.OrderUID, err)
-		}
-
-		orders[i].Delivery = *delivery
-		orders[i].Payment = *payment
-		orders[i].Items = items
 	}
-
 	return orders, nil
 }
 
 func (r *OrderRepository) GetItemsByOrderUID(ctx context.Context, orderUID string, lastID, limit int) (items []*model.Item, err error) {
-	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, wrapDBError("failed to begin transaction", "", err)
-	}
-
-	defer func() {
-		err = r.finishTransaction(tx, err)
-	}()
-
-	items = make([]*model.Item, 0, limit)
-	items, err = r.getItemsByOrderUID(ctx, tx, orderUID, lastID, limit)
+	err = r.read(ctx, func(db *sql.DB) error {
+		return readOnlyTx(ctx, db, func(q Querier) (err error) {
+			items, err = r.getItemsByOrderUID(ctx, q, orderUID, lastID, limit)
+			return err
+		})
+	})
 	if err != nil {
 		return nil, wrapDBError("failed to get items of order", orderUID, err)
 	}
@@ -403,61 +507,48 @@ func (r *OrderRepository) GetItemsByOrderUID(ctx context.Context, orderUID strin
 	return items, nil
 }
 
-func (r *OrderRepository) getOrderByOrderUID(ctx context.Context, q Querier, orderUID string) (*model.Order, error) {
-	row := q.QueryRowContext(ctx, getOrderByIDQuery, orderUID)
-	order, err := dto.ScanOrderFromRow(row)
+func (r *OrderRepository) GetItemsByOrderUIDs(ctx context.Context, orderUIDs []string, lastID, limit int) (items map[string][]*model.Item, err error) {
+	err = r.read(ctx, func(db *sql.DB) error {
+		return readOnlyTx(ctx, db, func(q Querier) (err error) {
+			items, err = r.getItemsByOrderUIDs(ctx, q, orderUIDs, lastID, limit)
+			return err
+		})
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapDBError("failed to get items of orders", "", err)
 	}
-	return order, nil
+
+	return items, nil
 }
 
-func (r *OrderRepository) getAllOrders(ctx context.Context, q Querier, limit int) ([]*model.Order, error) {
-	orders := make([]*model.Order, 0)
+func (r *OrderRepository) getItemsByOrderUID(ctx context.Context, q Querier, orderUID string, lastID int, limit int) ([]*model.Item, error) {
+	var items []*model.Item
 
-	rows, err := q.QueryContext(ctx, getAllOrdersQuery, limit)
+	rows, err := q.QueryContext(ctx, getItemsByOrderUIDQuery, orderUID, lastID, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		order, err := dto.ScanOrderFromRow(rows)
+		item, err := dto.ScanItemFromRow(rows)
 		if err != nil {
 			return nil, err
 		}
-		orders = append(orders, order)
+		items = append(items, item)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return orders, nil
-}
-
-func (r *OrderRepository) getDeliveryByOrderUID(ctx context.Context, q Querier, orderUID string) (*model.Delivery, error) {
-	row := q.QueryRowContext(ctx, getDeliveryByOrderUIDQuery, orderUID)
-	delivery, err := dto.ScanDeliveryFromRow(row)
-	if err != nil {
-		return nil, err
-	}
-	return delivery, nil
-}
-
-func (r *OrderRepository) getPaymentByOrderUID(ctx context.Context, q Querier, orderUID string) (*model.Payment, error) {
-	row := q.QueryRowContext(ctx, getPaymentByOrderUIDQuery, orderUID)
-	payment, err := dto.ScanPaymentFromRow(row)
-	if err != nil {
-		return nil, err
-	}
-	return payment, nil
+	return items, nil
 }
 
-func (r *OrderRepository) getItemsByOrderUID(ctx context.Context, q Querier, orderUID string, lastID int, limit int) ([]*model.Item, error) {
-	var items []*model.Item
+func (r *OrderRepository) getItemsByOrderUIDs(ctx context.Context, q Querier, orderUIDs []string, lastID int, limit int) (map[string][]*model.Item, error) {
+	items := make(map[string][]*model.Item, len(orderUIDs))
 
-	rows, err := q.QueryContext(ctx, getItemsByOrderUIDQuery, orderUID, lastID, limit)
+	rows, err := q.QueryContext(ctx, getItemsByOrderUIDsQuery, pq.Array(orderUIDs), lastID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -468,7 +559,7 @@ func (r *OrderRepository) getItemsByOrderUID(ctx context.Context, q Querier, ord
 		if err != nil {
 			return nil, err
 		}
-		items = append(items, item)
+		items[item.OrderUID] = append(items[item.OrderUID], item)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -478,31 +569,55 @@ func (r *OrderRepository) getItemsByOrderUID(ctx context.Context, q Querier, ord
 	return items, nil
 }
 
-func (r *OrderRepository) getAllItemsByOrderUID(ctx context.Context, q Querier, orderUID string, limit int) ([]*model.Item, error) {
-	var items []*model.Item
-
-	rows, err := q.QueryContext(ctx, getAllItemsByOrderUIDQuery, orderUID, limit)
-	if err != nil {
+func (r *OrderRepository) replaceOrderFlags(ctx context.Context, q Querier, orderUID string, flags []*model.OrderFlag) ([]*model.OrderFlag, error) {
+	if _, err := q.ExecContext(ctx, deleteOrderFlagsQuery, orderUID); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		item, err := dto.ScanItemFromRow(rows)
+	var stored []*model.OrderFlag
+	for _, flag := range flags {
+		row := q.QueryRowContext(ctx, insertOrderFlagQuery, orderUID, flag.Rule, flag.Message)
+		newFlag, err := dto.ScanOrderFlagFromRow(row)
 		if err != nil {
 			return nil, err
 		}
-		items = append(items, item)
+		stored = append(stored, newFlag)
 	}
+	return stored, nil
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+// read runs fn on a healthy replica when replicas are configured. If the
+// replica fails or does not have the row yet, fn is retried on the primary.
+func (r *OrderRepository) read(ctx context.Context, fn func(*sql.DB) error) error {
+	rep := r.replicas.pick()
+	if rep == nil {
+		return fn(r.db)
 	}
 
-	return items, nil
+	err := fn(rep.db)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		r.replicas.markDown(ctx, rep, err)
+	}
+	return fn(r.db)
+}
+
+func readOnlyTx(ctx context.Context, db *sql.DB, fn func(Querier) error) (err error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = finishTransaction(tx, err)
+	}()
+
+	return fn(traced(tx))
 }
 
-func (r *OrderRepository) finishTransaction(tx *sql.Tx, origErr error) error {
+func finishTransaction(tx *sql.Tx, origErr error) error {
 	if origErr != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("%v\n%w: rollback failed:\n[%v]\n", origErr, srvcerrors.ErrDatabase, rbErr)
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/dto"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+)
+
+const (
+	benchOrders = 200
+	benchItems  = 5
+)
+
+const (
+	perQueryOrderQuery = `SELECT order_uid, track_number, entry, locale, internal_signature,
+		customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
+		FROM orders WHERE order_uid = $1`
+	perQueryDeliveryQuery = `SELECT * FROM deliveries WHERE order_uid = $1`
+	perQueryPaymentQuery  = `SELECT * FROM payments WHERE transaction = $1`
+	perQueryItemsQuery    = `SELECT ` + itemColumns + ` FROM items WHERE order_uid = $1 ORDER BY id`
+	perQueryFlagsQuery    = `SELECT rule, message, created_at FROM order_flags WHERE order_uid = $1 ORDER BY id`
+)
+
+// getOrdersPerQuery is the previous hydration path: one query per table and order.
+func getOrdersPerQuery(ctx context.Context, q Querier, orderUIDs []string) (map[string]*model.Order, error) {
+	orders := make(map[string]*model.Order, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		order, err := dto.ScanOrderFromRow(q.QueryRowContext(ctx, perQueryOrderQuery, orderUID))
+		if err != nil {
+			return nil, err
+		}
+		delivery, err := dto.ScanDeliveryFromRow(q.QueryRowContext(ctx, perQueryDeliveryQuery, orderUID))
+		if err != nil {
+			return nil, err
+		}
+		payment, err := dto.ScanPaymentFromRow(q.QueryRowContext(ctx, perQueryPaymentQuery, orderUID))
+		if err != nil {
+			return nil, err
+		}
+		if order.Items, err = queryRows(ctx, q, dto.ScanItemFromRow, perQueryItemsQuery, orderUID); err != nil {
+			return nil, err
+		}
+		if order.Flags, err = queryRows(ctx, q, dto.ScanOrderFlagFromRow, perQueryFlagsQuery, orderUID); err != nil {
+			return nil, err
+		}
+		order.Delivery = *delivery
+		order.Payment = *payment
+		orders[orderUID] = order
+	}
+	return orders, nil
+}
+
+func seedBenchOrders(b *testing.B) []string {
+	b.Helper()
+	if _, err := TestDB.Exec("TRUNCATE TABLE items, payments, deliveries, orders RESTART IDENTITY CASCADE"); err != nil {
+		b.Fatal(err)
+	}
+
+	repo := NewOrderRepository(TestDB)
+	uids := make([]string, 0, benchOrders)
+	for i := 0; i < benchOrders; i++ {
+		order := generateTestOrder()
+		order.OrderUID = fmt.Sprintf("bench-%d", i)
+		order.Delivery.OrderUID = order.OrderUID
+		order.Payment.Transaction = order.OrderUID
+		template := order.Items[0]
+		order.Items = nil
+		for j := 0; j < benchItems; j++ {
+			item := *template
+			item.OrderUID = order.OrderUID
+			order.Items = append(order.Items, &item)
+		}
+		if _, _, err := repo.UpsertOrder(context.Background(), order); err != nil {
+			b.Fatal(err)
+		}
+		uids = append(uids, order.OrderUID)
+	}
+	return uids
+}
+
+func BenchmarkOrderHydration(b *testing.B) {
+	uids := seedBenchOrders(b)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	for _, size := range []int{1, 10, benchOrders} {
+		batch := uids[:size]
+
+		b.Run(fmt.Sprintf("per_query/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := getOrdersPerQuery(ctx, TestDB, batch); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("json_agg/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetOrdersByUIDs(ctx, batch); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
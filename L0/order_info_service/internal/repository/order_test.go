@@ -15,7 +15,7 @@ import (
 	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -82,7 +82,7 @@ func TestCreateOrder_Success(t *testing.T) {
 
 	order := generateTestOrder()
 
-	createdOrder, err := repo.UpsertOrder(ctx, order)
+	createdOrder, _, err := repo.UpsertOrder(ctx, order)
 
 	require.NoError(t, err)
 	require.NotNil(t, createdOrder)
@@ -106,11 +106,11 @@ func TestCreateOrder_Fail(t *testing.T) {
 	order := generateTestOrder()
 
 	mock.ExpectBegin()
-	expectQuery := regexp.QuoteMeta(insertIntoOrdersQuery)
+	expectQuery := regexp.QuoteMeta(upsertOrderQuery)
 	mock.ExpectQuery(expectQuery).WillReturnError(srvcerrors.ErrDatabase)
 	mock.ExpectRollback()
 
-	createdOrder, err := repo.UpsertOrder(ctx, order)
+	createdOrder, _, err := repo.UpsertOrder(ctx, order)
 
 	require.Error(t, err)
 	require.Nil(t, createdOrder)
@@ -118,13 +118,156 @@ func TestCreateOrder_Fail(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpsertOrder_NotifiesChange(t *testing.T) {
+	clearTables(t)
+	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s "+
+		"password=%s dbname=%s sslmode=disable",
+		host, port, user, password, dbname)
+	listener := pq.NewListener(psqlInfo, time.Second, time.Second, nil)
+	defer listener.Close()
+	require.NoError(t, listener.Listen("order_changes_test"))
+
+	repo := NewOrderRepository(TestDB, WithChangeNotifications("order_changes_test", "instance-a"))
+	_, _, err := repo.UpsertOrder(context.Background(), generateTestOrder())
+	require.NoError(t, err)
+
+	select {
+	case n := <-listener.Notify:
+		require.NotNil(t, n)
+		assert.JSONEq(t, `{"order_uid":"test-order-uid","origin":"instance-a"}`, n.Extra)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+	}
+}
+
+func TestUpsertOrder_NotifyFail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := NewOrderRepository(db, WithChangeNotifications("order_changes", "instance-a"))
+	order := generateTestOrder()
+	order.Items = nil
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(upsertOrderQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"order_uid", "track_number", "entry", "locale", "internal_signature",
+			"customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "inserted"}).
+			AddRow(order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature,
+				order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard, true))
+	mock.ExpectExec(regexp.QuoteMeta(insertIntoDeliveriesQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(insertIntoPaymentsQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(deleteOrderFlagsQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(notifyOrderChangedQuery)).
+		WithArgs("order_changes", `{"order_uid":"test-order-uid","origin":"instance-a"}`).
+		WillReturnError(srvcerrors.ErrDatabase)
+	mock.ExpectRollback()
+
+	createdOrder, _, err := repo.UpsertOrder(context.Background(), order)
+
+	require.Error(t, err)
+	require.Nil(t, createdOrder)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertOrder_WritesOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := NewOrderRepository(db, WithOutbox())
+	order := generateTestOrder()
+	order.Items = nil
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(upsertOrderQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"order_uid", "track_number", "entry", "locale", "internal_signature",
+			"customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "inserted"}).
+			AddRow(order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature,
+				order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard, true))
+	mock.ExpectExec(regexp.QuoteMeta(insertIntoDeliveriesQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(insertIntoPaymentsQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(deleteOrderFlagsQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxQuery)).
+		WithArgs(sqlmock.AnyArg(), order.OrderUID, model.EventOrderCreated, sqlmock.AnyArg()).
+		WillReturnError(srvcerrors.ErrDatabase)
+	mock.ExpectRollback()
+
+	createdOrder, _, err := repo.UpsertOrder(context.Background(), order)
+
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.Nil(t, createdOrder)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertOrder_UpdateWritesUpdatedEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := NewOrderRepository(db, WithOutbox())
+	order := generateTestOrder()
+	order.Items = nil
+	d, p := order.Delivery, order.Payment
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(upsertOrderQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"order_uid", "track_number", "entry", "locale", "internal_signature",
+			"customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "inserted"}).
+			AddRow(order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature,
+				order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard, false))
+	mock.ExpectExec(regexp.QuoteMeta(deleteItemsQuery)).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(regexp.QuoteMeta(updateDeliveryQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"order_uid", "name", "phone", "zip", "city", "address", "region", "email"}).
+			AddRow(d.OrderUID, d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Email))
+	mock.ExpectQuery(regexp.QuoteMeta(updatePaymentQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction", "request_id", "currency", "provider", "amount",
+			"payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee"}).
+			AddRow(p.Transaction, p.RequestID, p.Currency, p.Provider, p.Amount,
+				p.PaymentDT, p.Bank, p.DeliveryCost, p.GoodsTotal, p.CustomFee))
+	mock.ExpectExec(regexp.QuoteMeta(deleteOrderFlagsQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxQuery)).
+		WithArgs(sqlmock.AnyArg(), order.OrderUID, model.EventOrderUpdated, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	stored, created, err := repo.UpsertOrder(context.Background(), order)
+
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, order.Delivery, stored.Delivery)
+	assert.Equal(t, order.Payment, stored.Payment)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertOrder_ReplacesItems(t *testing.T) {
+	clearTables(t)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	order := generateTestOrder()
+	_, _, err := repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+
+	order.Items = order.Items[1:]
+	order.Items[0].Price = 4000
+	updatedOrder, _, err := repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+	require.Len(t, updatedOrder.Items, 1)
+	assert.NotZero(t, updatedOrder.Items[0].ID)
+
+	items, err := repo.GetItemsByOrderUID(ctx, order.OrderUID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, 4000, items[0].Price)
+	assert.Equal(t, updatedOrder.Items[0].ID, items[0].ID)
+}
+
 func TestGetOrderByID_Success(t *testing.T) {
 	clearTables(t)
 	repo := NewOrderRepository(TestDB)
 	ctx := context.Background()
 
 	order := generateTestOrder()
-	createdOrder, err := repo.UpsertOrder(ctx, order)
+	createdOrder, _, err := repo.UpsertOrder(ctx, order)
 	require.NoError(t, err)
 	require.NotNil(t, createdOrder)
 
@@ -149,6 +292,25 @@ func TestGetOrderByID_Fail(t *testing.T) {
 	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
 }
 
+func TestOrderExists(t *testing.T) {
+	clearTables(t)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	order := generateTestOrder()
+
+	exists, err := repo.OrderExists(ctx, order.OrderUID)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	_, _, err = repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+
+	exists, err = repo.OrderExists(ctx, order.OrderUID)
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
 func TestGetAllOrders_Success(t *testing.T) {
 	clearTables(t)
 	repo := NewOrderRepository(TestDB)
@@ -162,7 +324,7 @@ func TestGetAllOrders_Success(t *testing.T) {
 		for _, item := range order.Items {
 			item.OrderUID = order.OrderUID
 		}
-		_, err := repo.UpsertOrder(ctx, order)
+		_, _, err := repo.UpsertOrder(ctx, order)
 		require.NoError(t, err)
 	}
 
@@ -177,9 +339,7 @@ func TestGetAllOrders_Fail(t *testing.T) {
 	repo := NewOrderRepository(db)
 	ctx := context.Background()
 
-	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(getAllOrdersQuery)).WillReturnError(srvcerrors.ErrDatabase)
-	mock.ExpectRollback()
 
 	orders, err := repo.GetAllOrders(ctx, 10)
 
@@ -189,6 +349,172 @@ func TestGetAllOrders_Fail(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetRecentOrders_Success(t *testing.T) {
+	clearTables(t)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := range(3) {
+		order := generateTestOrder()
+		order.OrderUID = fmt.Sprintf("uid-%d", i)
+		order.DateCreated = base.Add(time.Duration(i) * time.Hour)
+		order.Delivery.OrderUID = order.OrderUID
+		order.Payment.Transaction = order.OrderUID
+		for _, item := range order.Items {
+			item.OrderUID = order.OrderUID
+		}
+		_, _, err := repo.UpsertOrder(ctx, order)
+		require.NoError(t, err)
+	}
+
+	orders, err := repo.GetAllOrders(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, "uid-2", orders[0].OrderUID)
+	assert.Len(t, orders[0].Items, 2)
+
+	cursor := &model.OrderCursor{DateCreated: orders[0].DateCreated, OrderUID: orders[0].OrderUID}
+	orders, err = repo.GetRecentOrders(ctx, cursor, 10)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, "uid-1", orders[0].OrderUID)
+	assert.Equal(t, "uid-0", orders[1].OrderUID)
+}
+
+func TestGetRecentOrders_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewOrderRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(getOrdersBeforeQuery)).WillReturnError(srvcerrors.ErrDatabase)
+
+	orders, err := repo.GetRecentOrders(context.Background(), &model.OrderCursor{OrderUID: "uid-1"}, 10)
+
+	require.Error(t, err)
+	require.Nil(t, orders)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrdersByUIDs_Success(t *testing.T) {
+	clearTables(t)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	for i := range(3) {
+		order := generateTestOrder()
+		order.OrderUID = fmt.Sprintf("uid-%d", i)
+		order.Delivery.OrderUID = order.OrderUID
+		order.Payment.Transaction = order.OrderUID
+		for _, item := range order.Items {
+			item.OrderUID = order.OrderUID
+		}
+		if i == 0 {
+			order.Flags = []*model.OrderFlag{{Rule: "payment_amount", Message: "amount mismatch"}}
+		}
+		_, _, err := repo.UpsertOrder(ctx, order)
+		require.NoError(t, err)
+	}
+
+	orders, err := repo.GetOrdersByUIDs(ctx, []string{"uid-0", "uid-2", "missing"})
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	require.Contains(t, orders, "uid-0")
+	assert.Equal(t, "uid-0", orders["uid-0"].Delivery.OrderUID)
+	assert.Equal(t, "uid-0", orders["uid-0"].Payment.Transaction)
+	assert.Len(t, orders["uid-0"].Items, 2)
+	assert.Less(t, orders["uid-0"].Items[0].ID, orders["uid-0"].Items[1].ID)
+	require.Len(t, orders["uid-0"].Flags, 1)
+	assert.Nil(t, orders["uid-2"].Flags)
+}
+
+func TestGetOrderByUID_Hydrated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewOrderRepository(db)
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{
+		"order_uid", "track_number", "entry", "locale", "internal_signature",
+		"customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+		"order_uid", "name", "phone", "zip", "city", "address", "region", "email",
+		"transaction", "request_id", "currency", "provider", "amount", "payment_dt", "bank",
+		"delivery_cost", "goods_total", "custom_fee", "flags", "items",
+	}).AddRow(
+		"uid-1", "track-123", "WBIL", "en", "", "customer", "meest", "9", 99, created, "1",
+		"uid-1", "Test", "+9720000000", "2639809", "Kiryat Mozkin", "Ploshad Mira 15", "Kraiot", "test@gmail.com",
+		"uid-1", "", "USD", "wbpay", 1817, 1637907727, "alpha", 1500, 317, 0,
+		[]byte(`[{"rule":"payment_amount","message":"amount mismatch","created_at":"2024-05-01T12:00:00.5+00:00"}]`),
+		nil,
+	)
+	mock.ExpectQuery(regexp.QuoteMeta(getOrderByIDQuery)).WithArgs("uid-1").WillReturnRows(rows)
+
+	order, err := repo.GetOrderByUID(context.Background(), "uid-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Kiryat Mozkin", order.Delivery.City)
+	assert.Equal(t, 1817, order.Payment.Amount)
+	assert.Nil(t, order.Items)
+	require.Len(t, order.Flags, 1)
+	assert.Equal(t, "payment_amount", order.Flags[0].Rule)
+	assert.Equal(t, 500*time.Millisecond, order.Flags[0].CreatedAt.Sub(created))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrdersByUIDs_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewOrderRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(getOrdersByUIDsQuery)).WillReturnError(srvcerrors.ErrDatabase)
+
+	orders, err := repo.GetOrdersByUIDs(context.Background(), []string{"uid-1"})
+
+	require.Error(t, err)
+	require.Nil(t, orders)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLastUpsertAt_Success(t *testing.T) {
+	clearTables(t)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	empty, err := repo.LastUpsertAt(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), empty.Unix())
+
+	order := generateTestOrder()
+	order.Items = nil
+	_, _, err = repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+	created, err := repo.LastUpsertAt(ctx)
+	require.NoError(t, err)
+	assert.True(t, created.After(empty))
+
+	_, _, err = repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+	updated, err := repo.LastUpsertAt(ctx)
+	require.NoError(t, err)
+	assert.True(t, updated.After(created))
+}
+
+func TestLastUpsertAt_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewOrderRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(lastUpsertAtQuery)).WillReturnError(srvcerrors.ErrDatabase)
+
+	_, err = repo.LastUpsertAt(context.Background())
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetItemsByOrderUID_Success(t *testing.T) {
 	clearTables(t)
 	repo := NewOrderRepository(TestDB)
@@ -208,7 +534,7 @@ func TestGetItemsByOrderUID_Success(t *testing.T) {
 		},
 	}...)
 	
-	createdOrder, err := repo.UpsertOrder(ctx, order)
+	createdOrder, _, err := repo.UpsertOrder(ctx, order)
 	require.NoError(t, err)
 	require.NotNil(t, createdOrder)
 
@@ -237,6 +563,52 @@ func TestGetItemsByOrderUID_Fail(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetItemsByOrderUIDs_Success(t *testing.T) {
+	clearTables(t)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	uids := make([]string, 0, 3)
+	for i := range 3 {
+		order := generateTestOrder()
+		order.OrderUID = fmt.Sprintf("uid-%d", i)
+		order.Delivery.OrderUID = order.OrderUID
+		order.Payment.Transaction = order.OrderUID
+		for _, item := range order.Items {
+			item.OrderUID = order.OrderUID
+		}
+		_, _, err := repo.UpsertOrder(ctx, order)
+		require.NoError(t, err)
+		uids = append(uids, order.OrderUID)
+	}
+
+	items, err := repo.GetItemsByOrderUIDs(ctx, append(uids, "nonexistent"), 0, 1)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	for _, uid := range uids {
+		require.Len(t, items[uid], 1)
+		assert.Equal(t, uid, items[uid][0].OrderUID)
+	}
+}
+
+func TestGetItemsByOrderUIDs_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewOrderRepository(db)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(getItemsByOrderUIDsQuery)).WillReturnError(srvcerrors.ErrDatabase)
+	mock.ExpectRollback()
+
+	items, err := repo.GetItemsByOrderUIDs(ctx, []string{"any-uid"}, 0, 10)
+
+	require.Error(t, err)
+	require.Nil(t, items)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func generateTestOrder() *model.Order {
 	return &model.Order{
 		OrderUID:          "test-order-uid",
@@ -307,3 +679,23 @@ func generateTestOrder() *model.Order {
 		},
 	}
 }
+
+func TestUpsertOrder_StoresFlags(t *testing.T) {
+	clearTables(t)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	order := generateTestOrder()
+	order.Flags = []*model.OrderFlag{{Rule: "payment_amount", Message: "amount mismatch"}}
+
+	createdOrder, _, err := repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+	require.Len(t, createdOrder.Flags, 1)
+	assert.False(t, createdOrder.Flags[0].CreatedAt.IsZero())
+
+	gotOrder, err := repo.GetOrderByUID(ctx, order.OrderUID)
+	require.NoError(t, err)
+	require.Len(t, gotOrder.Flags, 1)
+	assert.Equal(t, "payment_amount", gotOrder.Flags[0].Rule)
+	assert.Equal(t, "amount mismatch", gotOrder.Flags[0].Message)
+}
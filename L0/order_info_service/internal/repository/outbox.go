@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/dto"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/lib/pq"
+)
+
+// outboxLockKey is the advisory lock held by the relay draining the outbox,
+// so that only one instance publishes at a time and order is preserved.
+const outboxLockKey = 0x6f7574626f78
+
+const (
+	tryLockOutboxQuery = `SELECT pg_try_advisory_xact_lock($1)`
+
+	getOutboxBatchQuery = `SELECT id, event_id, order_uid, event_type, payload, created_at
+		FROM order_outbox
+		ORDER BY id
+		LIMIT $1`
+
+	deleteOutboxQuery = `DELETE FROM order_outbox WHERE id = ANY($1)`
+)
+
+// PublishFunc publishes msgs in order and returns how many of them, counted
+// from the start, were delivered.
+type PublishFunc func(ctx context.Context, msgs []*model.OutboxMessage) (int, error)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// RelayOutbox hands up to limit of the oldest outbox messages to publish and
+// deletes the delivered ones. It returns 0 without publishing if another
+// relay currently holds the outbox. Messages are deleted only after publish
+// returns, so a crash in between leads to them being published again.
+func (r *OutboxRepository) RelayOutbox(ctx context.Context, limit int, publish PublishFunc) (int, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, wrapDBError("failed to begin transaction", "", err)
+	}
+
+	delivered, publishErr, err := relayOutboxBatch(ctx, traced(tx), limit, publish)
+	if err = finishTransaction(tx, err); err != nil {
+		return 0, err
+	}
+	return delivered, publishErr
+}
+
+func relayOutboxBatch(ctx context.Context, q Querier, limit int, publish PublishFunc) (int, error, error) {
+	var locked bool
+	if err := q.QueryRowContext(ctx, tryLockOutboxQuery, int64(outboxLockKey)).Scan(&locked); err != nil {
+		return 0, nil, wrapDBError("failed to lock outbox", "", err)
+	}
+	if !locked {
+		return 0, nil, nil
+	}
+
+	msgs, err := queryRows(ctx, q, dto.ScanOutboxMessageFromRow, getOutboxBatchQuery, limit)
+	if err != nil {
+		return 0, nil, wrapDBError("failed to get outbox messages", "", err)
+	}
+	if len(msgs) == 0 {
+		return 0, nil, nil
+	}
+
+	delivered, publishErr := publish(ctx, msgs)
+	delivered = min(max(delivered, 0), len(msgs))
+	if delivered > 0 {
+		ids := make([]int64, delivered)
+		for i, msg := range msgs[:delivered] {
+			ids[i] = msg.ID
+		}
+		if _, err := q.ExecContext(ctx, deleteOutboxQuery, pq.Array(ids)); err != nil {
+			return 0, nil, wrapDBError("failed to delete published outbox messages", "", err)
+		}
+	}
+	return delivered, publishErr, nil
+}
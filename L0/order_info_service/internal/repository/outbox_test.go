@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox_UpsertAndRelay(t *testing.T) {
+	clearTables(t)
+	_, err := TestDB.Exec("TRUNCATE TABLE order_outbox RESTART IDENTITY")
+	require.NoError(t, err)
+
+	orders := NewOrderRepository(TestDB, WithOutbox())
+	outbox := NewOutboxRepository(TestDB)
+	ctx := context.Background()
+
+	order := generateTestOrder()
+	_, _, err = orders.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+	order.Items = order.Items[:1]
+	_, _, err = orders.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+
+	var published []*model.OutboxMessage
+	delivered, err := outbox.RelayOutbox(ctx, 10, func(_ context.Context, msgs []*model.OutboxMessage) (int, error) {
+		published = append(published, msgs...)
+		return len(msgs), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	require.Len(t, published, 2)
+
+	var types []string
+	for _, msg := range published {
+		assert.Equal(t, order.OrderUID, msg.OrderUID)
+		var event model.OrderStoredEvent
+		require.NoError(t, json.Unmarshal(msg.Payload, &event))
+		assert.Equal(t, msg.EventID, event.EventID)
+		assert.Equal(t, msg.EventType, event.Type)
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{model.EventOrderCreated, model.EventOrderUpdated}, types)
+
+	var event model.OrderStoredEvent
+	require.NoError(t, json.Unmarshal(published[1].Payload, &event))
+	assert.Len(t, event.Order.Items, 1)
+
+	delivered, err = outbox.RelayOutbox(ctx, 10, func(context.Context, []*model.OutboxMessage) (int, error) {
+		t.Fatal("outbox should be empty")
+		return 0, nil
+	})
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+}
+
+func TestRelayOutbox_PartialDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := NewOutboxRepository(db)
+	createdAt := time.Now()
+	publishErr := errors.New("broker unavailable")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(tryLockOutboxQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(getOutboxBatchQuery)).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "order_uid", "event_type", "payload", "created_at"}).
+			AddRow(1, "e1", "order-1", model.EventOrderCreated, []byte(`{}`), createdAt).
+			AddRow(2, "e2", "order-2", model.EventOrderCreated, []byte(`{}`), createdAt).
+			AddRow(3, "e3", "order-1", model.EventOrderUpdated, []byte(`{}`), createdAt))
+	mock.ExpectExec(regexp.QuoteMeta(deleteOutboxQuery)).
+		WithArgs(pq.Array([]int64{1, 2})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	delivered, err := repo.RelayOutbox(context.Background(), 3, func(_ context.Context, msgs []*model.OutboxMessage) (int, error) {
+		require.Len(t, msgs, 3)
+		return 2, publishErr
+	})
+
+	require.ErrorIs(t, err, publishErr)
+	assert.Equal(t, 2, delivered)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelayOutbox_Locked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := NewOutboxRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(tryLockOutboxQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	mock.ExpectCommit()
+
+	delivered, err := repo.RelayOutbox(context.Background(), 10, func(context.Context, []*model.OutboxMessage) (int, error) {
+		t.Fatal("publish must not be called without the lock")
+		return 0, nil
+	})
+
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelayOutbox_DeleteFail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := NewOutboxRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(tryLockOutboxQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(getOutboxBatchQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "order_uid", "event_type", "payload", "created_at"}).
+			AddRow(1, "e1", "order-1", model.EventOrderCreated, []byte(`{}`), time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(deleteOutboxQuery)).WillReturnError(srvcerrors.ErrDatabase)
+	mock.ExpectRollback()
+
+	delivered, err := repo.RelayOutbox(context.Background(), 10, func(_ context.Context, msgs []*model.OutboxMessage) (int, error) {
+		return len(msgs), nil
+	})
+
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	assert.Zero(t, delivered)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
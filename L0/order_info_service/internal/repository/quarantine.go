@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/dto"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/lib/pq"
+)
+
+type QuarantineRepository struct {
+	db *sql.DB
+}
+
+const (
+	quarantinedOrderColumns = `id, order_uid, payload, stage, errors, topic, kafka_partition,
+		kafka_offset, kafka_key, kafka_timestamp, status, received_at, updated_at, resubmitted_at`
+
+	insertQuarantinedOrderQuery = `INSERT INTO quarantined_orders
+			(order_uid, payload, stage, errors, topic, kafka_partition, kafka_offset, kafka_key, kafka_timestamp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + quarantinedOrderColumns
+
+	getQuarantinedOrderQuery = `SELECT ` + quarantinedOrderColumns + `
+		FROM quarantined_orders
+		WHERE id = $1`
+
+	listQuarantinedOrdersQuery = `SELECT ` + quarantinedOrderColumns + `
+		FROM quarantined_orders
+		WHERE ($1 = '' OR status = $1) AND id > $2
+		ORDER BY id
+		LIMIT $3`
+
+	updateQuarantinedPayloadQuery = `UPDATE quarantined_orders
+		SET payload = $1, order_uid = $2, stage = $3, errors = $4, updated_at = now()
+		WHERE id = $5 AND status = $6
+		RETURNING ` + quarantinedOrderColumns
+
+	updateQuarantinedStatusQuery = `UPDATE quarantined_orders
+		SET status = $1, updated_at = now(),
+			resubmitted_at = CASE WHEN $1 = 'resubmitted' THEN now() ELSE resubmitted_at END
+		WHERE id = $2 AND status = $3
+		RETURNING ` + quarantinedOrderColumns
+)
+
+func NewQuarantineRepository(db *sql.DB) *QuarantineRepository {
+	return &QuarantineRepository{db: db}
+}
+
+func (r *QuarantineRepository) CreateQuarantinedOrder(ctx context.Context, q *model.QuarantinedOrder) (*model.QuarantinedOrder, error) {
+	row := r.db.QueryRowContext(ctx, insertQuarantinedOrderQuery,
+		q.OrderUID,
+		[]byte(q.Payload),
+		q.Stage,
+		pq.Array(q.Errors),
+		q.Topic,
+		q.Partition,
+		q.Offset,
+		q.Key,
+		q.KafkaTimestamp,
+	)
+	created, err := dto.ScanQuarantinedOrderFromRow(row)
+	if err != nil {
+		return nil, wrapDBError("failed to quarantine order", q.OrderUID, err)
+	}
+	return created, nil
+}
+
+func (r *QuarantineRepository) GetQuarantinedOrder(ctx context.Context, id int64) (*model.QuarantinedOrder, error) {
+	row := r.db.QueryRowContext(ctx, getQuarantinedOrderQuery, id)
+	q, err := dto.ScanQuarantinedOrderFromRow(row)
+	if err != nil {
+		return nil, wrapDBError("failed to get quarantined order", "", err)
+	}
+	return q, nil
+}
+
+func (r *QuarantineRepository) ListQuarantinedOrders(ctx context.Context, status string, lastID int64, limit int) ([]*model.QuarantinedOrder, error) {
+	orders, err := queryRows(ctx, r.db, dto.ScanQuarantinedOrderFromRow, listQuarantinedOrdersQuery, status, lastID, limit)
+	if err != nil {
+		return nil, wrapDBError("failed to list quarantined orders", status, err)
+	}
+	return orders, nil
+}
+
+// UpdateQuarantinedPayload replaces the payload only while the order is still
+// in q.Status. If the order is missing or has moved on it returns ErrNotFound,
+// so an edit cannot overwrite an order that is being resubmitted.
+func (r *QuarantineRepository) UpdateQuarantinedPayload(ctx context.Context, q *model.QuarantinedOrder) (*model.QuarantinedOrder, error) {
+	row := r.db.QueryRowContext(ctx, updateQuarantinedPayloadQuery,
+		[]byte(q.Payload),
+		q.OrderUID,
+		q.Stage,
+		pq.Array(q.Errors),
+		q.ID,
+		q.Status,
+	)
+	updated, err := dto.ScanQuarantinedOrderFromRow(row)
+	if err != nil {
+		return nil, wrapDBError("failed to update quarantined order payload", q.OrderUID, err)
+	}
+	return updated, nil
+}
+
+// SetQuarantinedStatus moves the order from one status to another in a single
+// statement. If the order is missing or no longer in the from status it
+// returns ErrNotFound, so concurrent callers cannot both win a transition.
+func (r *QuarantineRepository) SetQuarantinedStatus(ctx context.Context, id int64, from, to string) (*model.QuarantinedOrder, error) {
+	row := r.db.QueryRowContext(ctx, updateQuarantinedStatusQuery, to, id, from)
+	updated, err := dto.ScanQuarantinedOrderFromRow(row)
+	if err != nil {
+		return nil, wrapDBError("failed to update quarantined order status", to, err)
+	}
+	return updated, nil
+}
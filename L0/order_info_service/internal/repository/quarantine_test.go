@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuarantine_Lifecycle(t *testing.T) {
+	_, err := TestDB.Exec("TRUNCATE TABLE quarantined_orders RESTART IDENTITY")
+	require.NoError(t, err)
+
+	repo := NewQuarantineRepository(TestDB)
+	ctx := context.Background()
+
+	created, err := repo.CreateQuarantinedOrder(ctx, &model.QuarantinedOrder{
+		OrderUID:  "b563feb7b2b84b6test",
+		Payload:   []byte(`{"order_uid":"b563feb7b2b84b6test"}`),
+		Stage:     "validation",
+		Errors:    []string{"Locale: oneof"},
+		Topic:     "orders",
+		Partition: 0,
+		Offset:    42,
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, created.ID)
+	assert.Equal(t, model.QuarantineStatusPending, created.Status)
+	assert.Equal(t, []string{"Locale: oneof"}, created.Errors)
+
+	created.Payload = []byte(`{"order_uid":"fixed"}`)
+	created.OrderUID = "fixed"
+	created.Errors = nil
+	updated, err := repo.UpdateQuarantinedPayload(ctx, created)
+	require.NoError(t, err)
+	assert.Equal(t, "fixed", updated.OrderUID)
+	assert.Empty(t, updated.Errors)
+
+	claimed, err := repo.SetQuarantinedStatus(ctx, created.ID, model.QuarantineStatusPending, model.QuarantineStatusResubmitting)
+	require.NoError(t, err)
+	assert.Equal(t, model.QuarantineStatusResubmitting, claimed.Status)
+	assert.Nil(t, claimed.ResubmittedAt)
+
+	_, err = repo.SetQuarantinedStatus(ctx, created.ID, model.QuarantineStatusPending, model.QuarantineStatusResubmitting)
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+
+	_, err = repo.UpdateQuarantinedPayload(ctx, created)
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound, "payload must not change once the order is claimed")
+
+	resubmitted, err := repo.SetQuarantinedStatus(ctx, created.ID, model.QuarantineStatusResubmitting, model.QuarantineStatusResubmitted)
+	require.NoError(t, err)
+	assert.NotNil(t, resubmitted.ResubmittedAt)
+
+	pending, err := repo.ListQuarantinedOrders(ctx, model.QuarantineStatusPending, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	all, err := repo.ListQuarantinedOrders(ctx, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+
+	_, err = repo.GetQuarantinedOrder(ctx, 100)
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+}
+
+func TestQuarantine_BinaryPayload(t *testing.T) {
+	_, err := TestDB.Exec("TRUNCATE TABLE quarantined_orders RESTART IDENTITY")
+	require.NoError(t, err)
+
+	repo := NewQuarantineRepository(TestDB)
+	payload := []byte("{\"order_uid\":\x00\xff")
+
+	created, err := repo.CreateQuarantinedOrder(context.Background(), &model.QuarantinedOrder{
+		Payload: payload,
+		Stage:   "decode",
+		Errors:  []string{"unexpected end of JSON input"},
+		Topic:   "orders",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payload, []byte(created.Payload))
+}
+
+func TestQuarantine_GetQuarantinedOrder_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewQuarantineRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(getQuarantinedOrderQuery)).WillReturnError(srvcerrors.ErrDatabase)
+
+	q, err := repo.GetQuarantinedOrder(context.Background(), 1)
+
+	require.Error(t, err)
+	require.Nil(t, q)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
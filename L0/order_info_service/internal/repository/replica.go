@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultReplicaCheckInterval = 5 * time.Second
+	DefaultReplicaCheckTimeout  = 2 * time.Second
+)
+
+// replicaLagQuery reports replay lag in seconds. A replica that has replayed
+// everything it received is not lagging even if the primary has been idle.
+const replicaLagQuery = `SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`
+
+type ReplicaConfig struct {
+	MaxLag        time.Duration
+	CheckInterval time.Duration
+	CheckTimeout  time.Duration
+}
+
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+	mu      sync.Mutex
+	lag     time.Duration
+	lastErr error
+}
+
+// ReplicaSet keeps track of read replica health and hands out healthy
+// replicas round-robin.
+type ReplicaSet struct {
+	replicas []*replica
+	cfg      ReplicaConfig
+	logger   logger.Logger
+	next     atomic.Uint64
+	wg       sync.WaitGroup
+}
+
+// NewReplicaSet returns a set of named replica pools. Replicas are treated as
+// unhealthy until the first check passes, so reads stay on the primary until
+// Start is called.
+func NewReplicaSet(replicas map[string]*sql.DB, logger logger.Logger, cfg ReplicaConfig) *ReplicaSet {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultReplicaCheckInterval
+	}
+	if cfg.CheckTimeout <= 0 {
+		cfg.CheckTimeout = DefaultReplicaCheckTimeout
+	}
+
+	names := make([]string, 0, len(replicas))
+	for name := range replicas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := &ReplicaSet{cfg: cfg, logger: logger}
+	for _, name := range names {
+		set.replicas = append(set.replicas, &replica{name: name, db: replicas[name]})
+	}
+	return set
+}
+
+func (s *ReplicaSet) Start(ctx context.Context) {
+	s.check(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.cfg.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.check(ctx)
+			}
+		}
+	}()
+}
+
+func (s *ReplicaSet) Wait() {
+	s.wg.Wait()
+}
+
+func (s *ReplicaSet) pick() *replica {
+	if s == nil || len(s.replicas) == 0 {
+		return nil
+	}
+	start := s.next.Add(1)
+	for i := range s.replicas {
+		rep := s.replicas[(start+uint64(i))%uint64(len(s.replicas))]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+func (s *ReplicaSet) check(ctx context.Context) {
+	for _, rep := range s.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, s.cfg.CheckTimeout)
+		lag, err := replicaLag(checkCtx, rep.db)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && s.cfg.MaxLag > 0 && lag > s.cfg.MaxLag {
+			err = fmt.Errorf("replication lag %s exceeds %s", lag, s.cfg.MaxLag)
+		}
+		s.setState(s.logger, rep, lag, err)
+	}
+}
+
+// markDown is called from request paths, so the transition is logged with
+// the request fields of ctx.
+func (s *ReplicaSet) markDown(ctx context.Context, rep *replica, err error) {
+	rep.mu.Lock()
+	lag := rep.lag
+	rep.mu.Unlock()
+	s.setState(logger.FromContext(ctx, s.logger), rep, lag, err)
+}
+
+func (s *ReplicaSet) setState(log logger.Logger, rep *replica, lag time.Duration, err error) {
+	rep.mu.Lock()
+	rep.lag = lag
+	rep.lastErr = err
+	rep.mu.Unlock()
+
+	healthy := err == nil
+	if rep.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		log.Info("replica is healthy, routing reads to it",
+			zap.String("replica", rep.name),
+			zap.Duration("lag", lag))
+	} else {
+		log.Warn("replica is unhealthy, routing reads elsewhere",
+			zap.String("replica", rep.name),
+			zap.Duration("lag", lag),
+			zap.Error(err))
+	}
+}
+
+func replicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var seconds float64
+	if err := db.QueryRowContext(ctx, replicaLagQuery).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/require"
+)
+
+var hydratedOrderRowColumns = []string{
+	"order_uid", "track_number", "entry", "locale", "internal_signature",
+	"customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+	"order_uid", "name", "phone", "zip", "city", "address", "region", "email",
+	"transaction", "request_id", "currency", "provider", "amount", "payment_dt", "bank",
+	"delivery_cost", "goods_total", "custom_fee", "flags", "items",
+}
+
+func newReplicaMocks(t *testing.T) (primary, replica sqlmock.Sqlmock, repo *OrderRepository, set *ReplicaSet) {
+	t.Helper()
+	primaryDB, primary, err := sqlmock.New()
+	require.NoError(t, err)
+	replicaDB, replica, err := sqlmock.New()
+	require.NoError(t, err)
+
+	set = NewReplicaSet(map[string]*sql.DB{"replica-1": replicaDB}, &loggertest.MockLogger{}, ReplicaConfig{
+		MaxLag:        time.Second,
+		CheckInterval: time.Hour,
+	})
+	repo = NewOrderRepository(primaryDB, WithReplicas(set))
+	return primary, replica, repo, set
+}
+
+func expectLag(mock sqlmock.Sqlmock, seconds float64) {
+	mock.ExpectQuery(regexp.QuoteMeta(replicaLagQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(seconds))
+}
+
+func TestReplica_ServesReads(t *testing.T) {
+	primary, replica, repo, set := newReplicaMocks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		set.Wait()
+	}()
+
+	expectLag(replica, 0.2)
+	set.Start(ctx)
+
+	replica.ExpectQuery(regexp.QuoteMeta(getAllOrdersQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+	replica.ExpectBegin()
+	replica.ExpectQuery(regexp.QuoteMeta(getItemsByOrderUIDQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	replica.ExpectCommit()
+	primary.ExpectQuery(regexp.QuoteMeta(getOrdersByUIDsQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+
+	_, err := repo.GetAllOrders(ctx, 10)
+	require.NoError(t, err)
+	_, err = repo.GetItemsByOrderUID(ctx, "uid-1", 0, 10)
+	require.NoError(t, err)
+	_, err = repo.GetOrdersByUIDs(ctx, []string{"uid-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, replica.ExpectationsWereMet())
+	require.NoError(t, primary.ExpectationsWereMet())
+}
+
+func TestReplica_LaggingUsesPrimary(t *testing.T) {
+	primary, replica, repo, set := newReplicaMocks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		set.Wait()
+	}()
+
+	expectLag(replica, 5)
+	set.Start(ctx)
+
+	primary.ExpectQuery(regexp.QuoteMeta(getAllOrdersQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+
+	_, err := repo.GetAllOrders(ctx, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, replica.ExpectationsWereMet())
+	require.NoError(t, primary.ExpectationsWereMet())
+}
+
+func TestReplica_FailureFallsBackToPrimary(t *testing.T) {
+	primary, replica, repo, set := newReplicaMocks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		set.Wait()
+	}()
+
+	expectLag(replica, 0)
+	set.Start(ctx)
+
+	replica.ExpectQuery(regexp.QuoteMeta(getAllOrdersQuery)).WillReturnError(srvcerrors.ErrDatabase)
+	primary.ExpectQuery(regexp.QuoteMeta(getAllOrdersQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+	primary.ExpectQuery(regexp.QuoteMeta(getAllOrdersQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+
+	_, err := repo.GetAllOrders(ctx, 10)
+	require.NoError(t, err)
+	_, err = repo.GetAllOrders(ctx, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, replica.ExpectationsWereMet())
+	require.NoError(t, primary.ExpectationsWereMet())
+}
+
+func TestReplica_MissingRowRetriedOnPrimary(t *testing.T) {
+	primary, replica, repo, set := newReplicaMocks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		set.Wait()
+	}()
+
+	expectLag(replica, 0)
+	set.Start(ctx)
+
+	replica.ExpectQuery(regexp.QuoteMeta(getOrderByIDQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+	primary.ExpectQuery(regexp.QuoteMeta(getOrderByIDQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+	replica.ExpectQuery(regexp.QuoteMeta(getAllOrdersQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+
+	_, err := repo.GetOrderByUID(ctx, "uid-1")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+	_, err = repo.GetAllOrders(ctx, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, replica.ExpectationsWereMet())
+	require.NoError(t, primary.ExpectationsWereMet())
+}
+
+func TestReplica_NotStartedUsesPrimary(t *testing.T) {
+	primary, replica, repo, _ := newReplicaMocks(t)
+
+	primary.ExpectQuery(regexp.QuoteMeta(getAllOrdersQuery)).
+		WillReturnRows(sqlmock.NewRows(hydratedOrderRowColumns))
+
+	_, err := repo.GetAllOrders(context.Background(), 10)
+	require.NoError(t, err)
+
+	require.NoError(t, replica.ExpectationsWereMet())
+	require.NoError(t, primary.ExpectationsWereMet())
+}
@@ -0,0 +1,369 @@
+// Package repositorytest holds the conformance suite every
+// repository.RepositoryProvider implementation is expected to pass.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewRepository returns an empty repository for a single subtest.
+type NewRepository func(t *testing.T) repository.RepositoryProvider
+
+var baseDate = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// RunConformance runs the shared suite against repositories built by newRepo.
+// LastUpsertAt is checked when the repository also implements
+// repository.SnapshotRepositoryProvider.
+func RunConformance(t *testing.T, newRepo NewRepository) {
+	tests := []struct {
+		name string
+		fn   func(*testing.T, repository.RepositoryProvider)
+	}{
+		{"UpsertCreatesOrder", testUpsertCreatesOrder},
+		{"GetOrderByUIDNotFound", testGetOrderByUIDNotFound},
+		{"UpsertReplacesItemsAndFlags", testUpsertReplacesItemsAndFlags},
+		{"ItemKeysetPagination", testItemKeysetPagination},
+		{"ItemsByOrderUIDs", testItemsByOrderUIDs},
+		{"RecentOrders", testRecentOrders},
+		{"OrdersByUIDs", testOrdersByUIDs},
+		{"ReturnsCopies", testReturnsCopies},
+		{"CanceledContext", testCanceledContext},
+		{"LastUpsertAt", testLastUpsertAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func testUpsertCreatesOrder(t *testing.T, repo repository.RepositoryProvider) {
+	ctx := context.Background()
+	order := newOrder("orderA", baseDate, 3)
+
+	created, isNew, err := repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	if diff := cmp.Diff(order, created, orderOpts...); diff != "" {
+		t.Errorf("created order mismatch (-want +got):\n%s", diff)
+	}
+	assertIncreasingIDs(t, created.Items, 0)
+
+	got, err := repo.GetOrderByUID(ctx, order.OrderUID)
+	require.NoError(t, err)
+	assert.Nil(t, got.Items, "single order lookup does not hydrate items")
+	if diff := cmp.Diff(order, got, append(orderOpts, cmpopts.IgnoreFields(model.Order{}, "Items"))...); diff != "" {
+		t.Errorf("stored order mismatch (-want +got):\n%s", diff)
+	}
+
+	exists, err := repo.OrderExists(ctx, order.OrderUID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.OrderExists(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func testGetOrderByUIDNotFound(t *testing.T, repo repository.RepositoryProvider) {
+	got, err := repo.GetOrderByUID(context.Background(), "missing")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+	assert.Nil(t, got)
+}
+
+func testUpsertReplacesItemsAndFlags(t *testing.T, repo repository.RepositoryProvider) {
+	ctx := context.Background()
+	order := newOrder("orderA", baseDate, 2)
+	order.Flags = []*model.OrderFlag{{Rule: "payment_amount", Message: "amount mismatch"}}
+
+	created, isNew, err := repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	require.Len(t, created.Flags, 1)
+	assert.False(t, created.Flags[0].CreatedAt.IsZero())
+
+	updated := newOrder("orderA", baseDate, 1)
+	updated.TrackNumber = "track-updated"
+	updated.Items[0].Name = "Replacement"
+
+	stored, isNew, err := repo.UpsertOrder(ctx, updated)
+	require.NoError(t, err)
+	assert.False(t, isNew, "second upsert of an order is an update")
+	assert.Equal(t, "track-updated", stored.TrackNumber)
+	assert.Nil(t, stored.Flags)
+	require.Len(t, stored.Items, 1)
+	assertIncreasingIDs(t, stored.Items, created.Items[len(created.Items)-1].ID)
+
+	items, err := repo.GetItemsByOrderUID(ctx, "orderA", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, stored.Items[0].ID, items[0].ID)
+	assert.Equal(t, "Replacement", items[0].Name)
+
+	got, err := repo.GetOrderByUID(ctx, "orderA")
+	require.NoError(t, err)
+	assert.Equal(t, "track-updated", got.TrackNumber)
+	assert.Nil(t, got.Flags)
+}
+
+func testItemKeysetPagination(t *testing.T, repo repository.RepositoryProvider) {
+	ctx := context.Background()
+	created, _, err := repo.UpsertOrder(ctx, newOrder("orderA", baseDate, 5))
+	require.NoError(t, err)
+
+	var pages [][]int
+	lastID := 0
+	for {
+		items, err := repo.GetItemsByOrderUID(ctx, "orderA", lastID, 2)
+		require.NoError(t, err)
+		if len(items) == 0 {
+			break
+		}
+		pages = append(pages, itemIDs(items))
+		lastID = items[len(items)-1].ID
+	}
+
+	ids := itemIDs(created.Items)
+	assert.Equal(t, [][]int{ids[0:2], ids[2:4], ids[4:5]}, pages)
+
+	items, err := repo.GetItemsByOrderUID(ctx, "missing", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func testItemsByOrderUIDs(t *testing.T, repo repository.RepositoryProvider) {
+	ctx := context.Background()
+	a, _, err := repo.UpsertOrder(ctx, newOrder("orderA", baseDate, 3))
+	require.NoError(t, err)
+	b, _, err := repo.UpsertOrder(ctx, newOrder("orderB", baseDate, 3))
+	require.NoError(t, err)
+
+	uids := []string{"orderA", "orderB", "missing"}
+
+	items, err := repo.GetItemsByOrderUIDs(ctx, uids, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]int{
+		"orderA": itemIDs(a.Items[:2]),
+		"orderB": itemIDs(b.Items[:2]),
+	}, itemIDsByOrder(items))
+
+	items, err = repo.GetItemsByOrderUIDs(ctx, uids, a.Items[1].ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]int{
+		"orderA": itemIDs(a.Items[2:]),
+		"orderB": itemIDs(b.Items[:2]),
+	}, itemIDsByOrder(items))
+}
+
+func testRecentOrders(t *testing.T, repo repository.RepositoryProvider) {
+	ctx := context.Background()
+	for _, order := range []*model.Order{
+		newOrder("orderA", baseDate, 1),
+		newOrder("orderB", baseDate.Add(time.Hour), 2),
+		newOrder("orderC", baseDate.Add(time.Hour), 0),
+		newOrder("orderD", baseDate.Add(2*time.Hour), 1),
+	} {
+		_, _, err := repo.UpsertOrder(ctx, order)
+		require.NoError(t, err)
+	}
+
+	all, err := repo.GetAllOrders(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orderD", "orderC", "orderB", "orderA"}, orderUIDs(all))
+	for _, order := range all {
+		assert.NotNil(t, order.Items, "order %s", order.OrderUID)
+	}
+	assert.Len(t, all[2].Items, 2)
+
+	first, err := repo.GetAllOrders(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orderD", "orderC"}, orderUIDs(first))
+
+	last := first[len(first)-1]
+	rest, err := repo.GetRecentOrders(ctx, &model.OrderCursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orderB", "orderA"}, orderUIDs(rest))
+
+	none, err := repo.GetRecentOrders(ctx, &model.OrderCursor{DateCreated: baseDate, OrderUID: "orderA"}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func testOrdersByUIDs(t *testing.T, repo repository.RepositoryProvider) {
+	ctx := context.Background()
+	order := newOrder("orderA", baseDate, 2)
+	_, _, err := repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+
+	orders, err := repo.GetOrdersByUIDs(ctx, []string{"orderA", "missing"})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	if diff := cmp.Diff(order, orders["orderA"], orderOpts...); diff != "" {
+		t.Errorf("hydrated order mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func testReturnsCopies(t *testing.T, repo repository.RepositoryProvider) {
+	ctx := context.Background()
+	order := newOrder("orderA", baseDate, 1)
+	created, _, err := repo.UpsertOrder(ctx, order)
+	require.NoError(t, err)
+
+	order.TrackNumber = "mutated"
+	order.Items[0].Name = "mutated"
+	created.Items[0].Name = "mutated"
+
+	orders, err := repo.GetOrdersByUIDs(ctx, []string{"orderA"})
+	require.NoError(t, err)
+	require.Contains(t, orders, "orderA")
+	assert.Equal(t, "track-orderA", orders["orderA"].TrackNumber)
+	assert.Equal(t, "Item orderA-0", orders["orderA"].Items[0].Name)
+
+	orders["orderA"].Items[0].Name = "mutated"
+	items, err := repo.GetItemsByOrderUID(ctx, "orderA", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Item orderA-0", items[0].Name)
+}
+
+func testCanceledContext(t *testing.T, repo repository.RepositoryProvider) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := repo.UpsertOrder(ctx, newOrder("orderA", baseDate, 1))
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+
+	exists, err := repo.OrderExists(context.Background(), "orderA")
+	require.NoError(t, err)
+	assert.False(t, exists, "canceled upsert must not store the order")
+
+	_, err = repo.GetOrderByUID(ctx, "orderA")
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+}
+
+func testLastUpsertAt(t *testing.T, repo repository.RepositoryProvider) {
+	snapshots, ok := repo.(repository.SnapshotRepositoryProvider)
+	if !ok {
+		t.Skip("repository does not implement SnapshotRepositoryProvider")
+	}
+	ctx := context.Background()
+
+	empty, err := snapshots.LastUpsertAt(ctx)
+	require.NoError(t, err)
+	assert.True(t, empty.Equal(time.Unix(0, 0)), "got %v", empty)
+
+	_, _, err = repo.UpsertOrder(ctx, newOrder("orderA", baseDate, 1))
+	require.NoError(t, err)
+	first, err := snapshots.LastUpsertAt(ctx)
+	require.NoError(t, err)
+	assert.True(t, first.After(empty))
+
+	_, _, err = repo.UpsertOrder(ctx, newOrder("orderA", baseDate, 1))
+	require.NoError(t, err)
+	second, err := snapshots.LastUpsertAt(ctx)
+	require.NoError(t, err)
+	assert.False(t, second.Before(first))
+}
+
+var orderOpts = []cmp.Option{
+	cmpopts.IgnoreFields(model.Item{}, "ID"),
+	cmpopts.IgnoreFields(model.Order{}, "Flags"),
+	cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) }),
+}
+
+func newOrder(uid string, dateCreated time.Time, itemCount int) *model.Order {
+	order := &model.Order{
+		OrderUID:          uid,
+		TrackNumber:       "track-" + uid,
+		Entry:             "WBIL",
+		Locale:            "en",
+		InternalSignature: "",
+		CustomerID:        "customer-" + uid,
+		DeliveryService:   "meest",
+		Shardkey:          "9",
+		SmID:              99,
+		DateCreated:       dateCreated,
+		OofShard:          "1",
+		Delivery: model.Delivery{
+			OrderUID: uid,
+			Name:     "Test Testov",
+			Phone:    "+9720000000",
+			Zip:      "2639809",
+			City:     "Kiryat Mozkin",
+			Address:  "Ploshad Mira 15",
+			Region:   "Kraiot",
+			Email:    "test@gmail.com",
+		},
+		Payment: model.Payment{
+			Transaction:  uid,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDT:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: make([]*model.Item, 0, itemCount),
+	}
+	for i := range itemCount {
+		order.Items = append(order.Items, &model.Item{
+			OrderUID:    uid,
+			ChrtID:      9934930 + i,
+			TrackNumber: "track-" + uid,
+			Price:       453,
+			RID:         fmt.Sprintf("rid-%s-%d", uid, i),
+			Name:        fmt.Sprintf("Item %s-%d", uid, i),
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		})
+	}
+	return order
+}
+
+func assertIncreasingIDs(t *testing.T, items []*model.Item, after int) {
+	t.Helper()
+	for _, item := range items {
+		assert.Greater(t, item.ID, after)
+		after = item.ID
+	}
+}
+
+func itemIDs(items []*model.Item) []int {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func itemIDsByOrder(items map[string][]*model.Item) map[string][]int {
+	ids := make(map[string][]int, len(items))
+	for uid, orderItems := range items {
+		ids[uid] = itemIDs(orderItems)
+	}
+	return ids
+}
+
+func orderUIDs(orders []*model.Order) []string {
+	uids := make([]string, len(orders))
+	for i, order := range orders {
+		uids[i] = order.OrderUID
+	}
+	return uids
+}
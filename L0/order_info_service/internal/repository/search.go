@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/dto"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+)
+
+const searchItemsQuery = `WITH query AS (
+		SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS q
+	)
+	SELECT ` + itemColumns + `,
+		ts_rank_cd(search_vector, query.q) AS rank
+	FROM items, query
+	WHERE search_vector @@ query.q
+	ORDER BY rank DESC, id
+	LIMIT $2 OFFSET $3`
+
+func (r *OrderRepository) SearchItems(ctx context.Context, query string, limit, offset int) ([]*model.ItemSearchHit, error) {
+	hits, err := queryRows(ctx, traced(r.db), dto.ScanItemSearchHitFromRow, searchItemsQuery, query, limit, offset)
+	if err != nil {
+		return nil, wrapDBError("failed to search items by query", query, err)
+	}
+	return hits, nil
+}
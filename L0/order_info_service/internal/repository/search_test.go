@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchItems_Success(t *testing.T) {
+	clearTables(t)
+	repo := NewOrderRepository(TestDB)
+	ctx := context.Background()
+
+	_, _, err := repo.UpsertOrder(ctx, generateTestOrder())
+	require.NoError(t, err)
+
+	hits, err := repo.SearchItems(ctx, "BrandA", 10, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, hits)
+	assert.Equal(t, "BrandA", hits[0].Item.Brand)
+	assert.Equal(t, "test-order-uid", hits[0].OrderUID)
+	assert.Greater(t, hits[0].Rank, 0.0)
+
+	hits, err = repo.SearchItems(ctx, "BrandA", 10, 1)
+	require.NoError(t, err)
+	for _, hit := range hits {
+		assert.NotEqual(t, "BrandA", hit.Item.Brand)
+	}
+
+	hits, err = repo.SearchItems(ctx, "nonexistent", 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func TestSearchItems_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewOrderRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(searchItemsQuery)).WillReturnError(srvcerrors.ErrDatabase)
+
+	hits, err := repo.SearchItems(context.Background(), "brand", 10, 0)
+
+	require.Error(t, err)
+	require.Nil(t, hits)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+
+// tracedQuerier starts a span for every query it runs. For QueryContext the
+// span covers execution only, not reading the rows.
+type tracedQuerier struct {
+	q Querier
+}
+
+func traced(q Querier) Querier {
+	return tracedQuerier{q: q}
+}
+
+func (t tracedQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	res, err := t.q.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (t tracedQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (t tracedQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	row := t.q.QueryRowContext(ctx, query, args...)
+	tracing.End(span, row.Err())
+	return row
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation, table := describeQuery(query)
+	name := operation
+	if table != "" {
+		name += " " + table
+	}
+	return tracing.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+			semconv.DBQueryText(query),
+		))
+}
+
+// describeQuery returns the statement keyword and the first table it reads or
+// writes, which is enough to tell the repository queries apart.
+func describeQuery(query string) (operation, table string) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	operation = strings.ToUpper(fields[0])
+	for i := 0; i < len(fields)-1; i++ {
+		switch strings.ToUpper(fields[i]) {
+		case "FROM", "INTO", "UPDATE":
+			if table = strings.Trim(fields[i+1], "(),;"); table != "" {
+				return operation, table
+			}
+		}
+	}
+	return operation, ""
+}
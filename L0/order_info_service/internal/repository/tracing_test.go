@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestDescribeQuery(t *testing.T) {
+	tests := []struct {
+		query     string
+		operation string
+		table     string
+	}{
+		{getOrderByIDQuery, "SELECT", "orders"},
+		{getItemsByOrderUIDsQuery, "SELECT", "items"},
+		{insertIntoItemsQuery, "INSERT", "items"},
+		{updatePaymentQuery, "UPDATE", "payments"},
+		{deleteOrderFlagsQuery, "DELETE", "order_flags"},
+		{notifyOrderChangedQuery, "SELECT", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		operation, table := describeQuery(tt.query)
+		require.Equal(t, tt.operation, operation)
+		require.Equal(t, tt.table, table)
+	}
+}
+
+func TestTracing_SpanPerQuery(t *testing.T) {
+	provider, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterMemory, SampleRatio: 1})
+	require.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewOrderRepository(db)
+
+	ctx, parent := tracing.Tracer("test").Start(context.Background(), "parent")
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(getItemsByOrderUIDQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(orderExistsQuery)).WillReturnError(srvcerrors.ErrDatabase)
+
+	_, err = repo.GetItemsByOrderUID(ctx, "uid-1", 0, 10)
+	require.NoError(t, err)
+	_, err = repo.OrderExists(ctx, "uid-1")
+	require.Error(t, err)
+	parent.End()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	spans := provider.Spans()
+	require.Len(t, spans, 3)
+
+	items, exists := spans[0], spans[1]
+	require.Equal(t, "SELECT items", items.Name)
+	require.Equal(t, parent.SpanContext().SpanID(), items.Parent.SpanID())
+	require.Contains(t, items.Attributes, attribute.String("db.system", "postgresql"))
+	require.Contains(t, items.Attributes, attribute.String("db.query.text", getItemsByOrderUIDQuery))
+	require.Equal(t, codes.Unset, items.Status.Code)
+
+	require.Equal(t, "SELECT orders", exists.Name)
+	require.Equal(t, codes.Error, exists.Status.Code)
+}
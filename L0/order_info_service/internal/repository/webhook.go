@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/dto"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+const (
+	webhookSubscriptionColumns = `id, url, secret, events, active, created_at`
+
+	webhookDeliveryColumns = `id, subscription_id, event_id, event_type, order_uid,
+		attempt, status_code, success, error, duration_ms, created_at`
+
+	insertWebhookSubscriptionQuery = `INSERT INTO webhook_subscriptions
+			(url, secret, events, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookSubscriptionColumns
+
+	updateWebhookSubscriptionQuery = `UPDATE webhook_subscriptions
+		SET url = $1, events = $2, active = $3
+		WHERE id = $4
+		RETURNING ` + webhookSubscriptionColumns
+
+	deleteWebhookSubscriptionQuery = `DELETE FROM webhook_subscriptions
+		WHERE id = $1`
+
+	getWebhookSubscriptionQuery = `SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE id = $1`
+
+	listWebhookSubscriptionsQuery = `SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		ORDER BY id`
+
+	insertWebhookDeliveryQuery = `INSERT INTO webhook_deliveries
+			(subscription_id, event_id, event_type, order_uid,
+			attempt, status_code, success, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + webhookDeliveryColumns
+
+	listWebhookDeliveriesQuery = `SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY id DESC
+		LIMIT $2`
+
+	enqueueWebhookEventQuery = `INSERT INTO webhook_pending_deliveries
+			(subscription_id, event_id, event_type, order_uid, payload)
+		SELECT id, $1::text, $2::text, $3::text, $4::jsonb
+		FROM webhook_subscriptions
+		WHERE active AND $2::text = ANY(events)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	// Claimed rows are leased rather than held in a transaction, so a sender
+	// that dies mid-delivery only delays the next attempt until the lease ends.
+	claimWebhookPendingDeliveriesQuery = `WITH claimed AS (
+			UPDATE webhook_pending_deliveries
+			SET locked_until = now() + $2::bigint * interval '1 millisecond'
+			WHERE id IN (
+				SELECT id
+				FROM webhook_pending_deliveries
+				WHERE next_attempt_at <= now()
+					AND (locked_until IS NULL OR locked_until <= now())
+				ORDER BY next_attempt_at, id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, subscription_id, event_id, event_type, order_uid, payload, attempts
+		)
+		SELECT c.id, c.subscription_id, s.url, s.secret, s.active,
+			c.event_id, c.event_type, c.order_uid, c.payload, c.attempts
+		FROM claimed c
+		JOIN webhook_subscriptions s ON s.id = c.subscription_id
+		ORDER BY c.id`
+
+	rescheduleWebhookPendingDeliveryQuery = `UPDATE webhook_pending_deliveries
+		SET attempts = $2,
+			next_attempt_at = now() + $3::bigint * interval '1 millisecond',
+			locked_until = NULL
+		WHERE id = $1`
+
+	deleteWebhookPendingDeliveryQuery = `DELETE FROM webhook_pending_deliveries
+		WHERE id = $1`
+)
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx, insertWebhookSubscriptionQuery,
+		sub.URL,
+		sub.Secret,
+		pq.Array(sub.Events),
+		sub.Active,
+	)
+	created, err := dto.ScanWebhookSubscriptionFromRow(row)
+	if err != nil {
+		return nil, wrapDBError("failed to create webhook subscription", "", err)
+	}
+	return created, nil
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx, updateWebhookSubscriptionQuery,
+		sub.URL,
+		pq.Array(sub.Events),
+		sub.Active,
+		sub.ID,
+	)
+	updated, err := dto.ScanWebhookSubscriptionFromRow(row)
+	if err != nil {
+		return nil, wrapDBError("failed to update webhook subscription", "", err)
+	}
+	return updated, nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, deleteWebhookSubscriptionQuery, id)
+	if err != nil {
+		return wrapDBError("failed to delete webhook subscription", "", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return wrapDBError("failed to delete webhook subscription", "", err)
+	}
+	if affected == 0 {
+		return wrapDBError("failed to delete webhook subscription", "", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx, getWebhookSubscriptionQuery, id)
+	sub, err := dto.ScanWebhookSubscriptionFromRow(row)
+	if err != nil {
+		return nil, wrapDBError("failed to get webhook subscription", "", err)
+	}
+	return sub, nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	subs, err := r.querySubscriptions(ctx, listWebhookSubscriptionsQuery)
+	if err != nil {
+		return nil, wrapDBError("failed to list webhook subscriptions", "", err)
+	}
+	return subs, nil
+}
+
+func (r *WebhookRepository) RecordDelivery(ctx context.Context, d *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	row := r.db.QueryRowContext(ctx, insertWebhookDeliveryQuery,
+		d.SubscriptionID,
+		d.EventID,
+		d.EventType,
+		d.OrderUID,
+		d.Attempt,
+		d.StatusCode,
+		d.Success,
+		d.Error,
+		d.DurationMs,
+	)
+	recorded, err := dto.ScanWebhookDeliveryFromRow(row)
+	if err != nil {
+		return nil, wrapDBError("failed to record webhook delivery", d.EventID, err)
+	}
+	return recorded, nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]*model.WebhookDelivery, error) {
+	deliveries := make([]*model.WebhookDelivery, 0)
+
+	rows, err := r.db.QueryContext(ctx, listWebhookDeliveriesQuery, subscriptionID, limit)
+	if err != nil {
+		return nil, wrapDBError("failed to list webhook deliveries", "", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := dto.ScanWebhookDeliveryFromRow(rows)
+		if err != nil {
+			return nil, wrapDBError("failed to scan webhook delivery", "", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrapDBError("failed to list webhook deliveries", "", err)
+	}
+
+	return deliveries, nil
+}
+
+// EnqueueEvent stores a pending delivery of the event for every active
+// subscription to its type and returns how many were stored. Enqueueing the
+// same event twice is a no-op.
+func (r *WebhookRepository) EnqueueEvent(ctx context.Context, event *model.WebhookPendingDelivery) (int, error) {
+	res, err := r.db.ExecContext(ctx, enqueueWebhookEventQuery,
+		event.EventID,
+		event.EventType,
+		event.OrderUID,
+		string(event.Payload),
+	)
+	if err != nil {
+		return 0, wrapDBError("failed to enqueue webhook event", event.EventID, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, wrapDBError("failed to enqueue webhook event", event.EventID, err)
+	}
+	return int(affected), nil
+}
+
+// ClaimPendingDeliveries leases up to limit due deliveries for lease. Other
+// instances skip them until the lease ends or they are rescheduled.
+func (r *WebhookRepository) ClaimPendingDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookPendingDelivery, error) {
+	pending, err := queryRows(ctx, r.db, dto.ScanWebhookPendingDeliveryFromRow,
+		claimWebhookPendingDeliveriesQuery, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, wrapDBError("failed to claim webhook deliveries", "", err)
+	}
+	return pending, nil
+}
+
+func (r *WebhookRepository) ReschedulePendingDelivery(ctx context.Context, id int64, attempts int, delay time.Duration) error {
+	if _, err := r.db.ExecContext(ctx, rescheduleWebhookPendingDeliveryQuery, id, attempts, delay.Milliseconds()); err != nil {
+		return wrapDBError("failed to reschedule webhook delivery", "", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) DeletePendingDelivery(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, deleteWebhookPendingDeliveryQuery, id); err != nil {
+		return wrapDBError("failed to delete webhook delivery", "", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]*model.WebhookSubscription, error) {
+	subs := make([]*model.WebhookSubscription, 0)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sub, err := dto.ScanWebhookSubscriptionFromRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
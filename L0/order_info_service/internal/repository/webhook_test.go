@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clearWebhookTables(t *testing.T) {
+	_, err := TestDB.Exec("TRUNCATE TABLE webhook_pending_deliveries, webhook_deliveries, webhook_subscriptions RESTART IDENTITY CASCADE")
+	require.NoError(t, err)
+}
+
+func TestWebhookSubscription_CRUD(t *testing.T) {
+	clearWebhookTables(t)
+	repo := NewWebhookRepository(TestDB)
+	ctx := context.Background()
+
+	created, err := repo.CreateSubscription(ctx, &model.WebhookSubscription{
+		URL:    "http://partner.local/hook",
+		Secret: "secret",
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+	assert.Equal(t, []string{model.EventOrderCreated}, created.Events)
+
+	created.Events = []string{model.EventOrderCreated, model.EventOrderUpdated}
+	updated, err := repo.UpdateSubscription(ctx, created)
+	require.NoError(t, err)
+	assert.Len(t, updated.Events, 2)
+
+	subs, err := repo.ListSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+
+	require.NoError(t, repo.DeleteSubscription(ctx, created.ID))
+
+	_, err = repo.GetSubscription(ctx, created.ID)
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+	require.ErrorIs(t, repo.DeleteSubscription(ctx, created.ID), srvcerrors.ErrNotFound)
+}
+
+func TestWebhookDeliveries_RecordAndList(t *testing.T) {
+	clearWebhookTables(t)
+	repo := NewWebhookRepository(TestDB)
+	ctx := context.Background()
+
+	sub, err := repo.CreateSubscription(ctx, &model.WebhookSubscription{
+		URL:    "http://partner.local/hook",
+		Secret: "secret",
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	})
+	require.NoError(t, err)
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		_, err := repo.RecordDelivery(ctx, &model.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        "event-1",
+			EventType:      model.EventOrderCreated,
+			OrderUID:       "test-order-uid",
+			Attempt:        attempt,
+			StatusCode:     500,
+		})
+		require.NoError(t, err)
+	}
+
+	deliveries, err := repo.ListDeliveries(ctx, sub.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, 2, deliveries[0].Attempt)
+}
+
+func TestWebhookPendingDeliveries_EnqueueClaimAndFinish(t *testing.T) {
+	clearWebhookTables(t)
+	repo := NewWebhookRepository(TestDB)
+	ctx := context.Background()
+
+	sub, err := repo.CreateSubscription(ctx, &model.WebhookSubscription{
+		URL:    "http://partner.local/hook",
+		Secret: "secret",
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	})
+	require.NoError(t, err)
+	_, err = repo.CreateSubscription(ctx, &model.WebhookSubscription{
+		URL:    "http://other.local/hook",
+		Secret: "secret",
+		Events: []string{model.EventOrderUpdated},
+		Active: true,
+	})
+	require.NoError(t, err)
+
+	event := &model.WebhookPendingDelivery{
+		EventID:   "event-1",
+		EventType: model.EventOrderCreated,
+		OrderUID:  "test-order-uid",
+		Payload:   []byte(`{"id":"event-1"}`),
+	}
+	queued, err := repo.EnqueueEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, 1, queued)
+
+	queued, err = repo.EnqueueEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Zero(t, queued)
+
+	claimed, err := repo.ClaimPendingDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, sub.ID, claimed[0].SubscriptionID)
+	assert.Equal(t, sub.URL, claimed[0].URL)
+	assert.Equal(t, "secret", claimed[0].Secret)
+	assert.Zero(t, claimed[0].Attempts)
+	assert.JSONEq(t, `{"id":"event-1"}`, string(claimed[0].Payload))
+
+	again, err := repo.ClaimPendingDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again, "leased deliveries must not be claimed twice")
+
+	require.NoError(t, repo.ReschedulePendingDelivery(ctx, claimed[0].ID, 1, 0))
+	retried, err := repo.ClaimPendingDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, retried, 1)
+	assert.Equal(t, 1, retried[0].Attempts)
+
+	require.NoError(t, repo.DeletePendingDelivery(ctx, retried[0].ID))
+	require.NoError(t, repo.ReschedulePendingDelivery(ctx, retried[0].ID, 2, 0))
+	left, err := repo.ClaimPendingDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, left)
+}
+
+func TestEnqueueWebhookEvent_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	repo := NewWebhookRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(enqueueWebhookEventQuery)).WillReturnError(srvcerrors.ErrDatabase)
+
+	queued, err := repo.EnqueueEvent(context.Background(), &model.WebhookPendingDelivery{
+		EventID:   "event-1",
+		EventType: model.EventOrderCreated,
+		OrderUID:  "test-order-uid",
+		Payload:   []byte(`{}`),
+	})
+
+	require.Error(t, err)
+	require.Zero(t, queued)
+	require.ErrorIs(t, err, srvcerrors.ErrDatabase)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
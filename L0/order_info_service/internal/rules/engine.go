@@ -0,0 +1,146 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+)
+
+type Action string
+
+const (
+	ActionOff    Action = "off"
+	ActionWarn   Action = "warn"
+	ActionFlag   Action = "flag"
+	ActionReject Action = "reject"
+)
+
+const (
+	RulePaymentAmount  = "payment_amount"
+	RuleItemTotalPrice = "item_total_price"
+)
+
+type Rule struct {
+	Name  string
+	Check func(*model.Order) []string
+}
+
+type Violation struct {
+	Rule    string
+	Action  Action
+	Message string
+}
+
+type Result struct {
+	Violations []Violation
+}
+
+func (r *Result) Rejected() bool {
+	for _, v := range r.Violations {
+		if v.Action == ActionReject {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Result) ByAction(action Action) []Violation {
+	filtered := make([]Violation, 0)
+	for _, v := range r.Violations {
+		if v.Action == action {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+func (r *Result) Flags() []*model.OrderFlag {
+	var flags []*model.OrderFlag
+	for _, v := range r.ByAction(ActionFlag) {
+		flags = append(flags, &model.OrderFlag{Rule: v.Rule, Message: v.Message})
+	}
+	return flags
+}
+
+type Engine struct {
+	rules   []Rule
+	actions map[string]Action
+}
+
+func NewEngine(actions map[string]Action, rules ...Rule) *Engine {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &Engine{
+		rules:   rules,
+		actions: actions,
+	}
+}
+
+func (e *Engine) Evaluate(order *model.Order) *Result {
+	result := &Result{}
+	for _, rule := range e.rules {
+		action := e.action(rule.Name)
+		if action == ActionOff {
+			continue
+		}
+		for _, message := range rule.Check(order) {
+			result.Violations = append(result.Violations, Violation{
+				Rule:    rule.Name,
+				Action:  action,
+				Message: message,
+			})
+		}
+	}
+	return result
+}
+
+func (e *Engine) action(rule string) Action {
+	if action, ok := e.actions[rule]; ok {
+		return action
+	}
+	return ActionFlag
+}
+
+func ParseAction(value string) (Action, error) {
+	switch action := Action(strings.ToLower(strings.TrimSpace(value))); action {
+	case ActionOff, ActionWarn, ActionFlag, ActionReject:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown rule action %q, expected off, warn, flag or reject", value)
+	}
+}
+
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: RulePaymentAmount, Check: checkPaymentAmount},
+		{Name: RuleItemTotalPrice, Check: checkItemTotalPrice},
+	}
+}
+
+func checkPaymentAmount(order *model.Order) []string {
+	p := order.Payment
+	expected := p.GoodsTotal + p.DeliveryCost + p.CustomFee
+	if p.Amount == expected {
+		return nil
+	}
+	return []string{fmt.Sprintf("payment amount %d does not match goods_total + delivery_cost + custom_fee = %d",
+		p.Amount, expected)}
+}
+
+func checkItemTotalPrice(order *model.Order) []string {
+	var messages []string
+	for _, item := range order.Items {
+		if item == nil {
+			continue
+		}
+		expected := item.Price * (100 - item.Sale) / 100
+		if diff := item.TotalPrice - expected; diff >= -1 && diff <= 1 {
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("item nm_id %d (chrt_id %d): total_price %d does not match price %d with sale %d%% = %d",
+			item.NmID, item.ChrtID, item.TotalPrice, item.Price, item.Sale, expected))
+	}
+	return messages
+}
@@ -0,0 +1,93 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/rules"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validOrder() *model.Order {
+	return &model.Order{
+		OrderUID: "b563feb7b2b84b6test",
+		Payment: model.Payment{
+			Amount:       1817,
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+			CustomFee:    0,
+		},
+		Items: []*model.Item{
+			{ChrtID: 9934930, NmID: 2389212, Price: 453, Sale: 30, TotalPrice: 317},
+		},
+	}
+}
+
+func TestEngine_ValidOrder(t *testing.T) {
+	engine := rules.NewEngine(nil)
+
+	result := engine.Evaluate(validOrder())
+
+	assert.Empty(t, result.Violations)
+	assert.False(t, result.Rejected())
+	assert.Nil(t, result.Flags())
+}
+
+func TestEngine_DefaultActionIsFlag(t *testing.T) {
+	order := validOrder()
+	order.Payment.Amount = 1000
+	order.Items[0].TotalPrice = 453
+
+	result := rules.NewEngine(nil).Evaluate(order)
+
+	require.Len(t, result.Violations, 2)
+	flags := result.Flags()
+	require.Len(t, flags, 2)
+	assert.Equal(t, rules.RulePaymentAmount, flags[0].Rule)
+	assert.Equal(t, rules.RuleItemTotalPrice, flags[1].Rule)
+	assert.False(t, result.Rejected())
+}
+
+func TestEngine_ConfiguredActions(t *testing.T) {
+	order := validOrder()
+	order.Payment.Amount = 1000
+	order.Items[0].TotalPrice = 453
+
+	engine := rules.NewEngine(map[string]rules.Action{
+		rules.RulePaymentAmount:  rules.ActionReject,
+		rules.RuleItemTotalPrice: rules.ActionWarn,
+	})
+
+	result := engine.Evaluate(order)
+
+	assert.True(t, result.Rejected())
+	assert.Len(t, result.ByAction(rules.ActionReject), 1)
+	assert.Len(t, result.ByAction(rules.ActionWarn), 1)
+	assert.Nil(t, result.Flags())
+}
+
+func TestEngine_OffSkipsRule(t *testing.T) {
+	order := validOrder()
+	order.Payment.Amount = 1000
+
+	engine := rules.NewEngine(map[string]rules.Action{rules.RulePaymentAmount: rules.ActionOff})
+
+	assert.Empty(t, engine.Evaluate(order).Violations)
+}
+
+func TestEngine_ItemTotalPriceRounding(t *testing.T) {
+	order := validOrder()
+	order.Items = append(order.Items, &model.Item{Price: 999, Sale: 33, TotalPrice: 670})
+
+	assert.Empty(t, rules.NewEngine(nil).Evaluate(order).Violations)
+}
+
+func TestParseAction(t *testing.T) {
+	action, err := rules.ParseAction(" Reject ")
+	require.NoError(t, err)
+	assert.Equal(t, rules.ActionReject, action)
+
+	_, err = rules.ParseAction("drop")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultLimit   = 20
+	MaxLimit       = 100
+	MaxOffset      = 10000
+	MaxQueryLength = 256
+)
+
+type SearchProvider interface {
+	SearchItems(context.Context, string, int, int) (*model.ItemSearchResult, error)
+}
+
+type Service struct {
+	repo   repository.ItemSearchRepositoryProvider
+	logger logger.Logger
+}
+
+func NewService(repo repository.ItemSearchRepositoryProvider, logger logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *Service) SearchItems(ctx context.Context, query string, limit, offset int) (*model.ItemSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > MaxQueryLength {
+		return nil, fmt.Errorf("%w: query must be between 1 and %d characters", srvcerrors.ErrInvalidInput, MaxQueryLength)
+	}
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 1 || limit > MaxLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", srvcerrors.ErrInvalidInput, MaxLimit)
+	}
+	if offset < 0 || offset > MaxOffset {
+		return nil, fmt.Errorf("%w: offset must be between 0 and %d", srvcerrors.ErrInvalidInput, MaxOffset)
+	}
+
+	s.logger.Info("search: request to search items",
+		zap.String("query", query),
+		zap.Int("limit", limit),
+		zap.Int("offset", offset))
+
+	hits, err := s.repo.SearchItems(ctx, query, limit+1, offset)
+	if err != nil {
+		s.logger.Error("search: failed to search items", zap.String("query", query), zap.Error(err))
+		return nil, err
+	}
+
+	result := &model.ItemSearchResult{
+		Query:  query,
+		Hits:   hits,
+		Offset: offset,
+	}
+	if len(hits) > limit {
+		result.Hits = hits[:limit]
+		result.HasMore = true
+	}
+	result.NextOffset = offset + len(result.Hits)
+
+	return result, nil
+}
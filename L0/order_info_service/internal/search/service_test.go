@@ -0,0 +1,89 @@
+package search_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/search"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) SearchItems(ctx context.Context, query string, limit, offset int) ([]*model.ItemSearchHit, error) {
+	args := m.Called(ctx, query, limit, offset)
+	hits, _ := args.Get(0).([]*model.ItemSearchHit)
+	return hits, args.Error(1)
+}
+
+func makeHits(n int) []*model.ItemSearchHit {
+	hits := make([]*model.ItemSearchHit, 0, n)
+	for i := 0; i < n; i++ {
+		hits = append(hits, &model.ItemSearchHit{OrderUID: "order-1", Item: &model.Item{ID: i + 1}})
+	}
+	return hits
+}
+
+func TestService_SearchItems_HasMore(t *testing.T) {
+	repo := new(MockRepository)
+	repo.On("SearchItems", mock.Anything, "mascara", 3, 4).Return(makeHits(3), nil)
+
+	svc := search.NewService(repo, &loggertest.MockLogger{})
+
+	result, err := svc.SearchItems(context.Background(), "  mascara ", 2, 4)
+
+	require.NoError(t, err)
+	assert.Equal(t, "mascara", result.Query)
+	assert.Len(t, result.Hits, 2)
+	assert.True(t, result.HasMore)
+	assert.Equal(t, 6, result.NextOffset)
+	repo.AssertExpectations(t)
+}
+
+func TestService_SearchItems_LastPage(t *testing.T) {
+	repo := new(MockRepository)
+	repo.On("SearchItems", mock.Anything, "тушь", search.DefaultLimit+1, 0).Return(makeHits(1), nil)
+
+	svc := search.NewService(repo, &loggertest.MockLogger{})
+
+	result, err := svc.SearchItems(context.Background(), "тушь", 0, 0)
+
+	require.NoError(t, err)
+	assert.Len(t, result.Hits, 1)
+	assert.False(t, result.HasMore)
+	assert.Equal(t, 1, result.NextOffset)
+}
+
+func TestService_SearchItems_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		limit  int
+		offset int
+	}{
+		{"empty query", "   ", 0, 0},
+		{"query too long", strings.Repeat("a", search.MaxQueryLength+1), 0, 0},
+		{"limit too large", "brand", search.MaxLimit + 1, 0},
+		{"offset too large", "brand", 0, search.MaxOffset + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepository)
+			svc := search.NewService(repo, &loggertest.MockLogger{})
+
+			_, err := svc.SearchItems(context.Background(), tt.query, tt.limit, tt.offset)
+
+			require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+			repo.AssertNotCalled(t, "SearchItems")
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultMaxSize   = 64 << 20
+	DefaultBatchSize = 500
+)
+
+type Store interface {
+	cache.Cache
+	cache.Snapshotter
+}
+
+type Config struct {
+	Path      string
+	MaxSize   int64
+	BatchSize int
+}
+
+type Service struct {
+	repo   repository.SnapshotRepositoryProvider
+	cache  Store
+	logger logger.Logger
+	cfg    Config
+	wg     sync.WaitGroup
+}
+
+func NewService(repo repository.SnapshotRepositoryProvider, cache Store, logger logger.Logger, cfg Config) *Service {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	return &Service{
+		repo:   repo,
+		cache:  cache,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+func (s *Service) Enabled() bool {
+	return s.cfg.Path != ""
+}
+
+// Restore loads the snapshot into the cache and, when the database has seen
+// upserts after the snapshot was taken, refreshes the restored orders in the
+// background. It reports whether any orders were restored.
+func (s *Service) Restore(ctx context.Context) bool {
+	if !s.Enabled() {
+		return false
+	}
+
+	info, err := s.cache.LoadSnapshot(s.cfg.Path, s.cfg.MaxSize)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		s.logger.Info("snapshot: no cache snapshot found", zap.String("path", s.cfg.Path))
+		return false
+	case err != nil:
+		s.logger.Warn("snapshot: failed to load cache snapshot", zap.String("path", s.cfg.Path), zap.Error(err))
+		return false
+	}
+
+	s.logger.Info("snapshot: cache restored",
+		zap.String("path", s.cfg.Path),
+		zap.Int("orders", info.Orders),
+		zap.Int64("size", info.Size),
+		zap.Time("saved_at", info.SavedAt),
+		zap.Time("watermark", info.Watermark))
+
+	if info.Orders == 0 {
+		return false
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.refresh(ctx, info)
+	}()
+	return true
+}
+
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+// Save writes the current cache content to the snapshot file. The watermark is
+// taken from the database before the cache is dumped so that a later restore
+// refreshes anything upserted after it.
+func (s *Service) Save(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	watermark, err := s.repo.LastUpsertAt(ctx)
+	if err != nil {
+		s.logger.Warn("snapshot: failed to get last upsert time, snapshot will be refreshed on restore", zap.Error(err))
+		watermark = time.Time{}
+	}
+
+	info, err := s.cache.SaveSnapshot(s.cfg.Path, s.cfg.MaxSize, watermark)
+	if err != nil {
+		s.logger.Error("snapshot: failed to save cache snapshot", zap.String("path", s.cfg.Path), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("snapshot: cache saved",
+		zap.String("path", s.cfg.Path),
+		zap.Int("orders", info.Orders),
+		zap.Int64("size", info.Size))
+	return nil
+}
+
+func (s *Service) refresh(ctx context.Context, info cache.SnapshotInfo) {
+	lastUpsert, err := s.repo.LastUpsertAt(ctx)
+	if err != nil {
+		s.logger.Error("snapshot: failed to get last upsert time", zap.Error(err))
+		return
+	}
+	if !info.Watermark.IsZero() && !lastUpsert.After(info.Watermark) {
+		s.logger.Debug("snapshot: restored cache is up to date", zap.Time("last_upsert", lastUpsert))
+		return
+	}
+
+	refreshed, evicted := 0, 0
+	for start := 0; start < len(info.OrderUIDs); start += s.cfg.BatchSize {
+		batch := info.OrderUIDs[start:min(start+s.cfg.BatchSize, len(info.OrderUIDs))]
+		orders, err := s.repo.GetOrdersByUIDs(ctx, batch)
+		if err != nil {
+			s.logger.Error("snapshot: failed to refresh restored orders",
+				zap.Int("refreshed", refreshed),
+				zap.Int("evicted", evicted),
+				zap.Error(err))
+			return
+		}
+
+		for _, orderUID := range batch {
+			if order, ok := orders[orderUID]; ok {
+				s.cache.SetOrder(order)
+				refreshed++
+			} else if s.cache.Delete(orderUID) {
+				evicted++
+			}
+		}
+	}
+
+	s.logger.Info("snapshot: restored orders refreshed",
+		zap.Int("refreshed", refreshed),
+		zap.Int("evicted", evicted),
+		zap.Time("last_upsert", lastUpsert))
+}
@@ -0,0 +1,150 @@
+package snapshot_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/snapshot"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	mu         sync.Mutex
+	orders     map[string]*model.Order
+	lastUpsert time.Time
+	err        error
+	requested  [][]string
+}
+
+func (f *fakeRepository) LastUpsertAt(context.Context) (time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastUpsert, f.err
+}
+
+func (f *fakeRepository) GetOrdersByUIDs(_ context.Context, orderUIDs []string) (map[string]*model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requested = append(f.requested, orderUIDs)
+	orders := make(map[string]*model.Order)
+	for _, orderUID := range orderUIDs {
+		if order, ok := f.orders[orderUID]; ok {
+			orders[orderUID] = order
+		}
+	}
+	return orders, nil
+}
+
+func newOrder(uid, track string) *model.Order {
+	return &model.Order{OrderUID: uid, TrackNumber: track}
+}
+
+func saveSnapshot(t *testing.T, path string, watermark time.Time, orders ...*model.Order) {
+	t.Helper()
+	source := cache.NewLocalCache()
+	for _, order := range orders {
+		source.SetOrder(order)
+	}
+	_, err := source.SaveSnapshot(path, 0, watermark)
+	require.NoError(t, err)
+}
+
+func TestRestore_RefreshesStaleOrders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	watermark := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	saveSnapshot(t, path, watermark,
+		newOrder("order-1", "stale"),
+		newOrder("order-2", "stale"),
+		newOrder("order-3", "stale"))
+
+	repo := &fakeRepository{
+		lastUpsert: watermark.Add(time.Minute),
+		orders: map[string]*model.Order{
+			"order-1": newOrder("order-1", "fresh"),
+			"order-2": newOrder("order-2", "fresh"),
+		},
+	}
+	c := cache.NewLocalCache()
+	svc := snapshot.NewService(repo, c, &loggertest.MockLogger{}, snapshot.Config{Path: path, BatchSize: 2})
+
+	require.True(t, svc.Restore(context.Background()))
+	svc.Wait()
+
+	for _, uid := range []string{"order-1", "order-2"} {
+		got, err := c.GetOrderByUID(uid)
+		require.NoError(t, err)
+		require.Equal(t, "fresh", got.TrackNumber)
+	}
+	_, err := c.GetOrderByUID("order-3")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+	require.Len(t, repo.requested, 2)
+}
+
+func TestRestore_SkipsRefreshWhenUpToDate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	watermark := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	saveSnapshot(t, path, watermark, newOrder("order-1", "cached"))
+
+	repo := &fakeRepository{lastUpsert: watermark}
+	c := cache.NewLocalCache()
+	svc := snapshot.NewService(repo, c, &loggertest.MockLogger{}, snapshot.Config{Path: path})
+
+	require.True(t, svc.Restore(context.Background()))
+	svc.Wait()
+
+	got, err := c.GetOrderByUID("order-1")
+	require.NoError(t, err)
+	require.Equal(t, "cached", got.TrackNumber)
+	require.Empty(t, repo.requested)
+}
+
+func TestRestore_MissingOrCorruptSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	svc := snapshot.NewService(&fakeRepository{}, cache.NewLocalCache(), &loggertest.MockLogger{},
+		snapshot.Config{Path: filepath.Join(dir, "missing")})
+	require.False(t, svc.Restore(context.Background()))
+
+	path := filepath.Join(dir, "cache.snapshot")
+	saveSnapshot(t, path, time.Time{}, newOrder("order-1", "cached"))
+	svc = snapshot.NewService(&fakeRepository{}, cache.NewLocalCache(), &loggertest.MockLogger{},
+		snapshot.Config{Path: path, MaxSize: 8})
+	require.False(t, svc.Restore(context.Background()))
+}
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	lastUpsert := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	c := cache.NewLocalCache()
+	c.SetOrder(newOrder("order-1", "cached"))
+	svc := snapshot.NewService(&fakeRepository{lastUpsert: lastUpsert}, c, &loggertest.MockLogger{}, snapshot.Config{Path: path})
+	require.NoError(t, svc.Save(context.Background()))
+
+	info, err := cache.NewLocalCache().LoadSnapshot(path, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, info.Orders)
+	require.True(t, lastUpsert.Equal(info.Watermark))
+
+	t.Run("database unavailable", func(t *testing.T) {
+		svc := snapshot.NewService(&fakeRepository{err: errors.New("down")}, c, &loggertest.MockLogger{}, snapshot.Config{Path: path})
+		require.NoError(t, svc.Save(context.Background()))
+
+		info, err := cache.NewLocalCache().LoadSnapshot(path, 0)
+		require.NoError(t, err)
+		require.True(t, info.Watermark.IsZero())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		svc := snapshot.NewService(&fakeRepository{}, c, &loggertest.MockLogger{}, snapshot.Config{})
+		require.False(t, svc.Enabled())
+		require.NoError(t, svc.Save(context.Background()))
+	})
+}
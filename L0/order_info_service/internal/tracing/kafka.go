@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// KafkaHeaderCarrier adapts Kafka message headers to the propagation API.
+type KafkaHeaderCarrier struct {
+	Headers *[]kafka.Header
+}
+
+var _ propagation.TextMapCarrier = KafkaHeaderCarrier{}
+
+func (c KafkaHeaderCarrier) Get(key string) string {
+	for _, h := range *c.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c KafkaHeaderCarrier) Set(key, value string) {
+	for i, h := range *c.Headers {
+		if h.Key == key {
+			(*c.Headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.Headers = append(*c.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c KafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.Headers))
+	for _, h := range *c.Headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
+
+// InjectKafkaHeaders adds the trace context of ctx to the message headers.
+func InjectKafkaHeaders(ctx context.Context, msg *kafka.Message) {
+	otel.GetTextMapPropagator().Inject(ctx, KafkaHeaderCarrier{Headers: &msg.Headers})
+}
+
+// ExtractKafkaHeaders returns ctx carrying the trace context of the message
+// producer, if the message has one.
+func ExtractKafkaHeaders(ctx context.Context, msg *kafka.Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, KafkaHeaderCarrier{Headers: &msg.Headers})
+}
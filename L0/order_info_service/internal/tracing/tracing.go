@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+	ExporterMemory = "memory"
+)
+
+const DefaultServiceName = "order-info-service"
+
+type Config struct {
+	Exporter    string
+	Endpoint    string
+	ServiceName string
+	// SampleRatio is the fraction of new traces that are recorded: 1 records
+	// all of them and 0 none.
+	SampleRatio float64
+}
+
+// Provider owns the tracer provider installed by Setup.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+	memory   *tracetest.InMemoryExporter
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. With ExporterNone no spans are recorded, but incoming trace
+// context is still passed on to Kafka headers and outgoing calls.
+func Setup(ctx context.Context, cfg Config) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	p := &Provider{}
+	var opt sdktrace.TracerProviderOption
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return p, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		opt = sdktrace.WithBatcher(exporter)
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		opt = sdktrace.WithBatcher(exporter)
+	case ExporterMemory:
+		p.memory = tracetest.NewInMemoryExporter()
+		opt = sdktrace.WithSyncer(p.memory)
+	default:
+		return nil, fmt.Errorf("%w: unknown trace exporter %q", srvcerrors.ErrInvalidInput, cfg.Exporter)
+	}
+
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = DefaultServiceName
+	}
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+
+	p.provider = sdktrace.NewTracerProvider(opt,
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(p.provider)
+	return p, nil
+}
+
+// Spans returns the spans recorded so far by the in-memory exporter.
+func (p *Provider) Spans() tracetest.SpanStubs {
+	if p.memory == nil {
+		return nil
+	}
+	return p.memory.GetSpans()
+}
+
+func (p *Provider) Reset() {
+	if p.memory != nil {
+		p.memory.Reset()
+	}
+}
+
+// Shutdown flushes buffered spans to the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.provider == nil {
+		return nil
+	}
+	return p.provider.Shutdown(ctx)
+}
+
+// Tracer looks the tracer up on every call rather than caching it, so spans go
+// to whichever provider Setup installed last.
+func Tracer(name string) trace.Tracer {
+	return otel.GetTracerProvider().Tracer(name)
+}
+
+// End records err on the span and ends it. Missing orders are an expected
+// outcome and are not marked as errors.
+func End(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, srvcerrors.ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
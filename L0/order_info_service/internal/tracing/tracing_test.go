@@ -0,0 +1,95 @@
+package tracing_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/tracing"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func setupMemory(t *testing.T) *tracing.Provider {
+	t.Helper()
+	provider, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterMemory, SampleRatio: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, provider.Shutdown(context.Background()))
+	})
+	return provider
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "jaeger"})
+	require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+}
+
+func TestSetup_ZeroSampleRatio(t *testing.T) {
+	provider, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterMemory})
+	require.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	_, span := tracing.Tracer("test").Start(context.Background(), "unsampled")
+	require.False(t, span.SpanContext().IsSampled())
+	span.End()
+
+	require.Empty(t, provider.Spans())
+}
+
+func TestEnd(t *testing.T) {
+	provider := setupMemory(t)
+	tracer := tracing.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "ok")
+	tracing.End(span, nil)
+	_, span = tracer.Start(context.Background(), "not found")
+	tracing.End(span, fmt.Errorf("%w: order", srvcerrors.ErrNotFound))
+	_, span = tracer.Start(context.Background(), "failed")
+	tracing.End(span, srvcerrors.ErrDatabase)
+
+	spans := provider.Spans()
+	require.Len(t, spans, 3)
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+	require.Equal(t, codes.Unset, spans[1].Status.Code)
+	require.Equal(t, codes.Error, spans[2].Status.Code)
+	require.Len(t, spans[2].Events, 1)
+}
+
+func TestKafkaHeaders_RoundTrip(t *testing.T) {
+	provider := setupMemory(t)
+
+	ctx, producer := tracing.Tracer("test").Start(context.Background(), "produce")
+	msg := &kafka.Message{Headers: []kafka.Header{{Key: "content-type", Value: []byte("application/json")}}}
+	tracing.InjectKafkaHeaders(ctx, msg)
+	tracing.InjectKafkaHeaders(ctx, msg)
+	producer.End()
+
+	require.Len(t, msg.Headers, 2)
+	carrier := tracing.KafkaHeaderCarrier{Headers: &msg.Headers}
+	require.ElementsMatch(t, []string{"content-type", "traceparent"}, carrier.Keys())
+
+	consumeCtx := tracing.ExtractKafkaHeaders(context.Background(), msg)
+	_, consumer := tracing.Tracer("test").Start(consumeCtx, "consume")
+	consumer.End()
+
+	spans := provider.Spans()
+	require.Len(t, spans, 2)
+	require.Equal(t, spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID())
+	require.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+	require.True(t, spans[1].Parent.IsRemote())
+}
+
+func TestExtractKafkaHeaders_NoContext(t *testing.T) {
+	provider := setupMemory(t)
+
+	ctx := tracing.ExtractKafkaHeaders(context.Background(), &kafka.Message{})
+	_, span := tracing.Tracer("test").Start(ctx, "consume")
+	span.End()
+
+	spans := provider.Spans()
+	require.Len(t, spans, 1)
+	require.False(t, spans[0].Parent.IsValid())
+}
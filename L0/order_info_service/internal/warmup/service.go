@@ -0,0 +1,223 @@
+package warmup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"go.uber.org/zap"
+)
+
+const (
+	MaxLimit         = 100000
+	DefaultBatchSize = 100
+)
+
+const (
+	StateIdle      = "idle"
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+	StateCancelled = "cancelled"
+)
+
+type Progress struct {
+	State      string     `json:"state"`
+	Limit      int        `json:"limit"`
+	Loaded     int        `json:"loaded"`
+	Cached     int        `json:"cached"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type WarmupProvider interface {
+	Trigger(int) (Progress, error)
+	Progress() Progress
+}
+
+type Service struct {
+	repo      repository.RepositoryProvider
+	cache     cache.Cache
+	logger    logger.Logger
+	batchSize int
+	requests  chan int
+	mu        sync.Mutex
+	progress  Progress
+	stopped   bool
+	wg        sync.WaitGroup
+}
+
+func NewService(repo repository.RepositoryProvider, cache cache.Cache, logger logger.Logger, batchSize int) *Service {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Service{
+		repo:      repo,
+		cache:     cache,
+		logger:    logger,
+		batchSize: batchSize,
+		requests:  make(chan int, 1),
+		progress:  Progress{State: StateIdle},
+	}
+}
+
+func (s *Service) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				s.stop()
+				return
+			case limit := <-s.requests:
+				s.run(ctx, limit)
+			}
+		}
+	}()
+}
+
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Service) Trigger(limit int) (Progress, error) {
+	if limit <= 0 || limit > MaxLimit {
+		return Progress{}, fmt.Errorf("%w: warmup limit must be between 1 and %d", srvcerrors.ErrInvalidInput, MaxLimit)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return s.progress, fmt.Errorf("%w: cache warmup is stopped", srvcerrors.ErrUnavailable)
+	}
+	if s.progress.State == StateRunning {
+		return s.progress, fmt.Errorf("%w: cache warmup is already running", srvcerrors.ErrConflict)
+	}
+
+	// The send never blocks while holding the mutex: a job is only queued
+	// when none is running, so the single slot is free.
+	select {
+	case s.requests <- limit:
+	default:
+		return s.progress, fmt.Errorf("%w: cache warmup is already queued", srvcerrors.ErrConflict)
+	}
+
+	now := time.Now().UTC()
+	s.progress = Progress{State: StateRunning, Limit: limit, StartedAt: &now}
+	return s.progress, nil
+}
+
+func (s *Service) Progress() Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.progress
+}
+
+func (s *Service) run(ctx context.Context, limit int) {
+	s.logger.Info("warmup: started", zap.Int("limit", limit), zap.Int("batch_size", s.batchSize))
+
+	var before *model.OrderCursor
+	for {
+		progress := s.Progress()
+		if progress.Loaded >= limit {
+			break
+		}
+
+		batch := min(s.batchSize, limit-progress.Loaded)
+		orders, err := s.repo.GetRecentOrders(ctx, before, batch)
+		if err != nil {
+			s.finish(ctx, err)
+			return
+		}
+
+		cached := 0
+		for _, order := range orders {
+			if s.cache.SetOrderIfAbsent(order) {
+				cached++
+			}
+		}
+
+		s.mu.Lock()
+		s.progress.Loaded += len(orders)
+		s.progress.Cached += cached
+		progress = s.progress
+		s.mu.Unlock()
+
+		s.logger.Debug("warmup: batch loaded",
+			zap.Int("loaded", progress.Loaded),
+			zap.Int("cached", progress.Cached),
+			zap.Int("limit", limit))
+
+		if len(orders) < batch {
+			break
+		}
+		last := orders[len(orders)-1]
+		before = &model.OrderCursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}
+	}
+
+	s.finish(ctx, nil)
+}
+
+func (s *Service) finish(ctx context.Context, err error) {
+	s.mu.Lock()
+	now := time.Now().UTC()
+	s.progress.FinishedAt = &now
+	switch {
+	case err == nil:
+		s.progress.State = StateCompleted
+	case ctx.Err() != nil:
+		s.progress.State = StateCancelled
+		s.progress.Error = ctx.Err().Error()
+	default:
+		s.progress.State = StateFailed
+		s.progress.Error = err.Error()
+	}
+	progress := s.progress
+	s.mu.Unlock()
+
+	switch progress.State {
+	case StateCompleted:
+		s.logger.Info("warmup: completed",
+			zap.Int("loaded", progress.Loaded),
+			zap.Int("cached", progress.Cached),
+			zap.Duration("duration", progress.FinishedAt.Sub(*progress.StartedAt)))
+	case StateCancelled:
+		s.logger.Warn("warmup: cancelled",
+			zap.Int("loaded", progress.Loaded),
+			zap.Int("limit", progress.Limit))
+	default:
+		s.logger.Error("warmup: failed",
+			zap.Int("loaded", progress.Loaded),
+			zap.Int("limit", progress.Limit),
+			zap.Error(err))
+	}
+}
+
+// stop rejects further triggers and cancels a job that was queued but never
+// started. Both happen under the mutex, so Trigger cannot queue a job after
+// the run loop has exited.
+func (s *Service) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	select {
+	case <-s.requests:
+	default:
+		return
+	}
+
+	now := time.Now().UTC()
+	s.progress.State = StateCancelled
+	s.progress.FinishedAt = &now
+	s.progress.Error = context.Canceled.Error()
+}
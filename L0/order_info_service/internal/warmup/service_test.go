@@ -0,0 +1,218 @@
+package warmup_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/cache"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/warmup"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	repository.RepositoryProvider
+	mu      sync.Mutex
+	orders  []*model.Order
+	calls   []int
+	err     error
+	block   chan struct{}
+	started chan struct{}
+}
+
+func newFakeRepository(n, items int) *fakeRepository {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeRepository{}
+	for i := n - 1; i >= 0; i-- {
+		order := &model.Order{OrderUID: fmt.Sprintf("order-%03d", i), DateCreated: base.Add(time.Duration(i) * time.Minute)}
+		for j := 0; j < items; j++ {
+			order.Items = append(order.Items, &model.Item{ID: j + 1, OrderUID: order.OrderUID})
+		}
+		repo.orders = append(repo.orders, order)
+	}
+	return repo
+}
+
+func (f *fakeRepository) GetRecentOrders(ctx context.Context, before *model.OrderCursor, limit int) ([]*model.Order, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, limit)
+	f.mu.Unlock()
+
+	if f.block != nil {
+		close(f.started)
+		select {
+		case <-f.block:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %v", srvcerrors.ErrDatabase, ctx.Err())
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	start := 0
+	if before != nil {
+		for start < len(f.orders) && !f.orders[start].DateCreated.Before(before.DateCreated) {
+			start++
+		}
+	}
+	end := min(start+limit, len(f.orders))
+	return f.orders[start:end], nil
+}
+
+func waitFor(t *testing.T, svc *warmup.Service, state string) warmup.Progress {
+	var progress warmup.Progress
+	require.Eventually(t, func() bool {
+		progress = svc.Progress()
+		return progress.State == state
+	}, time.Second, 5*time.Millisecond)
+	return progress
+}
+
+func TestService_StreamsRecentOrdersInBatches(t *testing.T) {
+	repo := newFakeRepository(25, 15)
+	c := cache.NewLocalCache()
+	svc := warmup.NewService(repo, c, &loggertest.MockLogger{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.Start(ctx)
+
+	_, err := svc.Trigger(22)
+	require.NoError(t, err)
+
+	progress := waitFor(t, svc, warmup.StateCompleted)
+	assert.Equal(t, 22, progress.Loaded)
+	assert.Equal(t, 22, progress.Cached)
+	assert.NotNil(t, progress.FinishedAt)
+	assert.Equal(t, []int{10, 10, 2}, repo.calls)
+
+	_, err = c.GetOrderByUID("order-024")
+	require.NoError(t, err)
+	_, err = c.GetOrderByUID("order-002")
+	require.ErrorIs(t, err, srvcerrors.ErrNotFound)
+
+	items, err := c.GetItemsByOrderUID("order-010", 0, 100)
+	require.NoError(t, err)
+	assert.Len(t, items, 15)
+}
+
+func TestService_StopsWhenOrdersRunOut(t *testing.T) {
+	repo := newFakeRepository(5, 1)
+	svc := warmup.NewService(repo, cache.NewLocalCache(), &loggertest.MockLogger{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.Start(ctx)
+
+	_, err := svc.Trigger(100)
+	require.NoError(t, err)
+
+	progress := waitFor(t, svc, warmup.StateCompleted)
+	assert.Equal(t, 5, progress.Loaded)
+	assert.Equal(t, []int{10}, repo.calls)
+}
+
+func TestService_KeepsNewerCachedOrders(t *testing.T) {
+	repo := newFakeRepository(3, 1)
+	c := cache.NewLocalCache()
+	c.SetOrder(&model.Order{OrderUID: "order-002", TrackNumber: "fresh"})
+	svc := warmup.NewService(repo, c, &loggertest.MockLogger{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.Start(ctx)
+
+	_, err := svc.Trigger(3)
+	require.NoError(t, err)
+
+	progress := waitFor(t, svc, warmup.StateCompleted)
+	assert.Equal(t, 3, progress.Loaded)
+	assert.Equal(t, 2, progress.Cached)
+
+	order, err := c.GetOrderByUID("order-002")
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", order.TrackNumber)
+}
+
+func TestService_CancelledByShutdown(t *testing.T) {
+	repo := newFakeRepository(5, 1)
+	repo.block = make(chan struct{})
+	repo.started = make(chan struct{})
+	svc := warmup.NewService(repo, cache.NewLocalCache(), &loggertest.MockLogger{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.Start(ctx)
+
+	_, err := svc.Trigger(5)
+	require.NoError(t, err)
+
+	_, err = svc.Trigger(5)
+	require.ErrorIs(t, err, srvcerrors.ErrConflict)
+
+	<-repo.started
+	cancel()
+	svc.Wait()
+
+	progress := svc.Progress()
+	assert.Equal(t, warmup.StateCancelled, progress.State)
+	assert.Equal(t, 0, progress.Loaded)
+}
+
+func TestService_TriggerAfterShutdown(t *testing.T) {
+	svc := warmup.NewService(newFakeRepository(5, 1), cache.NewLocalCache(), &loggertest.MockLogger{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.Start(ctx)
+	cancel()
+	svc.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 2 {
+			_, err := svc.Trigger(5)
+			assert.ErrorIs(t, err, srvcerrors.ErrUnavailable)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Trigger blocked after shutdown")
+	}
+	assert.Equal(t, warmup.StateIdle, svc.Progress().State)
+}
+
+func TestService_Failed(t *testing.T) {
+	repo := newFakeRepository(5, 1)
+	repo.err = srvcerrors.ErrDatabase
+	svc := warmup.NewService(repo, cache.NewLocalCache(), &loggertest.MockLogger{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.Start(ctx)
+
+	_, err := svc.Trigger(5)
+	require.NoError(t, err)
+
+	progress := waitFor(t, svc, warmup.StateFailed)
+	assert.Contains(t, progress.Error, srvcerrors.ErrDatabase.Error())
+}
+
+func TestService_InvalidLimit(t *testing.T) {
+	svc := warmup.NewService(newFakeRepository(0, 0), cache.NewLocalCache(), &loggertest.MockLogger{}, 10)
+
+	for _, limit := range []int{0, -1, warmup.MaxLimit + 1} {
+		_, err := svc.Trigger(limit)
+		require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+	}
+	assert.Equal(t, warmup.StateIdle, svc.Progress().State)
+}
@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+	recordTimeout   = 5 * time.Second
+)
+
+// Sign returns the value of the signature header: an HMAC-SHA256 over
+// "<timestamp>.<body>" keyed by the subscription secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// deliver makes one attempt to send a pending delivery, records it in the
+// delivery log and either removes the delivery or schedules the next attempt.
+func (s *Service) deliver(ctx context.Context, p *model.WebhookPendingDelivery) {
+	if !p.Active {
+		s.finish(ctx, p)
+		return
+	}
+
+	attempt := p.Attempts + 1
+	start := time.Now()
+	statusCode, err := s.send(ctx, p)
+	if ctx.Err() != nil {
+		// Cut off by shutdown: the lease runs out and the attempt is repeated.
+		s.logger.Warn("webhook: delivery aborted by shutdown",
+			zap.Int64("subscription_id", p.SubscriptionID),
+			zap.String("event_id", p.EventID),
+			zap.Int("attempt", attempt))
+		return
+	}
+	success := err == nil && statusCode >= 200 && statusCode < 300
+
+	delivery := &model.WebhookDelivery{
+		SubscriptionID: p.SubscriptionID,
+		EventID:        p.EventID,
+		EventType:      p.EventType,
+		OrderUID:       p.OrderUID,
+		Attempt:        attempt,
+		StatusCode:     statusCode,
+		Success:        success,
+		DurationMs:     time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	} else if !success {
+		delivery.Error = fmt.Sprintf("unexpected status code %d", statusCode)
+	}
+	s.record(ctx, delivery)
+
+	switch {
+	case success:
+		s.logger.Debug("webhook: event delivered",
+			zap.Int64("subscription_id", p.SubscriptionID),
+			zap.String("event_id", p.EventID),
+			zap.Int("attempt", attempt))
+	case !isRetryable(statusCode, err):
+		s.logger.Warn("webhook: delivery rejected by receiver",
+			zap.Int64("subscription_id", p.SubscriptionID),
+			zap.String("event_id", p.EventID),
+			zap.Int("status", statusCode))
+	case attempt >= s.config.MaxAttempts:
+		s.logger.Error("webhook: all delivery attempts exhausted",
+			zap.Int64("subscription_id", p.SubscriptionID),
+			zap.String("event_id", p.EventID))
+	default:
+		s.logger.Warn("webhook: delivery attempt failed",
+			zap.Int64("subscription_id", p.SubscriptionID),
+			zap.String("event_id", p.EventID),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", s.config.MaxAttempts),
+			zap.Int("status", statusCode),
+			zap.Error(err))
+		s.reschedule(ctx, p, attempt)
+		return
+	}
+	s.finish(ctx, p)
+}
+
+func (s *Service) send(ctx context.Context, p *model.WebhookPendingDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(p.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, p.EventType)
+	req.Header.Set(HeaderEventID, p.EventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(p.Secret, timestamp, p.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+func (s *Service) record(ctx context.Context, delivery *model.WebhookDelivery) {
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	if _, err := s.repo.RecordDelivery(recordCtx, delivery); err != nil {
+		s.logger.Error("webhook: failed to record delivery",
+			zap.Int64("subscription_id", delivery.SubscriptionID),
+			zap.String("event_id", delivery.EventID),
+			zap.Error(err))
+	}
+}
+
+// finish removes a delivery that needs no further attempts. If that fails the
+// delivery is attempted again once its lease runs out.
+func (s *Service) finish(ctx context.Context, p *model.WebhookPendingDelivery) {
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	if err := s.repo.DeletePendingDelivery(finishCtx, p.ID); err != nil {
+		s.logger.Error("webhook: failed to remove finished delivery",
+			zap.Int64("subscription_id", p.SubscriptionID),
+			zap.String("event_id", p.EventID),
+			zap.Error(err))
+	}
+}
+
+func (s *Service) reschedule(ctx context.Context, p *model.WebhookPendingDelivery, attempts int) {
+	rescheduleCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	if err := s.repo.ReschedulePendingDelivery(rescheduleCtx, p.ID, attempts, s.backoff(attempts+1)); err != nil {
+		s.logger.Error("webhook: failed to schedule next delivery attempt",
+			zap.Int64("subscription_id", p.SubscriptionID),
+			zap.String("event_id", p.EventID),
+			zap.Error(err))
+	}
+}
+
+func (s *Service) backoff(attempt int) time.Duration {
+	backoff := s.config.InitialBackoff << uint(attempt-2)
+	if backoff <= 0 || backoff > s.config.MaxBackoff {
+		return s.config.MaxBackoff
+	}
+	return backoff
+}
+
+func isRetryable(statusCode int, err error) bool {
+	if err != nil {
+		return true
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
@@ -0,0 +1,273 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/repository"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type WebhookProvider interface {
+	CreateSubscription(context.Context, *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	UpdateSubscription(context.Context, *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	DeleteSubscription(context.Context, int64) error
+	GetSubscription(context.Context, int64) (*model.WebhookSubscription, error)
+	ListSubscriptions(context.Context) ([]*model.WebhookSubscription, error)
+	ListDeliveries(context.Context, int64, int) ([]*model.WebhookDelivery, error)
+}
+
+type Config struct {
+	// Workers is the number of deliveries sent concurrently.
+	Workers int
+	// BatchSize is the number of due deliveries claimed per poll.
+	BatchSize      int
+	PollInterval   time.Duration
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	RequestTimeout time.Duration
+	// AllowLoopback permits loopback, private and link-local targets, for
+	// tests and local development only.
+	AllowLoopback bool
+}
+
+type Event struct {
+	ID         string       `json:"id"`
+	Type       string       `json:"type"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Order      *model.Order `json:"order"`
+}
+
+type Service struct {
+	repo      repository.WebhookRepositoryProvider
+	logger    logger.Logger
+	config    Config
+	client    *http.Client
+	validator *validator.Validate
+	wake      chan struct{}
+	wg        sync.WaitGroup
+}
+
+func NewService(repo repository.WebhookRepositoryProvider, logger logger.Logger, config Config) *Service {
+	return &Service{
+		repo:      repo,
+		logger:    logger,
+		config:    config,
+		client:    newClient(config.RequestTimeout, config.AllowLoopback),
+		validator: validator.New(),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+func (s *Service) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	if sub.Secret == "" {
+		secret, err := randomHex(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		sub.Secret = secret
+	}
+
+	if err := s.validateSubscription(sub); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateSubscription(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("webhook: subscription created",
+		zap.Int64("subscription_id", created.ID),
+		zap.String("url", created.URL),
+		zap.Strings("events", created.Events))
+	return created, nil
+}
+
+func (s *Service) UpdateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	if err := s.validateSubscription(sub); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateSubscription(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	return hideSecret(updated), nil
+}
+
+func (s *Service) DeleteSubscription(ctx context.Context, id int64) error {
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+func (s *Service) GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return hideSecret(sub), nil
+}
+
+func (s *Service) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		hideSecret(sub)
+	}
+	return subs, nil
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]*model.WebhookDelivery, error) {
+	if _, err := s.repo.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, subscriptionID, limit)
+}
+
+// Notify stores a pending delivery of the event for every subscription to its
+// type. Deliveries survive restarts and are retried until they succeed, are
+// rejected by the receiver or run out of attempts, so an error here must be
+// treated as the order not being processed.
+func (s *Service) Notify(ctx context.Context, eventType string, order *model.Order) error {
+	id, err := randomHex(16)
+	if err != nil {
+		return fmt.Errorf("failed to generate webhook event id: %w", err)
+	}
+
+	event := &Event{
+		ID:         id,
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Order:      order,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	queued, err := s.repo.EnqueueEvent(ctx, &model.WebhookPendingDelivery{
+		EventID:   event.ID,
+		EventType: event.Type,
+		OrderUID:  order.OrderUID,
+		Payload:   payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	if queued > 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *Service) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Service) run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// drain sends due deliveries, batch by batch, until none are left.
+func (s *Service) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		pending, err := s.repo.ClaimPendingDeliveries(ctx, s.config.BatchSize, s.lease())
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("webhook: failed to claim pending deliveries", zap.Error(err))
+			}
+			return
+		}
+		if len(pending) == 0 {
+			return
+		}
+
+		sem := make(chan struct{}, s.config.Workers)
+		var wg sync.WaitGroup
+		for _, p := range pending {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				s.deliver(ctx, p)
+			}()
+		}
+		wg.Wait()
+
+		if len(pending) < s.config.BatchSize {
+			return
+		}
+	}
+}
+
+// lease is how long a claimed delivery stays hidden from other pollers: long
+// enough for one request and for recording its outcome.
+func (s *Service) lease() time.Duration {
+	return 2*s.config.RequestTimeout + recordTimeout
+}
+
+func (s *Service) validateSubscription(sub *model.WebhookSubscription) error {
+	if err := s.validator.Struct(sub); err != nil {
+		return fmt.Errorf("%w: %v", srvcerrors.ErrInvalidInput, err)
+	}
+
+	u, err := url.Parse(sub.URL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook url: %v", srvcerrors.ErrInvalidInput, err)
+	}
+	return checkTarget(u, s.config.AllowLoopback)
+}
+
+func hideSecret(sub *model.WebhookSubscription) *model.WebhookSubscription {
+	sub.Secret = ""
+	return sub
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
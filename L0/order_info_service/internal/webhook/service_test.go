@@ -0,0 +1,434 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/logger/loggertest"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/internal/webhook"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/model"
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	mu         sync.Mutex
+	subs       []*model.WebhookSubscription
+	deliveries []*model.WebhookDelivery
+	pending    []*fakePending
+	nextID     int64
+}
+
+type fakePending struct {
+	delivery    model.WebhookPendingDelivery
+	nextAt      time.Time
+	lockedUntil time.Time
+}
+
+func (f *fakeRepository) CreateSubscription(_ context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	created := *sub
+	created.ID = int64(len(f.subs) + 1)
+	f.subs = append(f.subs, &created)
+	return &created, nil
+}
+
+func (f *fakeRepository) UpdateSubscription(_ context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	return sub, nil
+}
+
+func (f *fakeRepository) DeleteSubscription(context.Context, int64) error {
+	return nil
+}
+
+func (f *fakeRepository) GetSubscription(_ context.Context, id int64) (*model.WebhookSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, sub := range f.subs {
+		if sub.ID == id {
+			copied := *sub
+			return &copied, nil
+		}
+	}
+	return nil, srvcerrors.ErrNotFound
+}
+
+func (f *fakeRepository) ListSubscriptions(context.Context) ([]*model.WebhookSubscription, error) {
+	return f.subs, nil
+}
+
+func (f *fakeRepository) EnqueueEvent(_ context.Context, event *model.WebhookPendingDelivery) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	queued := 0
+	for _, sub := range f.subs {
+		if !sub.Active || !slices.Contains(sub.Events, event.EventType) {
+			continue
+		}
+		f.nextID++
+		p := *event
+		p.ID = f.nextID
+		p.SubscriptionID = sub.ID
+		f.pending = append(f.pending, &fakePending{delivery: p, nextAt: time.Now()})
+		queued++
+	}
+	return queued, nil
+}
+
+func (f *fakeRepository) ClaimPendingDeliveries(_ context.Context, limit int, lease time.Duration) ([]*model.WebhookPendingDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	claimed := make([]*model.WebhookPendingDelivery, 0)
+	for _, p := range f.pending {
+		if len(claimed) == limit || p.nextAt.After(now) || p.lockedUntil.After(now) {
+			continue
+		}
+		p.lockedUntil = now.Add(lease)
+		d := p.delivery
+		for _, sub := range f.subs {
+			if sub.ID == d.SubscriptionID {
+				d.URL, d.Secret, d.Active = sub.URL, sub.Secret, sub.Active
+			}
+		}
+		claimed = append(claimed, &d)
+	}
+	return claimed, nil
+}
+
+func (f *fakeRepository) ReschedulePendingDelivery(_ context.Context, id int64, attempts int, delay time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.pending {
+		if p.delivery.ID == id {
+			p.delivery.Attempts = attempts
+			p.nextAt = time.Now().Add(delay)
+			p.lockedUntil = time.Time{}
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) DeletePendingDelivery(_ context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = slices.DeleteFunc(f.pending, func(p *fakePending) bool { return p.delivery.ID == id })
+	return nil
+}
+
+func (f *fakeRepository) pendingCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pending)
+}
+
+func (f *fakeRepository) RecordDelivery(_ context.Context, d *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliveries = append(f.deliveries, d)
+	return d, nil
+}
+
+func (f *fakeRepository) ListDeliveries(context.Context, int64, int) ([]*model.WebhookDelivery, error) {
+	return f.deliveries, nil
+}
+
+func (f *fakeRepository) recorded() []*model.WebhookDelivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*model.WebhookDelivery(nil), f.deliveries...)
+}
+
+func testConfig() webhook.Config {
+	return webhook.Config{
+		Workers:        1,
+		BatchSize:      10,
+		PollInterval:   5 * time.Millisecond,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		RequestTimeout: time.Second,
+		AllowLoopback:  true,
+	}
+}
+
+func startService(t *testing.T, repo *fakeRepository) *webhook.Service {
+	t.Helper()
+
+	svc := webhook.NewService(repo, &loggertest.MockLogger{}, testConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		svc.Wait()
+	})
+	return svc
+}
+
+func TestService_DeliversSignedEvent(t *testing.T) {
+	repo := &fakeRepository{}
+	received := make(chan *http.Request, 1)
+	var verified atomic.Bool
+
+	var secret string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		verified.Store(webhook.Verify(secret, ts, body, r.Header.Get(webhook.HeaderSignature)))
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	svc := startService(t, repo)
+
+	sub, err := svc.CreateSubscription(context.Background(), &model.WebhookSubscription{
+		URL:    receiver.URL,
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, sub.Secret)
+	secret = sub.Secret
+
+	require.NoError(t, svc.Notify(context.Background(), model.EventOrderCreated, &model.Order{OrderUID: "ORDER-001"}))
+
+	select {
+	case r := <-received:
+		assert.Equal(t, model.EventOrderCreated, r.Header.Get(webhook.HeaderEvent))
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	assert.True(t, verified.Load())
+
+	require.Eventually(t, func() bool { return len(repo.recorded()) == 1 }, time.Second, 5*time.Millisecond)
+	delivery := repo.recorded()[0]
+	assert.True(t, delivery.Success)
+	assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+	assert.Equal(t, "ORDER-001", delivery.OrderUID)
+}
+
+func TestService_EventFilter(t *testing.T) {
+	repo := &fakeRepository{}
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	svc := startService(t, repo)
+	_, err := svc.CreateSubscription(context.Background(), &model.WebhookSubscription{
+		URL:    receiver.URL,
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Notify(context.Background(), model.EventOrderUpdated, &model.Order{OrderUID: "ORDER-001"}))
+	require.NoError(t, svc.Notify(context.Background(), model.EventOrderCreated, &model.Order{OrderUID: "ORDER-002"}))
+
+	require.Eventually(t, func() bool { return len(repo.recorded()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, "ORDER-002", repo.recorded()[0].OrderUID)
+}
+
+func TestService_RetriesWithBackoff(t *testing.T) {
+	repo := &fakeRepository{}
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	svc := startService(t, repo)
+	_, err := svc.CreateSubscription(context.Background(), &model.WebhookSubscription{
+		URL:    receiver.URL,
+		Events: []string{model.EventOrderUpdated},
+		Active: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Notify(context.Background(), model.EventOrderUpdated, &model.Order{OrderUID: "ORDER-001"}))
+
+	require.Eventually(t, func() bool { return len(repo.recorded()) == 3 }, 2*time.Second, 5*time.Millisecond)
+	deliveries := repo.recorded()
+	for i, d := range deliveries {
+		assert.Equal(t, i+1, d.Attempt)
+		assert.Equal(t, deliveries[0].EventID, d.EventID)
+	}
+	assert.False(t, deliveries[0].Success)
+	assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+	assert.True(t, deliveries[2].Success)
+}
+
+func TestService_NoRetryOnClientError(t *testing.T) {
+	repo := &fakeRepository{}
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer receiver.Close()
+
+	svc := startService(t, repo)
+	_, err := svc.CreateSubscription(context.Background(), &model.WebhookSubscription{
+		URL:    receiver.URL,
+		Events: []string{model.EventOrderUpdated},
+		Active: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Notify(context.Background(), model.EventOrderUpdated, &model.Order{OrderUID: "ORDER-001"}))
+
+	require.Eventually(t, func() bool { return len(repo.recorded()) == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.False(t, repo.recorded()[0].Success)
+}
+
+func TestService_CreateSubscription_Invalid(t *testing.T) {
+	cfg := testConfig()
+	cfg.AllowLoopback = false
+	svc := webhook.NewService(&fakeRepository{}, &loggertest.MockLogger{}, cfg)
+
+	tests := []struct {
+		name string
+		sub  *model.WebhookSubscription
+	}{
+		{"unknown event", &model.WebhookSubscription{URL: "http://example.com/hook", Events: []string{"order.deleted"}}},
+		{"no events", &model.WebhookSubscription{URL: "http://example.com/hook"}},
+		{"bad scheme", &model.WebhookSubscription{URL: "ftp://example.com/hook", Events: []string{model.EventOrderCreated}}},
+		{"localhost", &model.WebhookSubscription{URL: "http://localhost:8080/hook", Events: []string{model.EventOrderCreated}}},
+		{"loopback", &model.WebhookSubscription{URL: "http://127.0.0.1/hook", Events: []string{model.EventOrderCreated}}},
+		{"loopback v6", &model.WebhookSubscription{URL: "http://[::1]/hook", Events: []string{model.EventOrderCreated}}},
+		{"link-local", &model.WebhookSubscription{URL: "http://169.254.169.254/latest/meta-data", Events: []string{model.EventOrderCreated}}},
+		{"private 10/8", &model.WebhookSubscription{URL: "http://10.1.2.3/hook", Events: []string{model.EventOrderCreated}}},
+		{"private 172.16/12", &model.WebhookSubscription{URL: "http://172.31.255.1/hook", Events: []string{model.EventOrderCreated}}},
+		{"private 192.168/16", &model.WebhookSubscription{URL: "http://192.168.0.10:8080/hook", Events: []string{model.EventOrderCreated}}},
+		{"unique local v6", &model.WebhookSubscription{URL: "http://[fd12:3456::1]/hook", Events: []string{model.EventOrderCreated}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateSubscription(context.Background(), tt.sub)
+			require.ErrorIs(t, err, srvcerrors.ErrInvalidInput)
+		})
+	}
+}
+
+func TestService_CreateSubscription_PublicTarget(t *testing.T) {
+	cfg := testConfig()
+	cfg.AllowLoopback = false
+	svc := webhook.NewService(&fakeRepository{}, &loggertest.MockLogger{}, cfg)
+
+	for _, target := range []string{"http://172.32.0.1/hook", "http://8.8.8.8/hook", "https://partner.example/hook"} {
+		_, err := svc.CreateSubscription(context.Background(), &model.WebhookSubscription{
+			URL:    target,
+			Events: []string{model.EventOrderCreated},
+		})
+		require.NoError(t, err, target)
+	}
+}
+
+func TestService_RefusesLoopbackDelivery(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	// The subscription was stored before targets were checked, or its host
+	// name resolves to a loopback address.
+	repo := &fakeRepository{subs: []*model.WebhookSubscription{{
+		ID:     1,
+		URL:    receiver.URL,
+		Secret: "secret",
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	}}}
+
+	cfg := testConfig()
+	cfg.AllowLoopback = false
+	cfg.MaxAttempts = 1
+	svc := webhook.NewService(repo, &loggertest.MockLogger{}, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.Start(ctx)
+	defer func() {
+		cancel()
+		svc.Wait()
+	}()
+
+	require.NoError(t, svc.Notify(context.Background(), model.EventOrderCreated, &model.Order{OrderUID: "ORDER-001"}))
+
+	require.Eventually(t, func() bool { return len(repo.recorded()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.False(t, repo.recorded()[0].Success)
+	assert.Zero(t, calls.Load())
+}
+
+func TestService_DeliversEventsStoredBeforeStart(t *testing.T) {
+	repo := &fakeRepository{}
+	received := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(webhook.HeaderEventID)
+	}))
+	defer receiver.Close()
+
+	// An instance that stored the event but stopped before delivering it.
+	stopped := webhook.NewService(repo, &loggertest.MockLogger{}, testConfig())
+	_, err := stopped.CreateSubscription(context.Background(), &model.WebhookSubscription{
+		URL:    receiver.URL,
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, stopped.Notify(context.Background(), model.EventOrderCreated, &model.Order{OrderUID: "ORDER-001"}))
+	require.Equal(t, 1, repo.pendingCount())
+
+	startService(t, repo)
+
+	select {
+	case id := <-received:
+		assert.NotEmpty(t, id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("stored event was not delivered")
+	}
+	require.Eventually(t, func() bool { return repo.pendingCount() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestService_DropsDeliveryAfterMaxAttempts(t *testing.T) {
+	repo := &fakeRepository{}
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	svc := startService(t, repo)
+	_, err := svc.CreateSubscription(context.Background(), &model.WebhookSubscription{
+		URL:    receiver.URL,
+		Events: []string{model.EventOrderCreated},
+		Active: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Notify(context.Background(), model.EventOrderCreated, &model.Order{OrderUID: "ORDER-001"}))
+
+	require.Eventually(t, func() bool { return repo.pendingCount() == 0 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Len(t, repo.recorded(), 3)
+}
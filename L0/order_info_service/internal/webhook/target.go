@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/NikitaKoros/wb_tech/L0/order_info_service/pkg/srvcerrors"
+)
+
+var errForbiddenTarget = fmt.Errorf("%w: webhook target is a loopback, private or link-local address", srvcerrors.ErrInvalidInput)
+
+// checkTarget rejects webhook URLs that are not http(s) or point at the host
+// itself, at internal networks or at link-local services such as cloud
+// metadata endpoints.
+func checkTarget(u *url.URL, allowLoopback bool) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: webhook url must be http or https", srvcerrors.ErrInvalidInput)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: webhook url must have a host", srvcerrors.ErrInvalidInput)
+	}
+	if allowLoopback {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errForbiddenTarget
+	}
+	if ip := net.ParseIP(host); ip != nil && forbiddenIP(ip) {
+		return errForbiddenTarget
+	}
+	return nil
+}
+
+func forbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// newClient returns an HTTP client that also refuses to connect to forbidden
+// addresses, so host names resolving to them and redirects are covered too.
+func newClient(timeout time.Duration, allowLoopback bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowLoopback {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || forbiddenIP(ip) {
+				return errForbiddenTarget
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Through a proxy the dialer would only see the proxy address.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+const (
+	BucketHour  = "hour"
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+
+	GroupByDeliveryService = "delivery_service"
+	GroupByLocale          = "locale"
+)
+
+type AnalyticsQuery struct {
+	From     time.Time
+	To       time.Time
+	Bucket   string
+	Limit    int
+	Currency string
+}
+
+type RevenuePoint struct {
+	Bucket   time.Time `json:"bucket"`
+	Currency string    `json:"currency"`
+	Revenue  int64     `json:"revenue"`
+	Orders   int64     `json:"orders"`
+}
+
+type OrderCountPoint struct {
+	Bucket time.Time `json:"bucket"`
+	Key    string    `json:"key"`
+	Orders int64     `json:"orders"`
+}
+
+type BrandStat struct {
+	Bucket time.Time `json:"bucket"`
+	Brand  string    `json:"brand"`
+	Items  int64     `json:"items"`
+	Orders int64     `json:"orders"`
+}
+
+type ProductStat struct {
+	Bucket time.Time `json:"bucket"`
+	NmID   int       `json:"nm_id"`
+	Items  int64     `json:"items"`
+	Orders int64     `json:"orders"`
+}
+
+type BasketPoint struct {
+	Bucket    time.Time `json:"bucket"`
+	Currency  string    `json:"currency"`
+	Orders    int64     `json:"orders"`
+	AvgItems  float64   `json:"avg_items"`
+	AvgAmount float64   `json:"avg_amount"`
+}
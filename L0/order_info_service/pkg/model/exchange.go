@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type ExchangeRate struct {
+	Currency  string    `json:"currency" validate:"required,len=3,uppercase"`
+	Rate      float64   `json:"rate" validate:"gt=0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
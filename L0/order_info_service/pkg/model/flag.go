@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type OrderFlag struct {
+	Rule      string    `json:"rule"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -3,20 +3,26 @@ package model
 import "time"
 
 type Order struct {
-	OrderUID          string    `json:"order_uid" validate:"required,alphanum"`
-	TrackNumber       string    `json:"track_number" validate:"required"`
-	Entry             string    `json:"entry" validate:"required"`
-	Delivery          Delivery  `json:"delivery" validate:"required"`
-	Payment           Payment   `json:"payment" validate:"required"`
-	Items             []*Item   `json:"items" validate:"required,min=1,dive"`
-	Locale            string    `json:"locale" validate:"required,oneof=en ru"`
-	InternalSignature string    `json:"internal_signature" validate:"omitempty"`
-	CustomerID        string    `json:"customer_id" validate:"required"`
-	DeliveryService   string    `json:"delivery_service" validate:"required"`
-	Shardkey          string    `json:"shardkey" validate:"required"`
-	SmID              int       `json:"sm_id" validate:"required"`
-	DateCreated       time.Time `json:"date_created" validate:"required"`
-	OofShard          string    `json:"oof_shard" validate:"required"`
+	OrderUID          string       `json:"order_uid" validate:"required,alphanum"`
+	TrackNumber       string       `json:"track_number" validate:"required"`
+	Entry             string       `json:"entry" validate:"required"`
+	Delivery          Delivery     `json:"delivery" validate:"required"`
+	Payment           Payment      `json:"payment" validate:"required"`
+	Items             []*Item      `json:"items" validate:"required,min=1,dive"`
+	Locale            string       `json:"locale" validate:"required,oneof=en ru"`
+	InternalSignature string       `json:"internal_signature" validate:"omitempty"`
+	CustomerID        string       `json:"customer_id" validate:"required"`
+	DeliveryService   string       `json:"delivery_service" validate:"required"`
+	Shardkey          string       `json:"shardkey" validate:"required"`
+	SmID              int          `json:"sm_id" validate:"required"`
+	DateCreated       time.Time    `json:"date_created" validate:"required"`
+	OofShard          string       `json:"oof_shard" validate:"required"`
+	Flags             []*OrderFlag `json:"flags,omitempty" validate:"-"`
+}
+
+type OrderCursor struct {
+	DateCreated time.Time
+	OrderUID    string
 }
 
 type Delivery struct {
@@ -0,0 +1,6 @@
+package model
+
+type OrderChange struct {
+	OrderUID string `json:"order_uid"`
+	Origin   string `json:"origin,omitempty"`
+}
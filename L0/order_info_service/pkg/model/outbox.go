@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// OrderStoredEvent is published to downstream services after an order has
+// been committed. Type is EventOrderCreated or EventOrderUpdated.
+type OrderStoredEvent struct {
+	EventID  string    `json:"event_id"`
+	Type     string    `json:"type"`
+	StoredAt time.Time `json:"stored_at"`
+	Order    *Order    `json:"order"`
+}
+
+type OutboxMessage struct {
+	ID        int64
+	EventID   string
+	OrderUID  string
+	EventType string
+	Payload   []byte
+	CreatedAt time.Time
+}
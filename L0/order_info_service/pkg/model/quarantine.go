@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	QuarantineStatusPending      = "quarantined"
+	QuarantineStatusResubmitting = "resubmitting"
+	QuarantineStatusResubmitted  = "resubmitted"
+	QuarantineStatusDiscarded    = "discarded"
+)
+
+type QuarantinedOrder struct {
+	ID             int64      `json:"id"`
+	OrderUID       string     `json:"order_uid"`
+	Payload        RawPayload `json:"payload"`
+	Stage          string     `json:"stage"`
+	Errors         []string   `json:"errors"`
+	Topic          string     `json:"topic"`
+	Partition      int32      `json:"partition"`
+	Offset         int64      `json:"offset"`
+	Key            string     `json:"key"`
+	Status         string     `json:"status"`
+	ReceivedAt     time.Time  `json:"received_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ResubmittedAt  *time.Time `json:"resubmitted_at,omitempty"`
+	KafkaTimestamp *time.Time `json:"kafka_timestamp,omitempty"`
+}
+
+// RawPayload is a Kafka message body as it was received. It is rendered as
+// JSON when it is valid JSON and as a JSON string otherwise, since orders
+// rejected at the decode stage often are not.
+type RawPayload []byte
+
+func (p RawPayload) MarshalJSON() ([]byte, error) {
+	if json.Valid(p) {
+		return p, nil
+	}
+	return json.Marshal(string(p))
+}
+
+func (p *RawPayload) UnmarshalJSON(data []byte) error {
+	*p = append((*p)[:0], data...)
+	return nil
+}
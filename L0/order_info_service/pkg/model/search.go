@@ -0,0 +1,16 @@
+package model
+
+type ItemSearchHit struct {
+	OrderUID string  `json:"order_uid"`
+	OrderURL string  `json:"order_url"`
+	Rank     float64 `json:"rank"`
+	Item     *Item   `json:"item"`
+}
+
+type ItemSearchResult struct {
+	Query      string           `json:"query"`
+	Hits       []*ItemSearchHit `json:"hits"`
+	Offset     int              `json:"offset"`
+	NextOffset int              `json:"next_offset"`
+	HasMore    bool             `json:"has_more"`
+}
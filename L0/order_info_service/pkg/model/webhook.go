@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+const (
+	EventOrderCreated = "order.created"
+	EventOrderUpdated = "order.updated"
+)
+
+type WebhookSubscription struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url" validate:"required,url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events" validate:"required,min=1,dive,oneof=order.created order.updated"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscription_id"`
+	EventID        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	OrderUID       string    `json:"order_uid"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"status_code"`
+	Success        bool      `json:"success"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// WebhookPendingDelivery is an event not yet delivered to one subscription.
+// Attempts counts the delivery attempts already made.
+type WebhookPendingDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	URL            string
+	Secret         string
+	Active         bool
+	EventID        string
+	EventType      string
+	OrderUID       string
+	Payload        []byte
+	Attempts       int
+}
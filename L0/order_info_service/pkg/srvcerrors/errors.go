@@ -7,4 +7,6 @@ var (
 	ErrDatabase           = fmt.Errorf("database error")
 	ErrInvalidInput       = fmt.Errorf("invalid input")
 	ErrKafka              = fmt.Errorf("kafka error")
+	ErrUnavailable        = fmt.Errorf("service unavailable")
+	ErrConflict           = fmt.Errorf("conflict")
 )